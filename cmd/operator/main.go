@@ -4,6 +4,10 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
+	// embed the time zone database, the operator image does not ship one and backup schedules may set a time zone
+	_ "time/tzdata"
 
 	"github.com/rancher/backup-restore-operator/pkg/version"
 	"github.com/rancher/wrangler/v3/pkg/kubeconfig"
@@ -26,6 +30,12 @@ var (
 	MetricsServerEnabled            string
 	OperatorS3BackupStorageLocation string
 	ChartNamespace                  string
+	OrphanArchiveRetention          string
+	CatalogSyncInterval             string
+	ResourceSetResolveInterval      string
+	RequireEncryption               string
+	SensitiveResources              string
+	WebhookCertDir                  string
 	Debug                           bool
 	Trace                           bool
 	PrintVersion                    bool
@@ -43,6 +53,12 @@ func init() {
 	ChartNamespace = os.Getenv("CHART_NAMESPACE")
 	MetricsServerEnabled = os.Getenv("METRICS_SERVER")
 	LocalEncryptionProviderLocation = os.Getenv("ENCRYPTION_PROVIDER_LOCATION")
+	OrphanArchiveRetention = os.Getenv("ORPHAN_ARCHIVE_RETENTION")
+	CatalogSyncInterval = os.Getenv("CATALOG_SYNC_INTERVAL")
+	ResourceSetResolveInterval = os.Getenv("RESOURCESET_RESOLVE_INTERVAL")
+	RequireEncryption = os.Getenv("REQUIRE_ENCRYPTION")
+	SensitiveResources = os.Getenv("SENSITIVE_RESOURCES")
+	WebhookCertDir = os.Getenv("WEBHOOK_CERT_DIR")
 }
 
 func main() {
@@ -68,17 +84,47 @@ func main() {
 		logrus.Fatalf("failed to find kubeconfig: %v", err)
 	}
 
+	var orphanArchiveRetention time.Duration
+	if OrphanArchiveRetention != "" {
+		if orphanArchiveRetention, err = time.ParseDuration(OrphanArchiveRetention); err != nil {
+			logrus.Fatalf("invalid ORPHAN_ARCHIVE_RETENTION %v: %v", OrphanArchiveRetention, err)
+		}
+	}
+
+	var catalogSyncInterval time.Duration
+	if CatalogSyncInterval != "" {
+		if catalogSyncInterval, err = time.ParseDuration(CatalogSyncInterval); err != nil || catalogSyncInterval < time.Second {
+			logrus.Fatalf("invalid CATALOG_SYNC_INTERVAL %v, it must be a duration of at least 1s", CatalogSyncInterval)
+		}
+	}
+
+	var resourceSetResolveInterval time.Duration
+	if ResourceSetResolveInterval != "" {
+		if resourceSetResolveInterval, err = time.ParseDuration(ResourceSetResolveInterval); err != nil || resourceSetResolveInterval < time.Second {
+			logrus.Fatalf("invalid RESOURCESET_RESOLVE_INTERVAL %v, it must be a duration of at least 1s", ResourceSetResolveInterval)
+		}
+	}
+
 	dm := os.Getenv("CATTLE_DEV_MODE")
 	backuputil.SetDevMode(dm != "")
 	runOptions := operator.RunOptions{
-		OperatorPVCEnabled:              OperatorPVEnabled != "",
-		MetricsServerEnabled:            MetricsServerEnabled != "",
-		MetricsPort:                     8080,
-		MetricsIntervalSeconds:          60,
-		OperatorS3BackupStorageLocation: OperatorS3BackupStorageLocation,
-		ChartNamespace:                  ChartNamespace,
-		LocalDriverPath:                 "",
-		LocalEncryptionProviderLocation: LocalEncryptionProviderLocation,
+		OperatorPVCEnabled:                OperatorPVEnabled != "",
+		MetricsServerEnabled:              MetricsServerEnabled != "",
+		MetricsPort:                       8080,
+		MetricsIntervalSeconds:            60,
+		OperatorS3BackupStorageLocation:   OperatorS3BackupStorageLocation,
+		ChartNamespace:                    ChartNamespace,
+		LocalDriverPath:                   "",
+		LocalEncryptionProviderLocation:   LocalEncryptionProviderLocation,
+		CatalogSyncIntervalSeconds:        int(catalogSyncInterval.Seconds()),
+		OrphanArchiveRetention:            orphanArchiveRetention,
+		ResourceSetResolveIntervalSeconds: int(resourceSetResolveInterval.Seconds()),
+		RequireEncryption:                 RequireEncryption == "true",
+		WebhookCertDir:                    WebhookCertDir,
+		WebhookPort:                       9443,
+	}
+	if SensitiveResources != "" {
+		runOptions.SensitiveResources = strings.Split(SensitiveResources, ",")
 	}
 
 	if err := operator.Run(ctx, restKubeConfig, runOptions); err != nil {
@@ -6,8 +6,11 @@ import (
 	"strings"
 
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/match"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // ResourceInfo describes the k8s resource to check against ResourceSet rules.
@@ -19,6 +22,25 @@ type ResourceInfo struct {
 	Name       string            // required
 	Namespace  string            // empty means cluster-scoped or not provided
 	Labels     map[string]string // empty means label selectors will not be checked
+
+	// The fields below are only set in live mode, from the cluster. The conditions they answer
+	// are then checked instead of being recorded as caveats.
+	Object              map[string]interface{} // the object, match expressions are evaluated on it
+	Resource            string                 // plural resource name, matched by kinds like the kind is
+	Namespaced          bool
+	Verbs               []string          // verbs of the resource, the operator skips resources it can't list or get
+	NamespaceLabels     map[string]string // labels of the namespace of the object
+	ServedAPIVersions   []string          // versions of the group serving the resource, the object is read in any of them
+	PreferredAPIVersion string            // version the cluster prefers for the group, empty if it doesn't serve the resource
+	// ListWithFieldSelector lists the resource of the object with a field selector, and reports
+	// whether the object is listed. Field selectors are checked with it, as the API server
+	// evaluates them.
+	ListWithFieldSelector func(fieldSelector string) (bool, error)
+}
+
+// live reports whether res was read from a cluster.
+func (res ResourceInfo) live() bool {
+	return res.Object != nil
 }
 
 // MatchResult describes a ResourceSelector rule that matched the resource.
@@ -30,6 +52,9 @@ type MatchResult struct {
 	// Caveats lists selector conditions that could not be checked offline
 	// (e.g. namespace not provided, label selectors without labels, field selectors).
 	Caveats []string
+	// OverlapsWith lists the display indexes of the other selectors of the same
+	// ResourceSet that match the resource. The operator backs the resource up once.
+	OverlapsWith []int `json:",omitempty"`
 }
 
 // Check tests res against every selector in every ResourceSet and returns ALL rules
@@ -41,27 +66,74 @@ type MatchResult struct {
 // every ResourceSet that would cover it, so callers get the complete picture of
 // which backup rules apply to the resource.
 //
-// Matching mirrors the operator's offline-checkable logic: apiVersion, kind, name,
+// Matching mirrors the operator's offline-checkable logic: apiVersion or apiGroupRegexp, kind, name,
 // namespace, and label selectors (when labels are available). Conditions that cannot
 // be evaluated without a live cluster (missing namespace, missing labels, field
-// selectors) are recorded as Caveats on the relevant MatchResult rather than
-// causing a non-match.
+// selectors, match expressions) are recorded as Caveats on the relevant MatchResult rather than
+// causing a non-match. In live mode they are all checked, so results have no caveats.
+// The selectors of a ResourceSet don't cover a resource one of its exclude selectors matches.
 func Check(res ResourceInfo, resourceSets []*AnnotatedResourceSet) ([]MatchResult, error) {
 	var results []MatchResult
 	for _, ars := range resourceSets {
+		excluded, exclusionCaveats, err := checkExcluded(res, ars)
+		if err != nil {
+			return nil, err
+		}
+		if excluded {
+			continue
+		}
+		var setResults []MatchResult
 		for i, sel := range ars.ResourceSelectors {
 			result, matched, err := tryMatch(res, sel, i+1, ars.Name, ars.SelectorSources[i])
 			if err != nil {
 				return nil, err
 			}
 			if matched {
-				results = append(results, result)
+				result.Caveats = append(result.Caveats, exclusionCaveats...)
+				setResults = append(setResults, result)
 			}
 		}
+		results = append(results, markOverlaps(setResults)...)
 	}
 	return results, nil
 }
 
+// markOverlaps records on each of the results of a single ResourceSet the other
+// selectors that match the resource too.
+func markOverlaps(results []MatchResult) []MatchResult {
+	if len(results) < 2 {
+		return results
+	}
+	for i := range results {
+		for j := range results {
+			if i != j {
+				results[i].OverlapsWith = append(results[i].OverlapsWith, results[j].SelectorIndex)
+			}
+		}
+	}
+	return results
+}
+
+// checkExcluded reports whether an exclude selector of the ResourceSet certainly matches res, in which case none of
+// its selectors cover it. Exclude selectors which match res only if their caveats hold are returned as caveats.
+func checkExcluded(res ResourceInfo, ars *AnnotatedResourceSet) (bool, []string, error) {
+	var caveats []string
+	for i, sel := range ars.ExcludeSelectors {
+		result, matched, err := tryMatch(res, sel, i+1, ars.Name, nil)
+		if err != nil {
+			return false, nil, fmt.Errorf("exclude %w", err)
+		}
+		if !matched {
+			continue
+		}
+		if len(result.Caveats) == 0 {
+			return true, nil, nil
+		}
+		caveats = append(caveats, fmt.Sprintf("may be excluded by exclude selector %d (%s)", i+1, strings.Join(result.Caveats, ", ")))
+	}
+	return false, caveats, nil
+}
+
 func tryMatch(res ResourceInfo, sel v1.ResourceSelector, idx int, rsName string, sources []string) (MatchResult, bool, error) {
 	r := MatchResult{
 		ResourceSetName: rsName,
@@ -70,13 +142,35 @@ func tryMatch(res ResourceInfo, sel v1.ResourceSelector, idx int, rsName string,
 		Selector:        sel,
 	}
 
-	// apiVersion: if provided by caller, must match exactly.
-	if res.APIVersion != "" && res.APIVersion != sel.APIVersion {
-		return r, false, nil
+	// apiVersion: if provided by caller, must match exactly, or its group must match
+	// the selector's apiGroupRegexp. Only the preferred version of a matching group is
+	// backed up, which is only known to a live cluster. In live mode, the object can be
+	// selected in any version of its group serving the resource.
+	if res.APIVersion != "" {
+		if sel.APIGroupRegexp != "" {
+			groupMatch, err := matchesAPIGroup(res.APIVersion, sel.APIGroupRegexp)
+			if err != nil {
+				return r, false, fmt.Errorf("rule %d (%s): apiGroup: %w", idx, rsName, err)
+			}
+			if !groupMatch {
+				return r, false, nil
+			}
+			if !res.live() {
+				r.Caveats = append(r.Caveats, "apiVersion not checked against the preferred version of the group (requires live cluster)")
+			} else if res.PreferredAPIVersion == "" {
+				return r, false, nil
+			}
+		} else if res.live() {
+			if !contains(res.ServedAPIVersions, sel.APIVersion) {
+				return r, false, nil
+			}
+		} else if res.APIVersion != sel.APIVersion {
+			return r, false, nil
+		}
 	}
 
 	// kind
-	kindMatch, err := matchesKind(res.Kind, sel)
+	kindMatch, err := matchesKind(res, sel)
 	if err != nil {
 		return r, false, fmt.Errorf("rule %d (%s): kind: %w", idx, rsName, err)
 	}
@@ -93,13 +187,39 @@ func tryMatch(res ResourceInfo, sel v1.ResourceSelector, idx int, rsName string,
 		return r, false, nil
 	}
 
+	// resources which can't be listed are gathered by name, mirrors collector.gatherObjectsForNonListResource.
+	if res.live() && !contains(res.Verbs, "list") {
+		if !contains(res.Verbs, "get") || !contains(sel.ResourceNames, res.Name) ||
+			(res.Namespaced && !contains(sel.Namespaces, res.Namespace)) {
+			return r, false, nil
+		}
+	}
+
 	// namespace: only checked when the caller provided a namespace AND the selector
 	// has namespace constraints. If namespace is absent, note it as a caveat.
-	if len(sel.Namespaces) > 0 || sel.NamespaceRegexp != "" {
+	// The namespace selector needs the labels of the namespace, so a namespace not
+	// matched by name may still be selected by it. In live mode, namespace constraints
+	// are ignored for cluster-scoped resources like the operator does.
+	hasNamespaceFilter := len(sel.Namespaces) > 0 || sel.NamespaceRegexp != "" || sel.NamespaceSelector != nil
+	if hasNamespaceFilter && (!res.live() || res.Namespaced) {
 		if res.Namespace != "" {
-			nsMatch, err := matchesNamespace(res.Namespace, sel)
-			if err != nil {
-				return r, false, fmt.Errorf("rule %d (%s): namespace: %w", idx, rsName, err)
+			nsMatch := false
+			if len(sel.Namespaces) > 0 || sel.NamespaceRegexp != "" {
+				nsMatch, err = matchesNamespace(res.Namespace, sel)
+				if err != nil {
+					return r, false, fmt.Errorf("rule %d (%s): namespace: %w", idx, rsName, err)
+				}
+			}
+			if !nsMatch && sel.NamespaceSelector != nil {
+				if res.live() {
+					nsMatch, err = matchesLabels(res.NamespaceLabels, sel.NamespaceSelector)
+					if err != nil {
+						return r, false, fmt.Errorf("rule %d (%s): namespaceSelector: %w", idx, rsName, err)
+					}
+				} else {
+					r.Caveats = append(r.Caveats, "namespace selector not checked (requires namespace labels from a live cluster)")
+					nsMatch = true
+				}
 			}
 			if !nsMatch {
 				return r, false, nil
@@ -111,7 +231,7 @@ func tryMatch(res ResourceInfo, sel v1.ResourceSelector, idx int, rsName string,
 
 	// label selectors: only checked when labels are available from a resource file.
 	if sel.LabelSelectors != nil {
-		if len(res.Labels) > 0 {
+		if len(res.Labels) > 0 || res.live() {
 			lblMatch, err := matchesLabels(res.Labels, sel.LabelSelectors)
 			if err != nil {
 				return r, false, fmt.Errorf("rule %d (%s): labels: %w", idx, rsName, err)
@@ -126,23 +246,100 @@ func tryMatch(res ResourceInfo, sel v1.ResourceSelector, idx int, rsName string,
 
 	// field selectors: cannot be evaluated offline.
 	if len(sel.FieldSelectors) > 0 {
-		r.Caveats = append(r.Caveats, "field selector not checked (requires live cluster)")
+		if !res.live() {
+			r.Caveats = append(r.Caveats, "field selector not checked (requires live cluster)")
+		} else {
+			fldMatch, err := matchesFields(res, sel.FieldSelectors)
+			if err != nil {
+				return r, false, fmt.Errorf("rule %d (%s): fields: %w", idx, rsName, err)
+			}
+			if !fldMatch {
+				return r, false, nil
+			}
+		}
+	}
+
+	// match expressions are evaluated on the whole object, which is only available in live mode.
+	if sel.Match != "" {
+		if !res.live() {
+			r.Caveats = append(r.Caveats, "match expression not checked (requires the object from a live cluster)")
+		} else {
+			matched, err := matchesExpression(res.Object, sel.Match)
+			if err != nil {
+				return r, false, fmt.Errorf("rule %d (%s): match: %w", idx, rsName, err)
+			}
+			if !matched {
+				return r, false, nil
+			}
+		}
 	}
 
 	return r, true, nil
 }
 
+// matchesFields mirrors collector.fetchResourcesFromAPIServer for the field selectors of a single object: the
+// resource is listed with them, restricted to the name of the object, and they match if the object
+// is listed. The API server only supports some fields per resource, and fails the list, as it fails
+// the backup, for the others.
+func matchesFields(res ResourceInfo, set fields.Set) (bool, error) {
+	selector := fields.AndSelectors(fields.SelectorFromSet(set), fields.OneTermEqualSelector("metadata.name", res.Name))
+	return res.ListWithFieldSelector(selector.String())
+}
+
+// matchesExpression mirrors collector.filterByMatch for a single object.
+func matchesExpression(object map[string]interface{}, expression string) (bool, error) {
+	program, err := match.Compile(expression)
+	if err != nil {
+		return false, fmt.Errorf("compiling %q: %w", expression, err)
+	}
+	matched, err := match.Eval(program, object)
+	if err != nil {
+		return false, fmt.Errorf("evaluating %q: %w", expression, err)
+	}
+	return matched, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAPIGroup mirrors collector.groupVersionsForSelector for the group of a single apiVersion.
+func matchesAPIGroup(apiVersion, apiGroupRegexp string) (bool, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return false, fmt.Errorf("parsing apiVersion %q: %w", apiVersion, err)
+	}
+	// "." is the operator's catch-all apiGroupRegexp, it matches the core group too.
+	if apiGroupRegexp == "." {
+		return true, nil
+	}
+	re, err := regexp.Compile(apiGroupRegexp)
+	if err != nil {
+		return false, fmt.Errorf("compiling apiGroupRegexp %q: %w", apiGroupRegexp, err)
+	}
+	return re.MatchString(gv.Group), nil
+}
+
 // matchesKind mirrors collector.filterByKind for a single user-supplied kind string.
 // Because the operator matches against both the singular Kind (e.g. "Deployment") and
 // the plural resource name (e.g. "deployments"), we try the kind as given, lowercase,
-// and a naive lowercase plural so that either form works from user input.
-func matchesKind(kind string, sel v1.ResourceSelector) (bool, error) {
+// and a naive lowercase plural so that either form works from user input. In live mode
+// the kind and resource name of the cluster are matched with the operator's rules.
+func matchesKind(res ResourceInfo, sel v1.ResourceSelector) (bool, error) {
 	// No kind filter: matches all.
 	if len(sel.Kinds) == 0 && sel.KindsRegexp == "" {
 		return true, nil
 	}
+	if res.live() {
+		return matchesKindLive(res, sel)
+	}
 
-	candidates := kindCandidates(kind)
+	candidates := kindCandidates(res.Kind)
 
 	// Check the explicit Kinds list first (OR with regexp, mirrors operator).
 	for _, c := range candidates {
@@ -173,6 +370,27 @@ func matchesKind(kind string, sel v1.ResourceSelector) (bool, error) {
 	return false, nil
 }
 
+// matchesKindLive mirrors collector.filterByKind for the kind and resource name read from the
+// cluster: kinds are matched exactly, and excludeKinds only drop the resources matched by
+// kindsRegexp, not the ones listed in kinds.
+func matchesKindLive(res ResourceInfo, sel v1.ResourceSelector) (bool, error) {
+	if contains(sel.Kinds, res.Kind) || contains(sel.Kinds, res.Resource) {
+		return true, nil
+	}
+	if sel.KindsRegexp == "" {
+		return false, nil
+	}
+	re, err := regexp.Compile(sel.KindsRegexp)
+	if err != nil {
+		return false, fmt.Errorf("compiling kindsRegexp %q: %w", sel.KindsRegexp, err)
+	}
+	// "." is the operator's catch-all kindsRegexp.
+	if sel.KindsRegexp != "." && !re.MatchString(res.Kind) && !re.MatchString(res.Resource) {
+		return false, nil
+	}
+	return !contains(sel.ExcludeKinds, res.Kind) && !contains(sel.ExcludeKinds, res.Resource), nil
+}
+
 // kindCandidates returns the distinct strings to try when matching a kind:
 // the original, lowercase, and a naive lowercase plural (only if not already pluralised).
 func kindCandidates(kind string) []string {
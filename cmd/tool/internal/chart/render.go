@@ -7,6 +7,7 @@ import (
 
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
 	"github.com/rancher/backup-restore-operator/pkg/consts"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/include"
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/common/util"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
@@ -55,6 +56,10 @@ func LoadAndRenderResourceSets(chartPath string) ([]*AnnotatedResourceSet, error
 	if err != nil {
 		return nil, err
 	}
+	resourceSets, err = resolveIncludes(resourceSets)
+	if err != nil {
+		return nil, err
+	}
 
 	// Build a fingerprint index from the chart's static source files so we can
 	// attribute each rendered selector back to the file it came from.
@@ -63,6 +68,30 @@ func LoadAndRenderResourceSets(chartPath string) ([]*AnnotatedResourceSet, error
 	return annotate(resourceSets, sourceIndex), nil
 }
 
+// resolveIncludes merges the selectors of the ResourceSets each ResourceSet includes, as the operator does when a
+// backup is taken, so that included selectors are attributed to the including ResourceSet too.
+func resolveIncludes(resourceSets []*v1.ResourceSet) ([]*v1.ResourceSet, error) {
+	byName := make(map[string]*v1.ResourceSet, len(resourceSets))
+	for _, rs := range resourceSets {
+		byName[rs.Name] = rs
+	}
+	get := func(name string) (*v1.ResourceSet, error) {
+		rs, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("ResourceSet %q is not rendered by the chart", name)
+		}
+		return rs, nil
+	}
+	resolved := make([]*v1.ResourceSet, len(resourceSets))
+	for i, rs := range resourceSets {
+		var err error
+		if resolved[i], err = include.Resolve(rs, get); err != nil {
+			return nil, fmt.Errorf("resolving includes of ResourceSet %q: %w", rs.Name, err)
+		}
+	}
+	return resolved, nil
+}
+
 // annotate pairs each selector in each ResourceSet with its source files from the index.
 func annotate(resourceSets []*v1.ResourceSet, sourceIndex map[string][]string) []*AnnotatedResourceSet {
 	out := make([]*AnnotatedResourceSet, len(resourceSets))
@@ -0,0 +1,278 @@
+// Package cluster reads the resource to check and the ResourceSets of a live cluster.
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rancher/backup-restore-operator/cmd/tool/internal/chart"
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/generate"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/include"
+	corev1 "k8s.io/api/core/v1"
+	apiextclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var resourceSetsGVR = v1.SchemeGroupVersion.WithResource("resourcesets")
+
+// Client reads from the cluster of a kubeconfig.
+type Client struct {
+	discovery  discovery.DiscoveryInterface
+	dynamic    dynamic.Interface
+	kubernetes kubernetes.Interface
+	apiext     apiextclientset.Interface
+}
+
+// NewClient returns a Client for the cluster of the current context of a kubeconfig file.
+func NewClient(kubeconfig string) (*Client, error) {
+	loadingRules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig %q: %w", kubeconfig, err)
+	}
+	c := &Client{}
+	if c.discovery, err = discovery.NewDiscoveryClientForConfig(config); err != nil {
+		return nil, err
+	}
+	if c.dynamic, err = dynamic.NewForConfig(config); err != nil {
+		return nil, err
+	}
+	if c.kubernetes, err = kubernetes.NewForConfig(config); err != nil {
+		return nil, err
+	}
+	if c.apiext, err = apiextclientset.NewForConfig(config); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Resource reads the resource described by res from the cluster, and returns res completed with
+// what the operator's selectors check: the object, its labels and namespace, the discovery details
+// of its resource, the labels of its namespace and a way to list it with field selectors.
+func (c *Client) Resource(ctx context.Context, res chart.ResourceInfo) (chart.ResourceInfo, error) {
+	gv, apiResource, err := c.findResource(res.APIVersion, res.Kind)
+	if err != nil {
+		return res, err
+	}
+	if apiResource.Namespaced && res.Namespace == "" {
+		return res, fmt.Errorf("%s is namespaced, use --namespace to specify the namespace of %s", apiResource.Kind, res.Name)
+	}
+	namespace := res.Namespace
+	if !apiResource.Namespaced {
+		namespace = ""
+	}
+
+	resource := c.dynamic.Resource(gv.WithResource(apiResource.Name)).Namespace(namespace)
+	obj, err := resource.Get(ctx, res.Name, metav1.GetOptions{})
+	if err != nil {
+		return res, fmt.Errorf("getting %s %s: %w", apiResource.Kind, res.Name, err)
+	}
+
+	live := chart.ResourceInfo{
+		APIVersion: gv.String(),
+		Kind:       apiResource.Kind,
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+		Labels:     obj.GetLabels(),
+		Object:     obj.Object,
+		Resource:   apiResource.Name,
+		Namespaced: apiResource.Namespaced,
+		Verbs:      apiResource.Verbs,
+		ListWithFieldSelector: func(fieldSelector string) (bool, error) {
+			list, err := resource.List(ctx, metav1.ListOptions{FieldSelector: fieldSelector})
+			if err != nil {
+				return false, fmt.Errorf("listing %s with field selector %q: %w", apiResource.Name, fieldSelector, err)
+			}
+			return len(list.Items) > 0, nil
+		},
+	}
+	if live.ServedAPIVersions, live.PreferredAPIVersion, err = c.groupVersions(gv.Group, apiResource.Name); err != nil {
+		return res, err
+	}
+	if live.Namespaced {
+		ns, err := c.kubernetes.CoreV1().Namespaces().Get(ctx, live.Namespace, metav1.GetOptions{})
+		if err != nil {
+			return res, fmt.Errorf("getting namespace %s: %w", live.Namespace, err)
+		}
+		live.NamespaceLabels = ns.Labels
+	}
+	return live, nil
+}
+
+// findResource returns the resource of a kind, which may be given as its kind or resource name.
+// Without an apiVersion, the preferred versions of all groups are searched.
+func (c *Client) findResource(apiVersion, kind string) (schema.GroupVersion, metav1.APIResource, error) {
+	var lists []*metav1.APIResourceList
+	if apiVersion != "" {
+		list, err := c.discovery.ServerResourcesForGroupVersion(apiVersion)
+		if err != nil {
+			return schema.GroupVersion{}, metav1.APIResource{}, fmt.Errorf("discovering resources of %s: %w", apiVersion, err)
+		}
+		lists = append(lists, list)
+	} else {
+		var err error
+		if lists, err = c.discovery.ServerPreferredResources(); err != nil && len(lists) == 0 {
+			return schema.GroupVersion{}, metav1.APIResource{}, fmt.Errorf("discovering resources: %w", err)
+		}
+	}
+
+	type found struct {
+		gv       schema.GroupVersion
+		resource metav1.APIResource
+	}
+	var matches []found
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			if strings.EqualFold(resource.Kind, kind) || strings.EqualFold(resource.Name, kind) {
+				matches = append(matches, found{gv: gv, resource: resource})
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return schema.GroupVersion{}, metav1.APIResource{}, fmt.Errorf("the cluster doesn't serve kind %q", kind)
+	case 1:
+		return matches[0].gv, matches[0].resource, nil
+	default:
+		var apiVersions []string
+		for _, m := range matches {
+			apiVersions = append(apiVersions, m.gv.String())
+		}
+		return schema.GroupVersion{}, metav1.APIResource{}, fmt.Errorf("kind %q is served by %s, use --api-version to pick one", kind, strings.Join(apiVersions, ", "))
+	}
+}
+
+// groupVersions returns the versions of a group serving a resource, and the version the cluster
+// prefers for the group if it serves the resource.
+func (c *Client) groupVersions(group, resource string) ([]string, string, error) {
+	groups, err := c.discovery.ServerGroups()
+	if err != nil {
+		return nil, "", fmt.Errorf("discovering API groups: %w", err)
+	}
+	for _, g := range groups.Groups {
+		if g.Name != group {
+			continue
+		}
+		var served []string
+		preferred := ""
+		for _, version := range g.Versions {
+			list, err := c.discovery.ServerResourcesForGroupVersion(version.GroupVersion)
+			if err != nil {
+				return nil, "", fmt.Errorf("discovering resources of %s: %w", version.GroupVersion, err)
+			}
+			for _, r := range list.APIResources {
+				if r.Name != resource {
+					continue
+				}
+				served = append(served, version.GroupVersion)
+				if version.GroupVersion == g.PreferredVersion.GroupVersion {
+					preferred = version.GroupVersion
+				}
+			}
+		}
+		return served, preferred, nil
+	}
+	return nil, "", fmt.Errorf("the cluster doesn't serve API group %q", group)
+}
+
+// ResourceSets returns the ResourceSets of the cluster as the operator resolves them when it takes
+// a backup: with their generated selectors, followed by those of the ResourceSets they include.
+// The source of each selector is the ResourceSet it comes from, suffixed with "(generated)" for
+// generated selectors.
+func (c *Client) ResourceSets(ctx context.Context) ([]*chart.AnnotatedResourceSet, error) {
+	list, err := c.dynamic.Resource(resourceSetsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing ResourceSets: %w", err)
+	}
+	byName := make(map[string]*v1.ResourceSet, len(list.Items))
+	var names []string
+	for _, item := range list.Items {
+		var resourceSet v1.ResourceSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &resourceSet); err != nil {
+			return nil, fmt.Errorf("decoding ResourceSet %s: %w", item.GetName(), err)
+		}
+		byName[resourceSet.Name] = &resourceSet
+		names = append(names, resourceSet.Name)
+	}
+
+	source := generate.NewClusterSource(ctx, c.apiext.ApiextensionsV1().CustomResourceDefinitions(), secretLister{ctx: ctx, client: c.kubernetes})
+	generated := make(map[string]*v1.ResourceSet, len(byName))
+	resolve := func(name string) (*v1.ResourceSet, error) {
+		if resourceSet, ok := generated[name]; ok {
+			return resourceSet, nil
+		}
+		resourceSet, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("ResourceSet %s not found", name)
+		}
+		resolved, err := generate.Resolve(resourceSet, source)
+		if err != nil {
+			return nil, err
+		}
+		generated[name] = resolved
+		return resolved, nil
+	}
+
+	var resourceSets []*chart.AnnotatedResourceSet
+	for _, name := range names {
+		resourceSet, err := resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		sources := selectorSources(byName[name], resourceSet)
+		// include.Resolve appends the selectors of each included ResourceSet right after getting it
+		resolved, err := include.Resolve(resourceSet, func(name string) (*v1.ResourceSet, error) {
+			included, err := resolve(name)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, selectorSources(byName[name], included)...)
+			return included, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		resolved.Status = v1.ResourceSetStatus{}
+		resourceSets = append(resourceSets, &chart.AnnotatedResourceSet{ResourceSet: resolved, SelectorSources: sources})
+	}
+	return resourceSets, nil
+}
+
+// selectorSources returns the sources of the selectors of a ResourceSet resolved with its
+// generated selectors.
+func selectorSources(resourceSet, generated *v1.ResourceSet) [][]string {
+	sources := make([][]string, 0, len(generated.ResourceSelectors))
+	for i := range generated.ResourceSelectors {
+		if i < len(resourceSet.ResourceSelectors) {
+			sources = append(sources, []string{resourceSet.Name})
+		} else {
+			sources = append(sources, []string{resourceSet.Name + " (generated)"})
+		}
+	}
+	return sources
+}
+
+// secretLister lists the secrets Helm stores releases in for generate.NewClusterSource.
+type secretLister struct {
+	ctx    context.Context
+	client kubernetes.Interface
+}
+
+func (s secretLister) List(namespace string, opts metav1.ListOptions) (*corev1.SecretList, error) {
+	return s.client.CoreV1().Secrets(namespace).List(s.ctx, opts)
+}
@@ -2,6 +2,7 @@
 package resourcesetcheck
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -11,6 +12,9 @@ import (
 	"text/tabwriter"
 
 	"github.com/rancher/backup-restore-operator/cmd/tool/internal/chart"
+	"github.com/rancher/backup-restore-operator/cmd/tool/internal/cluster"
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
 
@@ -26,6 +30,7 @@ func Run(args []string) error {
 		apiVersion   string
 		resourcePath string
 		outputFmt    string
+		kubeconfig   string
 	)
 	fs.StringVar(&version, "version", "", "BRO version to check against (e.g. v2.1.0); fetches chart from GitHub.")
 	fs.StringVar(&chartPath, "path", "", "Path to a local rancher-backup helm chart directory or .tgz.")
@@ -34,15 +39,24 @@ func Run(args []string) error {
 	fs.StringVar(&apiVersion, "api-version", "", "API version of the resource (e.g. apps/v1). Omit to match any.")
 	fs.StringVar(&resourcePath, "resource-path", "", "Path to a YAML/JSON file describing the resource (provides kind, name, namespace, labels).")
 	fs.StringVar(&outputFmt, "output", "table", "Output format: table or json.")
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig; checks the resource as it is in the cluster against the cluster's ResourceSets, without caveats.")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
-	chartDir, err := resolveChartPath(version, chartPath)
-	if err != nil {
-		fs.Usage()
-		return err
+	var chartDir string
+	if kubeconfig != "" {
+		if version != "" || chartPath != "" {
+			fs.Usage()
+			return fmt.Errorf("--kubeconfig is mutually exclusive with --version and --path")
+		}
+	} else {
+		var err error
+		if chartDir, err = resolveChartPath(version, chartPath); err != nil {
+			fs.Usage()
+			return err
+		}
 	}
 
 	res, err := resolveResource(resourceFlag, namespace, apiVersion, resourcePath)
@@ -51,9 +65,17 @@ func Run(args []string) error {
 		return err
 	}
 
-	resourceSets, err := chart.LoadAndRenderResourceSets(chartDir)
-	if err != nil {
-		return fmt.Errorf("rendering ResourceSets: %w", err)
+	var resourceSets []*chart.AnnotatedResourceSet
+	if kubeconfig != "" {
+		res, resourceSets, err = loadFromCluster(kubeconfig, res)
+		if err != nil {
+			return err
+		}
+	} else {
+		resourceSets, err = chart.LoadAndRenderResourceSets(chartDir)
+		if err != nil {
+			return fmt.Errorf("rendering ResourceSets: %w", err)
+		}
 	}
 
 	results, err := chart.Check(res, resourceSets)
@@ -81,8 +103,26 @@ func resolveChartPath(version, path string) (string, error) {
 	case version != "":
 		return chart.FetchChartByVersion(version)
 	default:
-		return "", fmt.Errorf("one of --version or --path is required")
+		return "", fmt.Errorf("one of --version, --path or --kubeconfig is required")
+	}
+}
+
+// loadFromCluster reads the resource and the ResourceSets from the cluster of a kubeconfig.
+func loadFromCluster(kubeconfig string, res chart.ResourceInfo) (chart.ResourceInfo, []*chart.AnnotatedResourceSet, error) {
+	ctx := context.Background()
+	client, err := cluster.NewClient(kubeconfig)
+	if err != nil {
+		return res, nil, err
+	}
+	res, err = client.Resource(ctx, res)
+	if err != nil {
+		return res, nil, fmt.Errorf("reading resource: %w", err)
+	}
+	resourceSets, err := client.ResourceSets(ctx)
+	if err != nil {
+		return res, nil, fmt.Errorf("reading ResourceSets: %w", err)
 	}
+	return res, resourceSets, nil
 }
 
 // resolveResource builds a ResourceInfo from the --resource / --resource-path flags.
@@ -219,16 +259,49 @@ func printTable(w io.Writer, res chart.ResourceInfo, results []chart.MatchResult
 			m.ResourceSetName,
 			m.SelectorIndex,
 			strings.Join(m.SelectorSources, ","),
-			sel.APIVersion,
+			fmtAPIVersion(sel),
 			fmtSelector(sel.Kinds, sel.KindsRegexp),
 			fmtSelector(sel.ResourceNames, sel.ResourceNameRegexp),
-			fmtSelector(sel.Namespaces, sel.NamespaceRegexp),
+			fmtNamespaces(sel),
 			caveats,
 		)
 	}
+
+	// Overlapping selectors are listed once per ResourceSet, from its first matching selector.
+	for _, m := range results {
+		if len(m.OverlapsWith) == 0 || m.OverlapsWith[0] < m.SelectorIndex {
+			continue
+		}
+		indexes := []string{fmt.Sprint(m.SelectorIndex)}
+		for _, idx := range m.OverlapsWith {
+			indexes = append(indexes, fmt.Sprint(idx))
+		}
+		fmt.Fprintf(tw, "\nOverlapping selectors in %s: %s (the resource is backed up once)\n", m.ResourceSetName, strings.Join(indexes, ", "))
+	}
 	return nil
 }
 
+// fmtAPIVersion formats the API version of a selector, or its API group regexp, for table display.
+func fmtAPIVersion(sel v1.ResourceSelector) string {
+	if sel.APIGroupRegexp != "" {
+		return "~" + sel.APIGroupRegexp + " (preferred)"
+	}
+	return sel.APIVersion
+}
+
+// fmtNamespaces formats the namespace constraints of a selector for table display.
+func fmtNamespaces(sel v1.ResourceSelector) string {
+	formatted := fmtSelector(sel.Namespaces, sel.NamespaceRegexp)
+	if sel.NamespaceSelector == nil {
+		return formatted
+	}
+	labels := "labels(" + metav1.FormatLabelSelector(sel.NamespaceSelector) + ")"
+	if formatted == "*" {
+		return labels
+	}
+	return formatted + " | " + labels
+}
+
 // fmtSelector formats a list/regexp pair for table display.
 func fmtSelector(list []string, re string) string {
 	var parts []string
@@ -0,0 +1,172 @@
+// Package resourcesetgenerate implements the resource-set:generate subcommand.
+package resourcesetgenerate
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/generate"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Run implements the resource-set:generate subcommand.
+func Run(args []string) error {
+	fs := flag.NewFlagSet("resource-set:generate", flag.ContinueOnError)
+
+	var (
+		crdsPath       string
+		crdGroupRegexp string
+		manifestPath   string
+		namespace      string
+		labelSelector  string
+		name           string
+	)
+	fs.StringVar(&crdsPath, "crds", "", "Path to a YAML/JSON file or directory of CustomResourceDefinitions (e.g. the output of kubectl get crds -o yaml).")
+	fs.StringVar(&crdGroupRegexp, "crd-group-regexp", ".", "Regexp selecting the groups of the CRDs to back up, with --crds.")
+	fs.StringVar(&manifestPath, "manifest", "", "Path to a manifest whose objects are backed up (e.g. the output of helm get manifest).")
+	fs.StringVar(&namespace, "namespace", "", "Namespace of the objects of --manifest which don't set one (the release namespace).")
+	fs.StringVar(&labelSelector, "label", "", "Label selector of the objects to back up in every resource (e.g. app.kubernetes.io/part-of=my-app).")
+	fs.StringVar(&name, "name", "", "Print a ResourceSet with this name instead of a list of selectors for the chart's files/ directory.")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if crdsPath == "" && manifestPath == "" && labelSelector == "" {
+		fs.Usage()
+		return fmt.Errorf("one of --crds, --manifest or --label is required")
+	}
+
+	var selectors []v1.ResourceSelector
+	if crdsPath != "" {
+		crds, err := loadCRDs(crdsPath)
+		if err != nil {
+			return fmt.Errorf("loading CRDs: %w", err)
+		}
+		crdSelectors, err := generate.FromCRDs(crds, crdGroupRegexp)
+		if err != nil {
+			return err
+		}
+		if len(crdSelectors) == 0 {
+			fmt.Fprintf(os.Stderr, "No CRDs found with a group matching %q.\n", crdGroupRegexp)
+		}
+		selectors = append(selectors, crdSelectors...)
+	}
+	if manifestPath != "" {
+		manifest, err := os.ReadFile(manifestPath)
+		if err != nil {
+			return fmt.Errorf("reading manifest: %w", err)
+		}
+		manifestSelectors, err := generate.FromManifest(manifest, namespace)
+		if err != nil {
+			return err
+		}
+		selectors = append(selectors, manifestSelectors...)
+	}
+	if labelSelector != "" {
+		parsed, err := metav1.ParseToLabelSelector(labelSelector)
+		if err != nil {
+			return fmt.Errorf("parsing --label: %w", err)
+		}
+		selectors = append(selectors, generate.FromLabelSelector(parsed)...)
+	}
+
+	return printSelectors(os.Stdout, selectors, name)
+}
+
+// printSelectors prints the selectors as a ResourceSet named name, or as the list of selectors the chart's ResourceSets
+// read from their files/ directory when name is empty.
+func printSelectors(w io.Writer, selectors []v1.ResourceSelector, name string) error {
+	var out interface{} = selectors
+	if name != "" {
+		out = &v1.ResourceSet{
+			TypeMeta:          metav1.TypeMeta{APIVersion: "resources.cattle.io/v1", Kind: "ResourceSet"},
+			ObjectMeta:        metav1.ObjectMeta{Name: name},
+			ResourceSelectors: selectors,
+		}
+	}
+	data, err := yaml.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("marshaling selectors: %w", err)
+	}
+	if name != "" {
+		// the status and creation timestamp of a new ResourceSet are empty
+		data = bytes.ReplaceAll(data, []byte("  creationTimestamp: null\n"), nil)
+		data = bytes.ReplaceAll(data, []byte("status: {}\n"), nil)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// loadCRDs reads the CustomResourceDefinitions of a file, or of the .yaml, .yml and .json files of a directory. Lists of
+// objects are expanded, and objects of other kinds are ignored.
+func loadCRDs(path string) ([]apiextv1.CustomResourceDefinition, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+	}
+
+	var crds []apiextv1.CustomResourceDefinition
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+		for {
+			var obj unstructured.Unstructured
+			if err := decoder.Decode(&obj.Object); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("decoding %s: %w", file, err)
+			}
+			objects := []unstructured.Unstructured{obj}
+			if obj.IsList() {
+				list, err := obj.ToList()
+				if err != nil {
+					return nil, fmt.Errorf("decoding %s: %w", file, err)
+				}
+				objects = list.Items
+			}
+			for _, object := range objects {
+				if object.GetKind() != "CustomResourceDefinition" {
+					continue
+				}
+				var crd apiextv1.CustomResourceDefinition
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &crd); err != nil {
+					return nil, fmt.Errorf("decoding CRD %s in %s: %w", object.GetName(), file, err)
+				}
+				crds = append(crds, crd)
+			}
+		}
+	}
+	return crds, nil
+}
@@ -11,6 +11,8 @@ import (
 	"text/tabwriter"
 
 	"github.com/rancher/backup-restore-operator/cmd/tool/internal/chart"
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
 
@@ -112,10 +114,20 @@ func printTable(w io.Writer, resourceSets []*chart.AnnotatedResourceSet) error {
 			fmt.Fprintf(tw, "  %d\t%s\t%s\t%s\t%s\t%s\n",
 				i+1,
 				strings.Join(ars.SelectorSources[i], ","),
-				sel.APIVersion,
+				fmtAPIVersion(sel),
 				fmtSelector(sel.Kinds, sel.KindsRegexp),
 				fmtSelector(sel.ResourceNames, sel.ResourceNameRegexp),
-				fmtSelector(sel.Namespaces, sel.NamespaceRegexp),
+				fmtNamespaces(sel),
+			)
+		}
+		for i, sel := range ars.ExcludeSelectors {
+			fmt.Fprintf(tw, "  x%d\t%s\t%s\t%s\t%s\t%s\n",
+				i+1,
+				"(excluded)",
+				fmtAPIVersion(sel),
+				fmtSelector(sel.Kinds, sel.KindsRegexp),
+				fmtSelector(sel.ResourceNames, sel.ResourceNameRegexp),
+				fmtNamespaces(sel),
 			)
 		}
 		fmt.Fprintln(tw)
@@ -123,6 +135,27 @@ func printTable(w io.Writer, resourceSets []*chart.AnnotatedResourceSet) error {
 	return nil
 }
 
+// fmtAPIVersion formats the API version of a selector, or its API group regexp, for table display.
+func fmtAPIVersion(sel v1.ResourceSelector) string {
+	if sel.APIGroupRegexp != "" {
+		return "~" + sel.APIGroupRegexp + " (preferred)"
+	}
+	return sel.APIVersion
+}
+
+// fmtNamespaces formats the namespace constraints of a selector for table display.
+func fmtNamespaces(sel v1.ResourceSelector) string {
+	formatted := fmtSelector(sel.Namespaces, sel.NamespaceRegexp)
+	if sel.NamespaceSelector == nil {
+		return formatted
+	}
+	labels := "labels(" + metav1.FormatLabelSelector(sel.NamespaceSelector) + ")"
+	if formatted == "*" {
+		return labels
+	}
+	return formatted + " | " + labels
+}
+
 // fmtSelector formats a list/regexp pair for table display.
 // List items are joined with commas. A regexp is shown with a "~" prefix.
 // If both are set, they are separated by " | ". Empty fields show "*".
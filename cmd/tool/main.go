@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/rancher/backup-restore-operator/cmd/tool/internal/cmd/resourcesetcheck"
+	"github.com/rancher/backup-restore-operator/cmd/tool/internal/cmd/resourcesetgenerate"
 	"github.com/rancher/backup-restore-operator/cmd/tool/internal/cmd/resourcesetview"
 	"github.com/rancher/backup-restore-operator/pkg/version"
 	"github.com/sirupsen/logrus"
@@ -27,8 +28,9 @@ func usage() {
 	fmt.Fprintf(os.Stderr, "Versioned alongside BRO; see BRO docs for compatibility notes.\n\n")
 	fmt.Fprintf(os.Stderr, "Usage: bro-tool [flags] <command> [command flags]\n\n")
 	fmt.Fprintf(os.Stderr, "Commands:\n")
-	fmt.Fprintf(os.Stderr, "  resource-set:view   View the ResourceSets defined by a BRO helm chart.\n")
-	fmt.Fprintf(os.Stderr, "  resource-set:check  Check whether a resource would be covered by any ResourceSet rule.\n\n")
+	fmt.Fprintf(os.Stderr, "  resource-set:view      View the ResourceSets defined by a BRO helm chart.\n")
+	fmt.Fprintf(os.Stderr, "  resource-set:check     Check whether a resource would be covered by any ResourceSet rule.\n")
+	fmt.Fprintf(os.Stderr, "  resource-set:generate  Generate ResourceSet selectors from CRDs, a Helm release manifest or a label.\n\n")
 	fmt.Fprintf(os.Stderr, "Flags:\n")
 	flag.PrintDefaults()
 }
@@ -58,6 +60,8 @@ func main() {
 		err = resourcesetview.Run(args[1:])
 	case "resource-set:check":
 		err = resourcesetcheck.Run(args[1:])
+	case "resource-set:generate":
+		err = resourcesetgenerate.Run(args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "unknown command: %q\n\n", args[0])
 		flag.Usage()
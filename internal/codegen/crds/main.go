@@ -69,9 +69,11 @@ func runControllerGen(cmdArgs []string) {
 func copyCRDsToChart() {
 	// Mapping of generated CRD files to chart template names
 	crdMapping := map[string]string{
-		"resources.cattle.io_backups.yaml":      "backup.yaml",
-		"resources.cattle.io_resourcesets.yaml": "resourceset.yaml",
-		"resources.cattle.io_restores.yaml":     "restore.yaml",
+		"resources.cattle.io_backuparchives.yaml":   "backuparchive.yaml",
+		"resources.cattle.io_backupreencrypts.yaml": "backupreencrypt.yaml",
+		"resources.cattle.io_backups.yaml":          "backup.yaml",
+		"resources.cattle.io_resourcesets.yaml":     "resourceset.yaml",
+		"resources.cattle.io_restores.yaml":         "restore.yaml",
 	}
 
 	srcDir := "./pkg/crds/yaml/generated"
@@ -15,6 +15,54 @@ const (
 	RecurringBackupType BackupType = "Recurring"
 )
 
+// ConcurrencyPolicy describes how a recurring backup is scheduled when a snapshot is still being taken at the time the
+// next one is due. Unlike the concurrencyPolicy of a CronJob, the snapshots of a Backup never run concurrently and none is
+// replaced: the policy only decides whether the next snapshot is scheduled from the start or from the end of the
+// previous one.
+// +kubebuilder:validation:Enum=Allow;Forbid
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent schedules the next snapshot from the start of the current one, so a slot missed while a slow
+	// snapshot was running is taken immediately after it finishes.
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+	// ForbidConcurrent schedules the next snapshot from the end of the current one, skipping any slot missed while it was running.
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+)
+
+// ArchiveDeletionPolicy describes what happens to the archives of a backup when the Backup is deleted
+// +kubebuilder:validation:Enum=Retain;Delete
+type ArchiveDeletionPolicy string
+
+const (
+	// RetainArchives keeps the archives of a deleted Backup in storage
+	RetainArchives ArchiveDeletionPolicy = "Retain"
+	// DeleteArchives deletes the archives taken by a Backup in this cluster when it is deleted
+	DeleteArchives ArchiveDeletionPolicy = "Delete"
+)
+
+// CompressionAlgorithm is the algorithm backup archives are compressed with
+// +kubebuilder:validation:Enum=gzip;zstd
+type CompressionAlgorithm string
+
+const (
+	// GzipCompression compresses archives with gzip, their extension is .tar.gz
+	GzipCompression CompressionAlgorithm = "gzip"
+	// ZstdCompression compresses archives with zstd, their extension is .tar.zst
+	ZstdCompression CompressionAlgorithm = "zstd"
+)
+
+// BackupArchivesFinalizer is set on Backups whose deletion policy is Delete, until their archives are deleted
+const BackupArchivesFinalizer = "resources.cattle.io/delete-archives"
+
+// TriggerSnapshotAnnotation can be set on a Backup to take a snapshot immediately, regardless of its schedule or suspension.
+// Every new value of the annotation triggers one snapshot, example: kubectl annotate backup <name> resources.cattle.io/trigger-snapshot="$(date +%s)" --overwrite
+const TriggerSnapshotAnnotation = "resources.cattle.io/trigger-snapshot"
+
+// TriggerSnapshotUserAnnotation is set by the admission webhook to the user who set the trigger-snapshot annotation,
+// it can't be set by users while the webhook is enabled
+const TriggerSnapshotUserAnnotation = "resources.cattle.io/trigger-snapshot-user"
+
 var (
 	BackupConditionReady        condition.Cond = "Ready"
 	BackupConditionUploaded     condition.Cond = "Uploaded"
@@ -56,6 +104,19 @@ type BackupSpec struct {
 	// Name of the Secret containing the encryption config
 	// +optional
 	EncryptionConfigSecretName string `json:"encryptionConfigSecretName,omitempty"`
+	// Name of the Secret containing the age recipients or KMS plugin the whole archive is encrypted to
+	// +optional
+	ArchiveEncryptionSecretName string `json:"archiveEncryptionSecretName,omitempty"`
+	// Compression of the archive, gzip at its default level when unset
+	// +optional
+	// +nullable
+	Compression *Compression `json:"compression,omitempty"`
+	// When true, the backup fails instead of storing secrets and the operator's sensitive resources unencrypted.
+	// Encryption is required regardless when the operator's requireEncryption setting is enabled, false doesn't
+	// override it.
+	// +optional
+	// +nullable
+	RequireEncryption *bool `json:"requireEncryption,omitempty"`
 	// Cron schedule for recurring backups
 	// +kubebuilder:example="Descriptors: '@midnight'\nStandard crontab specs: 0 0 * * *"
 	// +optional
@@ -63,6 +124,74 @@ type BackupSpec struct {
 	// +kubebuilder:validation:Minimum=1
 	// +optional
 	RetentionCount int64 `json:"retentionCount,omitempty"`
+	// Tiered retention of the backup's archives. When set, it replaces retentionCount for deleting the archives of
+	// recurring backups, and is also applied to one-time backups each time they take a snapshot.
+	// +optional
+	// +nullable
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+	// IANA name of the time zone the schedule is evaluated in, example "Europe/Berlin". Defaults to the operator's local time zone.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// Upper bound in seconds of a random delay added to each scheduled snapshot, to spread load when many backups share a schedule
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	JitterSeconds int64 `json:"jitterSeconds,omitempty"`
+	// Deadline in seconds for starting a scheduled snapshot. A snapshot missed by more than this, for instance because
+	// the operator was down, is skipped and the next one is scheduled instead. Missed snapshots are always taken when unset.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartingDeadlineSeconds int64 `json:"startingDeadlineSeconds,omitempty"`
+	// When set to true, no further snapshots are taken until it is set back to false
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// How to schedule the next snapshot when the current one is still running at the time it is due: from the start
+	// (Allow) or from the end (Forbid) of the current one, defaults to Forbid. Snapshots never run concurrently.
+	// +optional
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// What happens to the archives taken by the backup in this cluster when the Backup is deleted, defaults to Retain
+	// +optional
+	DeletionPolicy ArchiveDeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// RetentionPolicy keeps the archives of a backup following grandfather-father-son rules, evaluated against the
+// timestamps in the archives' filenames. An archive is kept when any rule keeps it, unless it is older than maxAge.
+type RetentionPolicy struct {
+	// Number of most recent hours to keep the newest archive of
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepHourly int `json:"keepHourly,omitempty"`
+	// Number of most recent days to keep the newest archive of
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepDaily int `json:"keepDaily,omitempty"`
+	// Number of most recent ISO weeks to keep the newest archive of
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepWeekly int `json:"keepWeekly,omitempty"`
+	// Number of most recent months to keep the newest archive of
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepMonthly int `json:"keepMonthly,omitempty"`
+	// Archives older than this are deleted, example "720h". When no keep rule is set, all younger archives are kept.
+	// +optional
+	// +nullable
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+	// Number of most recent archives that are always kept, regardless of the other rules
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinKeep int `json:"minKeep,omitempty"`
+}
+
+// Compression describes how the archive of a backup is compressed
+type Compression struct {
+	// Compression algorithm, gzip when unset
+	// +optional
+	Algorithm CompressionAlgorithm `json:"algorithm,omitempty"`
+	// Compression level, from 1 to 9 for gzip and from 1 to 22 for zstd. The default level of the algorithm is used when unset
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=22
+	Level int `json:"level,omitempty"`
 }
 
 type BackupStatus struct {
@@ -76,4 +205,83 @@ type BackupStatus struct {
 	BackupType         BackupType                          `json:"backupType,omitempty"`
 	Filename           string                              `json:"filename,omitempty"`
 	Summary            string                              `json:"summary,omitempty"`
+	// Snapshots taken by this backup, newest first. Entries are pruned along with the archives deleted by the retention
+	// policy, and only the most recent failed attempts are kept.
+	// +listType=atomic
+	// +optional
+	History []BackupSnapshot `json:"history,omitempty"`
+	// Last snapshot triggered through the trigger-snapshot annotation
+	// +optional
+	// +nullable
+	LastTrigger *BackupTrigger `json:"lastTrigger,omitempty"`
+}
+
+// SnapshotResult is the outcome of a single snapshot
+// +kubebuilder:validation:Enum=Succeeded;Failed
+type SnapshotResult string
+
+const (
+	SnapshotSucceeded SnapshotResult = "Succeeded"
+	SnapshotFailed    SnapshotResult = "Failed"
+)
+
+// BackupSnapshot describes a single snapshot taken by a Backup
+type BackupSnapshot struct {
+	// Name of the backup archive
+	Filename string `json:"filename,omitempty"`
+	// Size of the archive in bytes
+	Size int64 `json:"size,omitempty"`
+	// Number of objects stored in the archive
+	ObjectCount int64 `json:"objectCount,omitempty"`
+	// Time the snapshot was started
+	StartedAt string `json:"startedAt,omitempty"`
+	// Time it took to take and store the snapshot, example "1m30s"
+	Duration string `json:"duration,omitempty"`
+	// Type of storage the archive was written to, S3 or PV
+	StorageLocation string `json:"storageLocation,omitempty"`
+	// Full location of the archive, example "s3://bucket/folder/archive.tar.gz"
+	Location string         `json:"location,omitempty"`
+	Result   SnapshotResult `json:"result,omitempty"`
+	// Error that caused a failed snapshot
+	Message string `json:"message,omitempty"`
+	// SelectorOverlaps lists the objects selected by several selectors of the ResourceSet, which are stored once
+	// +listType=atomic
+	// +optional
+	SelectorOverlaps []SelectorOverlap `json:"selectorOverlaps,omitempty"`
+}
+
+// SelectorOverlap counts the objects of a resource selected by a selector of a ResourceSet which an earlier selector
+// already selected
+type SelectorOverlap struct {
+	// Selector which first selected the objects
+	FirstSelector SelectorReference `json:"firstSelector"`
+	// Selector which selected the objects again
+	Selector    SelectorReference `json:"selector"`
+	APIVersion  string            `json:"apiVersion"`
+	Resource    string            `json:"resource"`
+	ObjectCount int               `json:"objectCount"`
+}
+
+// SelectorReference identifies a selector of the ResourceSet of a Backup, or of a ResourceSet it includes
+type SelectorReference struct {
+	// Name of the ResourceSet the selector belongs to
+	ResourceSet string `json:"resourceSet"`
+	// Position of the selector, starting at 1, in the resourceSelectors of the ResourceSet, or in the selectors it
+	// generates when generated is true
+	Position int `json:"position"`
+	// Generated is true for a selector generated by the generate field of the ResourceSet
+	// +optional
+	Generated bool `json:"generated,omitempty"`
+}
+
+// BackupTrigger records a snapshot that was requested through the trigger-snapshot annotation
+type BackupTrigger struct {
+	// Value of the annotation that requested the snapshot
+	Token string `json:"token,omitempty"`
+	// Field manager that set the annotation, example "kubectl-annotate". It names the client which set the annotation.
+	FieldManager string `json:"fieldManager,omitempty"`
+	// User who set the annotation, recorded by the admission webhook. Empty when the webhook is disabled.
+	User string `json:"user,omitempty"`
+	// Time the triggered snapshot was taken
+	TriggeredAt string `json:"triggeredAt,omitempty"`
 }
@@ -0,0 +1,56 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupArchiveStorageLocationLabel is set on every BackupArchive to the hash of the storage location it was found in
+const BackupArchiveStorageLocationLabel = "resources.cattle.io/storage-location"
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Backup",type=string,JSONPath=`.spec.backupName`
+// +kubebuilder:printcolumn:name="Cluster-UID",type=string,JSONPath=`.spec.clusterUID`
+// +kubebuilder:printcolumn:name="Timestamp",type=string,JSONPath=`.spec.timestamp`
+// +kubebuilder:printcolumn:name="Location-Type",type=string,JSONPath=`.spec.storageLocationType`
+// +kubebuilder:printcolumn:name="Size",type=integer,JSONPath=`.spec.size`
+// +kubebuilder:printcolumn:name="Encrypted",type=boolean,JSONPath=`.spec.encrypted`
+// +kubebuilder:printcolumn:name="Filename",type=string,JSONPath=`.spec.filename`,priority=1
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BackupArchive is a catalog entry for a backup archive found in a storage location. BackupArchives are maintained by
+// the operator, which periodically lists the storage locations it knows of, so that archives can be found and restored
+// in a cluster that has no Backup CR for them, such as a freshly provisioned cluster after a disaster.
+type BackupArchive struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BackupArchiveSpec `json:"spec"`
+}
+
+type BackupArchiveSpec struct {
+	// Filename of the archive, to be used as backupFilename of a Restore
+	Filename string `json:"filename"`
+	// BackupName is the name of the Backup CR that created the archive
+	BackupName string `json:"backupName"`
+	// ClusterUID is the UID of the kube-system namespace of the cluster the archive was taken from
+	ClusterUID string `json:"clusterUID"`
+	// Timestamp is the time the archive was taken at, in RFC3339 format
+	Timestamp string `json:"timestamp"`
+	// +optional
+	Size int64 `json:"size,omitempty"`
+	// +optional
+	Encrypted bool `json:"encrypted,omitempty"`
+	// Sealed archives are encrypted as a whole, with the keys of an archive encryption secret
+	// +optional
+	Sealed bool `json:"sealed,omitempty"`
+	// StorageLocationType is either PV or S3
+	StorageLocationType string `json:"storageLocationType"`
+	// StorageLocation is the S3 location of the archive, to be used as storageLocation of a Restore.
+	// It is unset for archives in the operator's default storage location.
+	// +optional
+	// +nullable
+	StorageLocation *StorageLocation `json:"storageLocation,omitempty"`
+}
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"github.com/rancher/wrangler/v3/pkg/condition"
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	BackupReencryptConditionReady       condition.Cond = "Ready"
+	BackupReencryptConditionReconciling condition.Cond = "Reconciling"
+)
+
+// ArchiveReencryptionPhase is the progress of the re-encryption of an archive
+type ArchiveReencryptionPhase string
+
+const (
+	ArchiveReencryptionPending     ArchiveReencryptionPhase = "Pending"
+	ArchiveReencryptionReencrypted ArchiveReencryptionPhase = "Reencrypted"
+	ArchiveReencryptionFailed      ArchiveReencryptionPhase = "Failed"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Backup",type=string,JSONPath=`.spec.backupName`
+// +kubebuilder:printcolumn:name="Total",type=integer,JSONPath=`.status.total`
+// +kubebuilder:printcolumn:name="Reencrypted",type=integer,JSONPath=`.status.reencrypted`
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.failed`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].message`
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BackupReencrypt re-encrypts the stored archives of a Backup after its encryption config was rotated, so that the
+// previous encryption config is no longer needed to restore them
+type BackupReencrypt struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackupReencryptSpec   `json:"spec"`
+	Status BackupReencryptStatus `json:"status,omitempty"`
+}
+
+type BackupReencryptSpec struct {
+	// Name of the Backup whose encrypted archives taken in this cluster are re-encrypted
+	BackupName string `json:"backupName"`
+	// Storage location of the archives, the Backup's storage location is used when unset
+	// +optional
+	// +nullable
+	StorageLocation *StorageLocation `json:"storageLocation,omitempty"`
+	// Name of the Secret containing the encryption config the archives were encrypted with
+	OldEncryptionConfigSecretName string `json:"oldEncryptionConfigSecretName"`
+	// Name of the Secret containing the encryption config the archives are re-encrypted with
+	NewEncryptionConfigSecretName string `json:"newEncryptionConfigSecretName"`
+	// Name of the Secret containing the age keys or KMS plugin of archives encrypted as a whole. These archives are
+	// decrypted with its identities and encrypted again to its recipients.
+	// +optional
+	ArchiveEncryptionSecretName string `json:"archiveEncryptionSecretName,omitempty"`
+}
+
+type BackupReencryptStatus struct {
+	// +listType=map
+	// +listMapKey=type
+	Conditions         []genericcondition.GenericCondition `json:"conditions,omitempty"`
+	ObservedGeneration int64                               `json:"observedGeneration,omitempty"`
+	// Total number of archives to re-encrypt
+	Total int `json:"total,omitempty"`
+	// Number of archives re-encrypted and verified
+	Reencrypted int `json:"reencrypted,omitempty"`
+	// Number of archives that could not be re-encrypted
+	Failed int `json:"failed,omitempty"`
+	// Archives lists the progress of each archive to re-encrypt, archives are re-encrypted in order
+	Archives []ArchiveReencryption `json:"archives,omitempty"`
+	// Time the last archive was processed
+	CompletionTimestamp string `json:"completionTimestamp,omitempty"`
+}
+
+// ArchiveReencryption is the progress of the re-encryption of an archive
+type ArchiveReencryption struct {
+	Filename string                   `json:"filename"`
+	Phase    ArchiveReencryptionPhase `json:"phase"`
+	// Number of resources re-encrypted in the archive
+	// +optional
+	ResourceCount int `json:"resourceCount,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
@@ -1,5 +1,9 @@
 package v1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 type StorageLocation struct {
 	// +optional
 	// +nullable
@@ -24,6 +28,67 @@ type S3ObjectStore struct {
 	// +optional
 	// +nullable
 	ClientConfig *ClientConfig `json:"clientConfig,omitempty"`
+	// +optional
+	// +nullable
+	ObjectLock *ObjectLock `json:"objectLock,omitempty"`
+	// +optional
+	// +nullable
+	ServerSideEncryption *ServerSideEncryption `json:"serverSideEncryption,omitempty"`
+	// Storage class of uploaded backup files, example "STANDARD_IA". The bucket's default storage class is used when unset.
+	// +optional
+	StorageClass string `json:"storageClass,omitempty"`
+	// Tags set on uploaded backup files
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+	// User metadata set on uploaded backup files
+	// +optional
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ServerSideEncryptionType is the kind of S3 server-side encryption of backup files
+// +kubebuilder:validation:Enum=SSE-S3;SSE-KMS;SSE-C
+type ServerSideEncryptionType string
+
+const (
+	// SSES3 encrypts backup files with keys managed by S3
+	SSES3 ServerSideEncryptionType = "SSE-S3"
+	// SSEKMS encrypts backup files with a key managed by the KMS of the S3 provider
+	SSEKMS ServerSideEncryptionType = "SSE-KMS"
+	// SSEC encrypts backup files with a key provided by the operator, which is needed again to download them
+	SSEC ServerSideEncryptionType = "SSE-C"
+)
+
+// ServerSideEncryption configures the S3 server-side encryption of uploaded backup files
+type ServerSideEncryption struct {
+	Type ServerSideEncryptionType `json:"type"`
+	// ID of the KMS key used by SSE-KMS, the bucket's default key is used when unset
+	// +optional
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
+	// Encryption context used by SSE-KMS
+	// +optional
+	KMSContext map[string]string `json:"kmsContext,omitempty"`
+	// Name of the Secret holding the 32 bytes key used by SSE-C in its sseCustomerKey field
+	// +optional
+	CustomerKeySecretName string `json:"customerKeySecretName,omitempty"`
+	// Namespace of the Secret holding the key used by SSE-C
+	// +optional
+	CustomerKeySecretNamespace string `json:"customerKeySecretNamespace,omitempty"`
+}
+
+// ObjectLock configures S3 Object Lock on uploaded backup files, to make them immutable. The bucket must have object lock enabled.
+type ObjectLock struct {
+	// Retention mode of uploaded backup files, GOVERNANCE or COMPLIANCE. When unset, the bucket's default retention applies.
+	// +kubebuilder:validation:Enum=GOVERNANCE;COMPLIANCE
+	// +optional
+	Mode string `json:"mode,omitempty"`
+	// How long uploaded backup files are retained, example "720h". Defaults to how long the backup's retention policy
+	// keeps them: its maxAge, or its retentionCount times the interval of its schedule.
+	// +optional
+	// +nullable
+	RetainFor *metav1.Duration `json:"retainFor,omitempty"`
+	// Places a legal hold on uploaded backup files, which prevents their deletion until the hold is removed
+	// +optional
+	LegalHold bool `json:"legalHold,omitempty"`
 }
 
 // ClientConfig allows configuration of more advanced minio client settings
@@ -1,15 +1,24 @@
 package v1
 
 import (
+	"github.com/rancher/wrangler/v3/pkg/condition"
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 )
 
+var (
+	ResourceSetConditionReady       condition.Cond = "Ready"
+	ResourceSetConditionReconciling condition.Cond = "Reconciling"
+)
+
 // +genclient
 // +genclient:nonNamespaced
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].message`
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 type ResourceSet struct {
@@ -20,21 +29,116 @@ type ResourceSet struct {
 	// +kubebuilder:default:={}
 	// +required
 	ResourceSelectors []ResourceSelector `json:"resourceSelectors"`
+	// ExcludeSelectors select objects which are not backed up, nor deleted by restores with prune, even though
+	// resourceSelectors select them. An object is excluded whichever version of its group the selectors use.
+	// +listType=atomic
+	// +optional
+	ExcludeSelectors []ResourceSelector `json:"excludeSelectors,omitempty"`
+	// Include lists the names of other ResourceSets whose ResourceSelectors and ControllerReferences are merged into
+	// this one when a backup is taken, and recorded with the merged selectors in the backup. Included ResourceSets can
+	// include others in turn.
+	// +listType=atomic
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Generate builds resource selectors from what is installed in the cluster each time a backup is taken, they are
+	// merged after ResourceSelectors and recorded with them in the backup
+	// +optional
+	// +nullable
+	Generate *ResourceSetGenerator `json:"generate,omitempty"`
 	// ControllerReferences lists controllers to scale down during restore operations.
 	// +listType=atomic
 	// +kubebuilder:default:={}
 	// +optional
 	ControllerReferences []ControllerReference `json:"controllerReferences,omitempty"`
+
+	Status ResourceSetStatus `json:"status,omitempty"`
+}
+
+type ResourceSetStatus struct {
+	// +listType=map
+	// +listMapKey=type
+	Conditions         []genericcondition.GenericCondition `json:"conditions,omitempty"`
+	ObservedGeneration int64                               `json:"observedGeneration,omitempty"`
+	// Selectors lists what each resource selector selects in the cluster, in the order of resourceSelectors and the
+	// generated selectors, followed by the selectors of the included ResourceSets
+	Selectors []ResourceSelectorStatus `json:"selectors,omitempty"`
+	// Number of objects selected by all the selectors, objects selected by several selectors are counted once per selector
+	ObjectCount int `json:"objectCount,omitempty"`
+	// Time the selectors were last resolved against the cluster
+	LastResolvedTimestamp string `json:"lastResolvedTimestamp,omitempty"`
+}
+
+// ResourceSetGenerator selects what resource selectors are generated from, the selectors of each field set are merged
+type ResourceSetGenerator struct {
+	// CRDGroupRegexp selects the CustomResourceDefinitions whose group matches, and their objects in the version each
+	// CRD stores them in
+	// +optional
+	CRDGroupRegexp string `json:"crdGroupRegexp,omitempty"`
+	// HelmReleases selects the objects of the manifest of the deployed revision of each Helm release
+	// +listType=atomic
+	// +optional
+	HelmReleases []HelmReleaseReference `json:"helmReleases,omitempty"`
+	// LabelSelector selects the objects of every resource whose labels match
+	// +optional
+	// +nullable
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+// HelmReleaseReference identifies a Helm release
+type HelmReleaseReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// ResourceSelectorStatus is what a resource selector selects in the cluster
+type ResourceSelectorStatus struct {
+	// Resources lists the resources matched by the selector, with the number of their objects it selects
+	// +optional
+	Resources []SelectedResource `json:"resources,omitempty"`
+	// Number of objects selected
+	// +optional
+	ObjectCount int `json:"objectCount,omitempty"`
+	// UnknownAPIVersions lists the API versions selected which the cluster doesn't serve
+	// +optional
+	UnknownAPIVersions []string `json:"unknownAPIVersions,omitempty"`
+	// SkippedResources lists the resources matched whose objects are not backed up, because they can't be listed and
+	// the selector doesn't name the objects to get
+	// +optional
+	SkippedResources []SkippedResource `json:"skippedResources,omitempty"`
+	// Error is set when the selector is invalid, or its objects could not be gathered
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// SelectedResource is a resource matched by a resource selector
+type SelectedResource struct {
+	APIVersion  string `json:"apiVersion"`
+	Resource    string `json:"resource"`
+	ObjectCount int    `json:"objectCount"`
+}
+
+// SkippedResource is a resource matched by a resource selector whose objects are not backed up
+type SkippedResource struct {
+	APIVersion string `json:"apiVersion"`
+	Resource   string `json:"resource"`
+	Reason     string `json:"reason"`
 }
 
 type ResourceSelector struct {
 	// Selector logic:
 	// - Fields with matching names (e.g., Kinds + KindsRegexp, ResourceNames + ResourceNameRegexp, Namespaces + NamespaceRegexp) are combined via OR
 	// - Different field groups are combined via AND
-	// Example: (Kinds OR KindsRegexp) AND (ResourceNames OR ResourceNameRegexp) AND (Namespaces OR NamespaceRegexp)
+	// Example: (Kinds OR KindsRegexp) AND (ResourceNames OR ResourceNameRegexp) AND (Namespaces OR NamespaceRegexp OR NamespaceSelector) AND Match
 
-	// +required
-	APIVersion string `json:"apiVersion"`
+	// APIVersion selects the resources of one group version, example "v1" or "management.cattle.io/v3". It is required
+	// unless APIGroupRegexp is set.
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// APIGroupRegexp selects the resources of every API group whose name matches, in the version the API server
+	// prefers for the group, so that an object served by several versions of its group is selected once. The core group
+	// is named "" and is matched by "^$", "." matches every group. It can't be combined with APIVersion.
+	// +optional
+	APIGroupRegexp string `json:"apiGroupRegexp,omitempty"`
 	// +listType=set
 	// +optional
 	Kinds []string `json:"kinds,omitempty"`
@@ -50,6 +154,11 @@ type ResourceSelector struct {
 	Namespaces []string `json:"namespaces,omitempty"`
 	// +optional
 	NamespaceRegexp string `json:"namespaceRegexp,omitempty"`
+	// Selects namespaced resources in the namespaces whose labels match. It is ignored for cluster scoped resources, and
+	// for resources which can't be listed
+	// +optional
+	// +nullable
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 	// +optional
 	// +nullable
 	LabelSelectors *metav1.LabelSelector `json:"labelSelectors,omitempty"`
@@ -60,6 +169,11 @@ type ResourceSelector struct {
 	ExcludeKinds []string `json:"excludeKinds,omitempty"`
 	// +optional
 	ExcludeResourceNameRegexp string `json:"excludeResourceNameRegexp,omitempty"`
+	// Match is a CEL expression evaluated on each object selected by the other fields, as the variable "object".
+	// Only the objects for which it returns true are selected, example `!has(object.type) || object.type !=
+	// "helm.sh/release.v1"`. Objects missing a field the expression reads fail the backup, check them with has().
+	// +optional
+	Match string `json:"match,omitempty"`
 }
 
 // ControllerReference identifies a controller to scale down during restore operations.
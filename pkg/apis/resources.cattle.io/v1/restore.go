@@ -11,7 +11,7 @@ import (
 // +kubebuilder:resource:scope=Cluster
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Backup-Source",type=string,JSONPath=`.status.backupSource`
-// +kubebuilder:printcolumn:name="Backup-File",type=string,JSONPath=`.spec.backupFilename`
+// +kubebuilder:printcolumn:name="Backup-File",type=string,JSONPath=`.status.backupFilename`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].message`
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -25,8 +25,21 @@ type Restore struct {
 }
 
 type RestoreSpec struct {
-	// +required
-	BackupFilename string `json:"backupFilename"`
+	// BackupFilename is the exact filename of the backup to restore.
+	// When unset, the backup is selected from the storage location by backupRef, latestFor and asOf.
+	// +optional
+	BackupFilename string `json:"backupFilename,omitempty"`
+	// BackupRef selects a backup taken by the Backup CR of the given name
+	// +optional
+	// +nullable
+	BackupRef *BackupReference `json:"backupRef,omitempty"`
+	// LatestFor selects the latest backup of the cluster whose kube-system namespace UID starts with this prefix
+	// +optional
+	LatestFor string `json:"latestFor,omitempty"`
+	// AsOf only selects backups taken at or before this time, in RFC3339 format.
+	// On its own, it selects the latest backup taken at or before this time.
+	// +optional
+	AsOf string `json:"asOf,omitempty"`
 	// +optional
 	// +nullable
 	StorageLocation *StorageLocation `json:"storageLocation,omitempty"`
@@ -40,12 +53,26 @@ type RestoreSpec struct {
 	DeleteTimeoutSeconds int `json:"deleteTimeoutSeconds,omitempty"`
 	// +optional
 	EncryptionConfigSecretName string `json:"encryptionConfigSecretName,omitempty"`
+	// Name of the Secret containing the age identities or KMS plugin that decrypt an encrypted archive
+	// +optional
+	ArchiveEncryptionSecretName string `json:"archiveEncryptionSecretName,omitempty"`
 
 	// When set to true, the controller ignores any errors during the restore process
 	// +optional
 	IgnoreErrors bool `json:"ignoreErrors,omitempty"`
 }
 
+// BackupReference selects one of the backups taken by a Backup CR
+type BackupReference struct {
+	// Name of the Backup CR
+	Name string `json:"name"`
+	// Index of the backup to restore among the backups taken by the Backup CR, from newest to oldest.
+	// The latest backup is restored by default.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Index int `json:"index,omitempty"`
+}
+
 // GetPrune returns the prune value, defaulting to true if unset
 // This helper consolidates the existing logic of Prune value in a single place.
 func (rs *RestoreSpec) GetPrune() bool {
@@ -62,5 +89,10 @@ type RestoreStatus struct {
 	RestoreCompletionTS string                              `json:"restoreCompletionTs,omitempty"`
 	ObservedGeneration  int64                               `json:"observedGeneration,omitempty"`
 	BackupSource        string                              `json:"backupSource,omitempty"`
-	Summary             string                              `json:"summary,omitempty"`
+	// BackupFilename is the filename of the backup being restored, as selected when the restore started
+	BackupFilename string `json:"backupFilename,omitempty"`
+	// BackupFilenameGeneration is the generation of the restore BackupFilename was selected for, the backup is
+	// selected again once the restore changes
+	BackupFilenameGeneration int64  `json:"backupFilenameGeneration,omitempty"`
+	Summary                  string `json:"summary,omitempty"`
 }
@@ -44,6 +44,40 @@ func NewBackup(namespace, name string, obj Backup) *Backup {
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// BackupArchiveList is a list of BackupArchive resources
+type BackupArchiveList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []BackupArchive `json:"items"`
+}
+
+func NewBackupArchive(namespace, name string, obj BackupArchive) *BackupArchive {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("BackupArchive").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BackupReencryptList is a list of BackupReencrypt resources
+type BackupReencryptList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []BackupReencrypt `json:"items"`
+}
+
+func NewBackupReencrypt(namespace, name string, obj BackupReencrypt) *BackupReencrypt {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("BackupReencrypt").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // ResourceSetList is a list of ResourceSet resources
 type ResourceSetList struct {
 	metav1.TypeMeta `json:",inline"`
@@ -29,9 +29,11 @@ import (
 )
 
 var (
-	BackupResourceName      = "backups"
-	ResourceSetResourceName = "resourcesets"
-	RestoreResourceName     = "restores"
+	BackupResourceName          = "backups"
+	BackupArchiveResourceName   = "backuparchives"
+	BackupReencryptResourceName = "backupreencrypts"
+	ResourceSetResourceName     = "resourcesets"
+	RestoreResourceName         = "restores"
 )
 
 // SchemeGroupVersion is group version used to register these objects
@@ -57,6 +59,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&Backup{},
 		&BackupList{},
+		&BackupArchive{},
+		&BackupArchiveList{},
+		&BackupReencrypt{},
+		&BackupReencryptList{},
 		&ResourceSet{},
 		&ResourceSetList{},
 		&Restore{},
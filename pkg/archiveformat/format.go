@@ -0,0 +1,158 @@
+package archiveformat
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// Version1 is the layout <resource>.<group>#<version>/<name>.json for cluster scoped resources, and
+	// <resource>.<group>#<version>/<namespace>/<name>.json for namespaced ones. Archives without a format file have
+	// this layout.
+	Version1 = 1
+	// Version2 is the Version1 layout, except that names which can't be used as filenames are encoded, and indexed in
+	// the names file
+	Version2 = 2
+	// CurrentVersion is the version of the archives written by the backup controller
+	CurrentVersion = Version2
+
+	// JSONContentType is the content type of resource files holding the JSON of the resource, or a JSON string of its
+	// ciphertext when it is encrypted
+	JSONContentType = "application/json"
+
+	// FiltersPath is the path of the ResourceSet the backup was taken with
+	FiltersPath = "filters/filters.json"
+	// FormatPath is the path of the format file. It is the first file of the archive, and is stored in the filters
+	// directory which restores of older operators skip, so archives of a version they can read keep restoring with them.
+	FormatPath = "filters/format.json"
+	// NamesPath is the path of the index of the names of resources whose filename is encoded, it follows the format file
+	NamesPath = "filters/names.json"
+)
+
+// Format describes the layout and the content of an archive
+type Format struct {
+	Version     int    `json:"version"`
+	ContentType string `json:"contentType"`
+}
+
+// CurrentFormat returns the format of the archives written by the backup controller
+func CurrentFormat() Format {
+	return Format{Version: CurrentVersion, ContentType: JSONContentType}
+}
+
+// Resource identifies a resource file of an archive
+type Resource struct {
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Name      string
+}
+
+// AdditionalAuthenticatedData returns the data the resource is encrypted with: its name for cluster scoped resources,
+// namespace#name for namespaced ones
+func (r Resource) AdditionalAuthenticatedData() string {
+	if r.Namespace == "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s#%s", r.Namespace, r.Name)
+}
+
+// Layout maps resources to the paths of their files in an archive
+type Layout interface {
+	// Path returns the path of the file of the resource
+	Path(resource Resource) string
+	// Parse returns the resource stored at path, false for files that are not resources such as the filters
+	Parse(path string) (Resource, bool, error)
+}
+
+// LayoutFor returns the layout of archives of the format, or an error when this operator can't read them. names is the
+// names file of the archive, it is empty for versions without one.
+func LayoutFor(format Format, names Names) (Layout, error) {
+	if format.ContentType != "" && format.ContentType != JSONContentType {
+		return nil, fmt.Errorf("archive content type %v is not supported", format.ContentType)
+	}
+	switch format.Version {
+	case Version1:
+		return v1Layout{}, nil
+	case Version2:
+		return NewLayout(names), nil
+	default:
+		return nil, fmt.Errorf("archive format version %v is not supported, the highest supported version is %v", format.Version, CurrentVersion)
+	}
+}
+
+// NewLayout returns the layout of the archives written by the backup controller, names whose filename is encoded are
+// added to names when their path is computed
+func NewLayout(names Names) Layout {
+	return v2Layout{names: names}
+}
+
+// WriteFormat writes the format file, it must be the first file of the archive
+func WriteFormat(tw *tar.Writer, format Format) error {
+	data, err := json.Marshal(format)
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{
+		Name:     FormatPath,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+		Typeflag: tar.TypeReg,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("error writing header for %v: %v", FormatPath, err)
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+type v1Layout struct{}
+
+func (v1Layout) Path(resource Resource) string {
+	return path.Join(resourceDir(resource), resource.Name+".json")
+}
+
+func (v1Layout) Parse(filePath string) (Resource, bool, error) {
+	resource, filename, ok := parsePath(filePath)
+	if !ok {
+		return Resource{}, false, nil
+	}
+	resource.Name = strings.TrimSuffix(filename, ".json")
+	return resource, true, nil
+}
+
+// resourceDir returns the directory of the file of a resource: <resource>.<group>#<version>, followed by the namespace
+// of namespaced resources
+func resourceDir(resource Resource) string {
+	dir := fmt.Sprintf("%s.%s#%s", resource.GVR.Resource, resource.GVR.Group, resource.GVR.Version)
+	if resource.Namespace != "" {
+		dir = path.Join(dir, resource.Namespace)
+	}
+	return dir
+}
+
+// parsePath returns the resource stored at filePath, without its name, and the filename of the resource
+func parsePath(filePath string) (Resource, string, bool) {
+	// filePath = serviceaccounts.#v1/cattle-system/cattle.json OR users.management.cattle.io#v3/u-lqx8j.json
+	splitPath := strings.Split(filePath, "/")
+	if !strings.HasSuffix(filePath, ".json") || !strings.Contains(splitPath[0], "#") {
+		return Resource{}, "", false
+	}
+	gvkParts := strings.SplitN(splitPath[0], "#", 2)
+	resource, group, _ := strings.Cut(gvkParts[0], ".")
+	gvr := schema.GroupVersionResource{Group: group, Version: gvkParts[1], Resource: resource}
+	switch len(splitPath) {
+	case 2:
+		return Resource{GVR: gvr}, splitPath[1], true
+	case 3:
+		return Resource{GVR: gvr, Namespace: splitPath[1]}, splitPath[2], true
+	default:
+		return Resource{}, "", false
+	}
+}
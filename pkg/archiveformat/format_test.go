@@ -0,0 +1,175 @@
+package archiveformat
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestV1Layout(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		expected Resource
+		ok       bool
+	}{
+		{
+			name: "Namespaced core resource",
+			path: "serviceaccounts.#v1/cattle-system/cattle.json",
+			expected: Resource{
+				GVR:       schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"},
+				Namespace: "cattle-system",
+				Name:      "cattle",
+			},
+			ok: true,
+		},
+		{
+			name: "Cluster scoped resource of a group",
+			path: "users.management.cattle.io#v3/u-lqx8j.json",
+			expected: Resource{
+				GVR:  schema.GroupVersionResource{Group: "management.cattle.io", Version: "v3", Resource: "users"},
+				Name: "u-lqx8j",
+			},
+			ok: true,
+		},
+		{
+			name: "Filters",
+			path: FiltersPath,
+		},
+		{
+			name: "Format",
+			path: FormatPath,
+		},
+		{
+			name: "Resource directory",
+			path: "serviceaccounts.#v1/cattle-system",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			for _, layout := range []Layout{v1Layout{}, NewLayout(Names{})} {
+				resource, ok, err := layout.Parse(testCase.path)
+				require.NoError(t, err)
+				require.Equal(t, testCase.ok, ok)
+				assert.Equal(t, testCase.expected, resource)
+				if ok {
+					assert.Equal(t, testCase.path, layout.Path(resource))
+				}
+			}
+		})
+	}
+}
+
+func TestAdditionalAuthenticatedData(t *testing.T) {
+	assert.Equal(t, "u-lqx8j", Resource{Name: "u-lqx8j"}.AdditionalAuthenticatedData())
+	assert.Equal(t, "cattle-system#cattle", Resource{Namespace: "cattle-system", Name: "cattle"}.AdditionalAuthenticatedData())
+}
+
+type file struct {
+	path string
+	data string
+}
+
+func newTarball(t *testing.T, format *Format, files ...file) []byte {
+	var tarball bytes.Buffer
+	tw := tar.NewWriter(&tarball)
+	if format != nil {
+		require.NoError(t, WriteFormat(tw, *format))
+	}
+	for _, f := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: f.path, Mode: 0600, Size: int64(len(f.data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(f.data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return tarball.Bytes()
+}
+
+func readAll(t *testing.T, reader *Reader) []*Entry {
+	var entries []*Entry
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			return entries
+		}
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+}
+
+func TestReader(t *testing.T) {
+	current := CurrentFormat()
+	files := []file{
+		{path: "serviceaccounts.#v1/cattle-system/cattle.json", data: `{"kind":"ServiceAccount"}`},
+		{path: FiltersPath, data: `{"resourceSelectors":[]}`},
+	}
+
+	reader, err := NewReader(bytes.NewReader(newTarball(t, &current, files...)))
+	require.NoError(t, err)
+	assert.Equal(t, current, reader.Format())
+	entries := readAll(t, reader)
+	require.Len(t, entries, 3)
+	assert.Equal(t, FormatPath, entries[0].Header.Name)
+	assert.Nil(t, entries[0].Resource)
+	require.NotNil(t, entries[1].Resource)
+	assert.Equal(t, "cattle", entries[1].Resource.Name)
+	assert.Equal(t, `{"kind":"ServiceAccount"}`, string(entries[1].Data))
+	assert.Nil(t, entries[2].Resource)
+}
+
+func TestReaderWithNames(t *testing.T) {
+	current := CurrentFormat()
+	names := Names{}
+	resource := Resource{GVR: schema.GroupVersionResource{Group: "example.io", Version: "v1", Resource: "widgets"}, Namespace: "ns", Name: "a/b"}
+	encodedPath := NewLayout(names).Path(resource)
+	namesData, err := json.Marshal(names)
+	require.NoError(t, err)
+
+	reader, err := NewReader(bytes.NewReader(newTarball(t, &current, file{path: NamesPath, data: string(namesData)}, file{path: encodedPath, data: "{}"})))
+	require.NoError(t, err)
+	entries := readAll(t, reader)
+	require.Len(t, entries, 3)
+	assert.Equal(t, NamesPath, entries[1].Header.Name)
+	require.NotNil(t, entries[2].Resource)
+	assert.Equal(t, resource, *entries[2].Resource)
+	assert.Equal(t, "ns#a/b", entries[2].Resource.AdditionalAuthenticatedData())
+
+	// the names file is required to read encoded names
+	reader, err = NewReader(bytes.NewReader(newTarball(t, &current, file{path: encodedPath, data: "{}"})))
+	require.NoError(t, err)
+	_, err = reader.Next()
+	require.NoError(t, err)
+	_, err = reader.Next()
+	assert.Error(t, err)
+}
+
+func TestReaderWithoutFormat(t *testing.T) {
+	reader, err := NewReader(bytes.NewReader(newTarball(t, nil, file{path: "users.management.cattle.io#v3/u-lqx8j.json", data: "{}"})))
+	require.NoError(t, err)
+	assert.Equal(t, Version1, reader.Format().Version)
+	entries := readAll(t, reader)
+	require.Len(t, entries, 1)
+	require.NotNil(t, entries[0].Resource)
+	assert.Equal(t, "u-lqx8j", entries[0].Resource.Name)
+
+	reader, err = NewReader(bytes.NewReader(newTarball(t, nil)))
+	require.NoError(t, err)
+	assert.Empty(t, readAll(t, reader))
+}
+
+func TestReaderUnsupportedFormat(t *testing.T) {
+	for _, format := range []Format{
+		{Version: CurrentVersion + 1, ContentType: JSONContentType},
+		{Version: CurrentVersion, ContentType: "application/yaml"},
+	} {
+		_, err := NewReader(bytes.NewReader(newTarball(t, &format)))
+		assert.Error(t, err, "%+v", format)
+	}
+}
@@ -0,0 +1,63 @@
+package archiveformat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	// encodedNamePrefix starts the filenames of encoded names, names starting with it are encoded too so that they can't
+	// be mistaken for an encoded name
+	encodedNamePrefix = "~"
+	// maxFilenameLength is the maximum length of a filename on most file systems
+	maxFilenameLength = 255
+)
+
+// Names indexes the names of the resources whose filename is encoded by the path of their file
+type Names map[string]string
+
+// v2Layout stores resources whose name can't be used as a filename in a file named after the hash of the name
+type v2Layout struct {
+	names Names
+}
+
+func (l v2Layout) Path(resource Resource) string {
+	filename, encoded := filenameOf(resource.Name)
+	filePath := path.Join(resourceDir(resource), filename)
+	if encoded && l.names != nil {
+		l.names[filePath] = resource.Name
+	}
+	return filePath
+}
+
+func (l v2Layout) Parse(filePath string) (Resource, bool, error) {
+	resource, filename, ok := parsePath(filePath)
+	if !ok {
+		return Resource{}, false, nil
+	}
+	if !strings.HasPrefix(filename, encodedNamePrefix) {
+		resource.Name = strings.TrimSuffix(filename, ".json")
+		return resource, true, nil
+	}
+	name, ok := l.names[filePath]
+	if !ok {
+		return Resource{}, false, fmt.Errorf("name of resource file %v is missing from %v", filePath, NamesPath)
+	}
+	resource.Name = name
+	return resource, true, nil
+}
+
+// filenameOf returns the filename of a resource, and true when its name had to be encoded. Names holding path
+// separators, dot segments or that are too long for a filename are encoded.
+func filenameOf(name string) (string, bool) {
+	filename := name + ".json"
+	if name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\\\x00") &&
+		!strings.HasPrefix(name, encodedNamePrefix) && len(filename) <= maxFilenameLength {
+		return filename, false
+	}
+	hash := sha256.Sum256([]byte(name))
+	return encodedNamePrefix + hex.EncodeToString(hash[:]) + ".json", true
+}
@@ -0,0 +1,50 @@
+package archiveformat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestNamesRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name    string
+		encoded bool
+	}{
+		{name: "cattle"},
+		{name: "system:serviceaccount.with-dots"},
+		{name: "a/b", encoded: true},
+		{name: `a\b`, encoded: true},
+		{name: "..", encoded: true},
+		{name: "~cattle", encoded: true},
+		{name: strings.Repeat("a", 250)},
+		{name: strings.Repeat("a", 251), encoded: true},
+		{name: strings.Repeat("a", 253), encoded: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			names := Names{}
+			layout := NewLayout(names)
+			resource := Resource{GVR: schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, Namespace: "ns", Name: testCase.name}
+
+			filePath := layout.Path(resource)
+			filename := filePath[strings.LastIndex(filePath, "/")+1:]
+			assert.LessOrEqual(t, len(filename), maxFilenameLength)
+			assert.Equal(t, "configmaps.#v1/ns/"+filename, filePath)
+			if testCase.encoded {
+				assert.Equal(t, Names{filePath: testCase.name}, names)
+			} else {
+				assert.Empty(t, names)
+			}
+
+			parsed, ok, err := NewLayout(names).Parse(filePath)
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, resource, parsed)
+		})
+	}
+}
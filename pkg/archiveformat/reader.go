@@ -0,0 +1,119 @@
+package archiveformat
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Entry is a file or directory of an archive
+type Entry struct {
+	Header *tar.Header
+	// Data is the content of regular files
+	Data []byte
+	// Resource is set for resource files
+	Resource *Resource
+}
+
+// Reader reads the entries of an archive according to its format
+type Reader struct {
+	tarball *tar.Reader
+	format  Format
+	layout  Layout
+	// pending entries were read to find the format and the names of the archive, and are returned first by Next
+	pending []*Entry
+}
+
+// NewReader returns a reader of the uncompressed tarball r. The format is read from the format file at the start of
+// the archive, archives without one have the Version1 layout. The names file follows the format file in archives of
+// versions which have one.
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{
+		tarball: tar.NewReader(r),
+		format:  Format{Version: Version1, ContentType: JSONContentType},
+	}
+	names := Names{}
+	first, err := reader.readPending()
+	if err != nil {
+		return nil, err
+	}
+	if first != nil && first.Header.Name == FormatPath {
+		if err := json.Unmarshal(first.Data, &reader.format); err != nil {
+			return nil, fmt.Errorf("error unmarshaling archive format: %v", err)
+		}
+		if reader.format.Version >= Version2 {
+			second, err := reader.readPending()
+			if err != nil {
+				return nil, err
+			}
+			if second != nil && second.Header.Name == NamesPath {
+				if err := json.Unmarshal(second.Data, &names); err != nil {
+					return nil, fmt.Errorf("error unmarshaling archive names: %v", err)
+				}
+			}
+		}
+	}
+	if reader.layout, err = LayoutFor(reader.format, names); err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+// Format returns the format of the archive
+func (r *Reader) Format() Format {
+	return r.format
+}
+
+// Layout returns the layout of the archive
+func (r *Reader) Layout() Layout {
+	return r.layout
+}
+
+// Next returns the next entry of the archive, including the format and names files, and io.EOF at the end of the archive
+func (r *Reader) Next() (*Entry, error) {
+	var entry *Entry
+	if len(r.pending) > 0 {
+		entry, r.pending = r.pending[0], r.pending[1:]
+	} else {
+		var err error
+		if entry, err = r.read(); err != nil {
+			return nil, err
+		}
+	}
+	if entry.Header.Typeflag == tar.TypeReg {
+		resource, ok, err := r.layout.Parse(entry.Header.Name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			entry.Resource = &resource
+		}
+	}
+	return entry, nil
+}
+
+// readPending reads the next entry and queues it for Next, it returns nil at the end of the archive
+func (r *Reader) readPending() (*Entry, error) {
+	entry, err := r.read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.pending = append(r.pending, entry)
+	return entry, nil
+}
+
+func (r *Reader) read() (*Entry, error) {
+	hdr, err := r.tarball.Next()
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r.tarball)
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{Header: hdr, Data: data}, nil
+}
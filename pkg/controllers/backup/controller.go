@@ -12,10 +12,16 @@ import (
 	"time"
 
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/archiveformat"
 	backupControllers "github.com/rancher/backup-restore-operator/pkg/generated/controllers/resources.cattle.io/v1"
 	"github.com/rancher/backup-restore-operator/pkg/monitoring"
 	"github.com/rancher/backup-restore-operator/pkg/resourcesets"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/generate"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/include"
+	"github.com/rancher/backup-restore-operator/pkg/storage"
 	"github.com/rancher/backup-restore-operator/pkg/util"
+	"github.com/rancher/backup-restore-operator/pkg/util/archiveencryption"
+	"github.com/rancher/backup-restore-operator/pkg/util/compression"
 	"github.com/rancher/backup-restore-operator/pkg/util/encryptionconfig"
 	v1core "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
 	"github.com/rancher/wrangler/v3/pkg/genericcondition"
@@ -23,6 +29,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiextv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
 	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sEncryptionconfig "k8s.io/apiserver/pkg/server/options/encryptionconfig"
 	"k8s.io/client-go/discovery"
@@ -37,12 +44,17 @@ type handler struct {
 	secrets                 v1core.SecretController
 	namespaces              v1core.NamespaceController
 	discoveryClient         discovery.DiscoveryInterface
+	crds                    apiextv1client.CustomResourceDefinitionInterface
 	dynamicClient           dynamic.Interface
 	defaultBackupMountPath  string
 	defaultS3BackupLocation *v1.S3ObjectStore
 	kubeSystemNS            string
 	metricsServerEnabled    bool
 	encryptionProviderPath  string
+	encryptionPolicy        EncryptionPolicy
+	// triggerUserRecorded is true when the admission webhook records the user who triggers a snapshot, the annotation
+	// it records it in can be set by anyone otherwise
+	triggerUserRecorded bool
 }
 
 const (
@@ -61,7 +73,9 @@ func Register(
 	defaultLocalBackupLocation string,
 	defaultS3 *v1.S3ObjectStore,
 	metricsServerEnabled bool,
-	encryptionProviderPath string) {
+	encryptionProviderPath string,
+	encryptionPolicy EncryptionPolicy,
+	triggerUserRecorded bool) {
 
 	controller := &handler{
 		ctx:                     ctx,
@@ -70,11 +84,14 @@ func Register(
 		secrets:                 secrets,
 		namespaces:              namespaces,
 		discoveryClient:         clientSet.Discovery(),
+		crds:                    clientSet.ApiextensionsV1().CustomResourceDefinitions(),
 		dynamicClient:           dynamicInterface,
 		defaultBackupMountPath:  defaultLocalBackupLocation,
 		defaultS3BackupLocation: defaultS3,
 		metricsServerEnabled:    metricsServerEnabled,
 		encryptionProviderPath:  encryptionProviderPath,
+		encryptionPolicy:        encryptionPolicy,
+		triggerUserRecorded:     triggerUserRecorded,
 	}
 	if controller.defaultBackupMountPath != "" {
 		logrus.Infof("Default location for storing backups is %v", controller.defaultBackupMountPath)
@@ -97,12 +114,21 @@ func Register(
 func (h *handler) OnBackupChange(_ string, backup *v1.Backup) (*v1.Backup, error) {
 	var err error
 
-	if backup == nil || backup.DeletionTimestamp != nil {
+	if backup == nil {
 		return backup, nil
 	}
+	if backup.DeletionTimestamp != nil {
+		return h.onBackupRemove(backup)
+	}
+	if updated, changed, err := h.syncArchivesFinalizer(backup); err != nil || changed {
+		// an updated backup is processed again
+		return updated, err
+	}
+
+	triggerToken, triggered := pendingTrigger(backup)
 
-	// skips if the backup is singular and already processed
-	if backupIsSingularAndComplete(backup) {
+	// skips if the backup is singular and already processed, unless a new snapshot was triggered
+	if !triggered && backupIsSingularAndComplete(backup) {
 		logrus.Debugf("Backup %s has already been processed, skipping it", backup.Name)
 		return backup, nil
 	}
@@ -123,17 +149,28 @@ func (h *handler) OnBackupChange(_ string, backup *v1.Backup) (*v1.Backup, error
 		return h.setReconcilingCondition(backup, err)
 	}
 
-	if backup.Status.LastSnapshotTS != "" {
+	if backup.Spec.Suspend && !triggered {
+		logrus.Infof("Backup %v is suspended, skipping it", backup.Name)
+		if backup.Generation != backup.Status.ObservedGeneration {
+			backup.Status.ObservedGeneration = backup.Generation
+			return h.backups.UpdateStatus(backup)
+		}
+		return backup, nil
+	}
+
+	if triggered {
+		logrus.Infof("Snapshot of backup %v was triggered through the %v annotation", backup.Name, v1.TriggerSnapshotAnnotation)
+	} else if backup.Status.LastSnapshotTS != "" {
 		if backup.Status.NextSnapshotAt != "" {
 			currTime := time.Now().Format(time.RFC3339)
 			logrus.Infof("Next snapshot is scheduled for: %v, current time: %v", backup.Status.NextSnapshotAt, currTime)
 
-			nextSnapshotTime, err := time.Parse(time.RFC3339, backup.Status.NextSnapshotAt)
+			scheduledAt, err := time.Parse(time.RFC3339, backup.Status.NextSnapshotAt)
 			if err != nil {
 				return h.setReconcilingCondition(backup, err)
 			}
-			if nextSnapshotTime.After(time.Now()) {
-				after := nextSnapshotTime.Sub(time.Now())
+			if scheduledAt.After(time.Now()) {
+				after := scheduledAt.Sub(time.Now())
 				h.backups.EnqueueAfter(backup.Name, after)
 				if backup.Generation != backup.Status.ObservedGeneration {
 					backup.Status.ObservedGeneration = backup.Generation
@@ -142,11 +179,18 @@ func (h *handler) OnBackupChange(_ string, backup *v1.Backup) (*v1.Backup, error
 				return backup, nil
 			}
 
-			// proceed with backup only if current time is same as or after nextSnapshotTime
+			if backup.Status.BackupType == v1.RecurringBackupType && missedStartingDeadline(backup.Spec, scheduledAt, time.Now()) {
+				return h.skipMissedSnapshot(backup, scheduledAt)
+			}
+
+			// proceed with backup only if current time is same as or after scheduledAt
 			logrus.Infof("Processing recurring backup CR %v ", backup.Name)
 		}
 	}
 
+	runStart := time.Now()
+	snapshot := v1.BackupSnapshot{StartedAt: runStart.Format(time.RFC3339)}
+
 	if h.metricsServerEnabled {
 		backupStartTS := time.Now()
 		defer func() {
@@ -170,13 +214,14 @@ func (h *handler) OnBackupChange(_ string, backup *v1.Backup) (*v1.Backup, error
 	}
 	logrus.Infof("Temporary backup path for storing all contents for backup CR %v is %v", backup.Name, tmpBackupPath)
 
-	if err = h.performBackup(backup, tmpBackupPath, backupFileName); err != nil {
+	if err = h.performBackup(backup, tmpBackupPath, backupFileName, &snapshot); err != nil {
 		fmt.Println(err.Error())
+		snapshot.Duration = time.Since(runStart).Round(time.Second).String()
 		removeDirErr := os.RemoveAll(tmpBackupPath)
 		if removeDirErr != nil {
-			return h.setReconcilingCondition(backup, errors.New(err.Error()+removeDirErr.Error()))
+			return h.setSnapshotFailedCondition(backup, errors.New(err.Error()+removeDirErr.Error()), snapshot)
 		}
-		return h.setReconcilingCondition(backup, err)
+		return h.setSnapshotFailedCondition(backup, err, snapshot)
 	}
 
 	if err := os.RemoveAll(tmpBackupPath); err != nil {
@@ -184,16 +229,22 @@ func (h *handler) OnBackupChange(_ string, backup *v1.Backup) (*v1.Backup, error
 	}
 	// check for retention
 	var cronSchedule cron.Schedule
-	if backup.Spec.Schedule != "" {
-		if err := h.deleteBackupsFollowingRetentionPolicy(backup); err != nil {
+	var deletedArchives []string
+	if backup.Spec.Schedule != "" || backup.Spec.Retention != nil {
+		if deletedArchives, err = h.deleteBackupsFollowingRetentionPolicy(backup); err != nil {
 			return h.setReconcilingCondition(backup, err)
 		}
-		cronSchedule, err = cron.ParseStandard(backup.Spec.Schedule)
+	}
+	if backup.Spec.Schedule != "" {
+		cronSchedule, err = parseSchedule(backup.Spec)
 		if err != nil {
 			return h.setReconcilingCondition(backup, err)
 		}
 	}
 	storageLocationType := backup.Status.StorageLocation
+	snapshot.Duration = time.Since(runStart).Round(time.Second).String()
+	snapshot.StorageLocation = storageLocationType
+	snapshot.Result = v1.SnapshotSucceeded
 	updateErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		var err error
 		backup, err = h.backups.Get(backup.Name, k8sv1.GetOptions{})
@@ -208,18 +259,28 @@ func (h *handler) OnBackupChange(_ string, backup *v1.Backup) (*v1.Backup, error
 		v1.BackupConditionUploaded.SetStatusBool(backup, true)
 
 		backup.Status.LastSnapshotTS = time.Now().Format(time.RFC3339)
+		if triggered {
+			backup.Status.LastTrigger = &v1.BackupTrigger{
+				Token:        triggerToken,
+				FieldManager: triggerFieldManager(backup),
+				User:         h.triggerUser(backup),
+				TriggeredAt:  backup.Status.LastSnapshotTS,
+			}
+		}
 		if cronSchedule != nil {
-			nextBackupAt := cronSchedule.Next(time.Now())
+			nextBackupAt, parseErr := time.Parse(time.RFC3339, backup.Status.NextSnapshotAt)
+			// a triggered snapshot doesn't move the schedule if the next snapshot is still due
+			if !triggered || parseErr != nil || !nextBackupAt.After(time.Now()) {
+				nextBackupAt = nextSnapshotTime(cronSchedule, backup.Spec, runStart, time.Now())
+			}
 			backup.Status.NextSnapshotAt = nextBackupAt.Format(time.RFC3339)
 			after := nextBackupAt.Sub(time.Now())
 			h.backups.EnqueueAfter(backup.Name, after)
 		}
 		backup.Status.ObservedGeneration = backup.Generation
 		backup.Status.StorageLocation = storageLocationType
-		backup.Status.Filename = backupFileName + ".tar.gz"
-		if backup.Spec.EncryptionConfigSecretName != "" {
-			backup.Status.Filename += ".enc"
-		}
+		backup.Status.Filename = snapshot.Filename
+		addSnapshotToHistory(&backup.Status, snapshot, backup.Spec, deletedArchives)
 		_, err = h.backups.UpdateStatus(backup)
 		return err
 	})
@@ -231,7 +292,8 @@ func (h *handler) OnBackupChange(_ string, backup *v1.Backup) (*v1.Backup, error
 	return backup, err
 }
 
-func (h *handler) performBackup(backup *v1.Backup, tmpBackupPath, backupFileName string) error {
+// performBackup gathers, archives and stores the resources of the backup, recording the archive details in snapshot
+func (h *handler) performBackup(backup *v1.Backup, tmpBackupPath, backupFileName string, snapshot *v1.BackupSnapshot) error {
 	var err error
 
 	transformerMap := k8sEncryptionconfig.StaticTransformers{}
@@ -248,11 +310,50 @@ func (h *handler) performBackup(backup *v1.Backup, tmpBackupPath, backupFileName
 		}
 	}
 
+	var archiveKeys *archiveencryption.Keys
+	if backup.Spec.ArchiveEncryptionSecretName != "" {
+		logrus.Infof("Processing archive encryption secret %v for backup CR %v", backup.Spec.ArchiveEncryptionSecretName, backup.Name)
+		archiveEncryptionSecret, err := archiveencryption.GetArchiveEncryptionSecret(h.secrets, backup.Spec.ArchiveEncryptionSecretName)
+		if err != nil {
+			return err
+		}
+		archiveKeys, err = archiveencryption.GetKeysFromSecret(h.ctx, archiveEncryptionSecret)
+		if err != nil {
+			return err
+		}
+	}
+
 	logrus.Infof("Using resourceSet %v for gathering resources for backup CR %v", backup.Spec.ResourceSetName, backup.Name)
 	resourceSetTemplate, err := h.resourceSets.Get(backup.Spec.ResourceSetName, k8sv1.GetOptions{})
 	if err != nil {
 		return err
 	}
+	// the generated selectors and the selectors of included ResourceSets are merged in, and saved with the backup
+	source := generate.NewClusterSource(h.ctx, h.crds, h.secrets)
+	generated, err := generate.Resolve(resourceSetTemplate, source)
+	if err != nil {
+		return err
+	}
+	// the overlaps between selectors refer to them in the ResourceSet they come from, include.Resolve appends the
+	// selectors of each included ResourceSet right after getting it
+	references := selectorReferences(resourceSetTemplate, generated)
+	resourceSetTemplate, err = include.Resolve(generated, func(name string) (*v1.ResourceSet, error) {
+		included, err := h.resourceSets.Get(name, k8sv1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		generatedIncluded, err := generate.Resolve(included, source)
+		if err != nil {
+			return nil, err
+		}
+		references = append(references, selectorReferences(included, generatedIncluded)...)
+		return generatedIncluded, nil
+	})
+	if err != nil {
+		return err
+	}
+	// the status describes the cluster the ResourceSet was resolved against, it is not saved with the backup
+	resourceSetTemplate.Status = v1.ResourceSetStatus{}
 
 	logrus.Infof("Gathering resources for backup CR %v", backup.Name)
 	rh := resourcesets.ResourceHandler{
@@ -261,50 +362,76 @@ func (h *handler) performBackup(backup *v1.Backup, tmpBackupPath, backupFileName
 		TransformerMap:  transformerMap,
 		Ctx:             h.ctx,
 	}
-	err = rh.GatherResources(h.ctx, resourceSetTemplate.ResourceSelectors)
+	err = rh.GatherResources(h.ctx, resourceSetTemplate.ResourceSelectors, resourceSetTemplate.ExcludeSelectors)
 	if err != nil {
 		return err
 	}
+	for _, overlap := range rh.Overlaps {
+		snapshot.SelectorOverlaps = append(snapshot.SelectorOverlaps, v1.SelectorOverlap{
+			FirstSelector: references[overlap.FirstSelector],
+			Selector:      references[overlap.Selector],
+			APIVersion:    overlap.GVResource.GroupVersion.String(),
+			Resource:      overlap.GVResource.Name,
+			ObjectCount:   overlap.ObjectCount,
+		})
+	}
+	if h.encryptionPolicy.requiresEncryption(backup.Spec) {
+		if err := h.encryptionPolicy.checkEncryption(h.ctx, rh.GVResourceToObjects, transformerMap); err != nil {
+			return err
+		}
+	}
 
 	logrus.Infof("Finished gathering resources for backup CR %v, writing to temp location", backup.Name)
-	err = rh.WriteBackupObjects(tmpBackupPath)
+	objectCount, err := rh.WriteBackupObjects(tmpBackupPath)
 	if err != nil {
 		return err
 	}
+	snapshot.ObjectCount = int64(objectCount)
 
 	logrus.Infof("Saving resourceSet used for backup CR %v", backup.Name)
 	filters, err := json.Marshal(resourceSetTemplate)
 	if err != nil {
 		return err
 	}
-	filtersPath := filepath.Join(tmpBackupPath, "filters")
-	err = os.MkdirAll(filtersPath, os.ModePerm)
+	filtersPath := filepath.Join(tmpBackupPath, filepath.FromSlash(archiveformat.FiltersPath))
+	err = os.MkdirAll(filepath.Dir(filtersPath), os.ModePerm)
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(filepath.Join(filtersPath, "filters.json"), filters, os.ModePerm)
+	err = os.WriteFile(filtersPath, filters, os.ModePerm)
 	if err != nil {
 		return err
 	}
 
 	v1.BackupConditionReady.SetStatusBool(backup, true)
 
-	gzipFile := backupFileName + ".tar.gz"
+	gzipFile := backupFileName + compression.Extension(compression.Algorithm(backup.Spec.Compression))
 	if backup.Spec.EncryptionConfigSecretName != "" {
-		gzipFile += ".enc"
+		gzipFile += storage.EncryptedExtension
 	}
+	if archiveKeys != nil {
+		gzipFile += storage.SealedExtension
+	}
+	snapshot.Filename = gzipFile
 	storageLocation := backup.Spec.StorageLocation
 	if storageLocation == nil {
 		logrus.Infof("No storage location specified, checking for default PVC and S3")
 		// use the default location that the controller is configured with
 		if h.defaultBackupMountPath != "" {
-			if err := CreateTarAndGzip(tmpBackupPath, h.defaultBackupMountPath, gzipFile, backup.Name); err != nil {
+			if err := writeArchive(tmpBackupPath, h.defaultBackupMountPath, gzipFile, backup.Name, backup.Spec.Compression, archiveKeys); err != nil {
+				return err
+			}
+			archivePath := filepath.Join(h.defaultBackupMountPath, gzipFile)
+			fileInfo, err := os.Stat(archivePath)
+			if err != nil {
 				return err
 			}
+			snapshot.Size = fileInfo.Size()
+			snapshot.Location = archivePath
 			backup.Status.StorageLocation = util.PVBackup
 		} else if h.defaultS3BackupLocation != nil {
 			// not checking for nil, since if this wasn't provided, the default local location would get used
-			if err := h.uploadToS3(backup, h.defaultS3BackupLocation, tmpBackupPath, gzipFile); err != nil {
+			if err := h.uploadToS3(backup, h.defaultS3BackupLocation, tmpBackupPath, gzipFile, archiveKeys, snapshot); err != nil {
 				return err
 			}
 			backup.Status.StorageLocation = util.S3Backup
@@ -313,13 +440,27 @@ func (h *handler) performBackup(backup *v1.Backup, tmpBackupPath, backupFileName
 		}
 	} else if storageLocation.S3 != nil {
 		backup.Status.StorageLocation = util.S3Backup
-		if err := h.uploadToS3(backup, storageLocation.S3, tmpBackupPath, gzipFile); err != nil {
+		if err := h.uploadToS3(backup, storageLocation.S3, tmpBackupPath, gzipFile, archiveKeys, snapshot); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// selectorReferences returns the references of the selectors of a ResourceSet resolved with its generated selectors,
+// which follow its resourceSelectors
+func selectorReferences(resourceSet, generated *v1.ResourceSet) []v1.SelectorReference {
+	references := make([]v1.SelectorReference, 0, len(generated.ResourceSelectors))
+	for i := range generated.ResourceSelectors {
+		if i < len(resourceSet.ResourceSelectors) {
+			references = append(references, v1.SelectorReference{ResourceSet: resourceSet.Name, Position: i + 1})
+		} else {
+			references = append(references, v1.SelectorReference{ResourceSet: resourceSet.Name, Position: i - len(resourceSet.ResourceSelectors) + 1, Generated: true})
+		}
+	}
+	return references
+}
+
 func (h *handler) setBackupType(backup *v1.Backup) {
 	// Only checking if Schedule is set to determine the backup type, actual validation happens later in validateBackupSpec
 	if backup.Spec.Schedule == "" {
@@ -332,11 +473,10 @@ func (h *handler) setBackupType(backup *v1.Backup) {
 func (h *handler) validateBackupSpec(backup *v1.Backup) error {
 	logrus.Infof("backuptype set to: %v for %s", backup.Status.BackupType, backup.Name)
 
+	if err := ValidateBackupSpec(backup.Spec); err != nil {
+		return err
+	}
 	if backup.Status.BackupType == v1.RecurringBackupType {
-		_, err := cron.ParseStandard(backup.Spec.Schedule)
-		if err != nil {
-			return fmt.Errorf("error parsing invalid cron string for schedule: %v", err)
-		}
 		if backup.Spec.RetentionCount == 0 {
 			backup.Spec.RetentionCount = DefaultRetentionCountRecurring
 		}
@@ -348,6 +488,38 @@ func (h *handler) validateBackupSpec(backup *v1.Backup) error {
 	return nil
 }
 
+// ValidateBackupSpec returns an error when the schedule, retention or compression of a Backup is invalid, it is also
+// used by the admission webhook
+func ValidateBackupSpec(spec v1.BackupSpec) error {
+	if spec.Schedule != "" {
+		if _, err := parseSchedule(spec); err != nil {
+			return fmt.Errorf("error parsing invalid cron string for schedule: %v", err)
+		}
+	}
+	// retention applies to one-time backups too, a maxAge which isn't positive would delete every archive
+	if retention := spec.Retention; retention != nil && retention.MaxAge != nil && retention.MaxAge.Duration <= 0 {
+		return fmt.Errorf("invalid retention maxAge %v, it must be positive", retention.MaxAge.Duration)
+	}
+	return compression.Validate(spec.Compression)
+}
+
+// skipMissedSnapshot moves a recurring backup past a snapshot that could not be started within its starting deadline
+func (h *handler) skipMissedSnapshot(backup *v1.Backup, missedAt time.Time) (*v1.Backup, error) {
+	cronSchedule, err := parseSchedule(backup.Spec)
+	if err != nil {
+		return h.setReconcilingCondition(backup, err)
+	}
+	now := time.Now()
+	nextBackupAt := nextSnapshotTime(cronSchedule, backup.Spec, now, now)
+	logrus.Warnf("Snapshot of backup %v scheduled for %v missed its starting deadline of %vs, next snapshot is scheduled for %v",
+		backup.Name, missedAt.Format(time.RFC3339), backup.Spec.StartingDeadlineSeconds, nextBackupAt.Format(time.RFC3339))
+
+	backup.Status.NextSnapshotAt = nextBackupAt.Format(time.RFC3339)
+	backup.Status.ObservedGeneration = backup.Generation
+	h.backups.EnqueueAfter(backup.Name, nextBackupAt.Sub(now))
+	return h.backups.UpdateStatus(backup)
+}
+
 func (h *handler) generateBackupFilename(backup *v1.Backup) (string, error) {
 	currSnapshotTS := time.Now().Format(time.RFC3339)
 	// on OS X writing file with `:` converts colon to forward slash
@@ -359,6 +531,17 @@ func (h *handler) generateBackupFilename(backup *v1.Backup) (string, error) {
 // https://github.com/kubernetes-sigs/cli-utils/tree/master/pkg/kstatus
 // Reconciling and Stalled conditions are present and with a value of true whenever something unusual happens.
 func (h *handler) setReconcilingCondition(backup *v1.Backup, originalErr error) (*v1.Backup, error) {
+	return h.updateReconcilingCondition(backup, originalErr, nil)
+}
+
+// setSnapshotFailedCondition sets the reconciling condition like setReconcilingCondition, and records the failed snapshot in the backup's history
+func (h *handler) setSnapshotFailedCondition(backup *v1.Backup, originalErr error, snapshot v1.BackupSnapshot) (*v1.Backup, error) {
+	snapshot.Result = v1.SnapshotFailed
+	snapshot.Message = originalErr.Error()
+	return h.updateReconcilingCondition(backup, originalErr, &snapshot)
+}
+
+func (h *handler) updateReconcilingCondition(backup *v1.Backup, originalErr error, failedSnapshot *v1.BackupSnapshot) (*v1.Backup, error) {
 	if !v1.BackupConditionReconciling.IsUnknown(backup) && v1.BackupConditionReconciling.GetReason(backup) == "Error" {
 		reconcileMsg := v1.BackupConditionReconciling.GetMessage(backup)
 		if strings.Contains(reconcileMsg, originalErr.Error()) {
@@ -377,6 +560,9 @@ func (h *handler) setReconcilingCondition(backup *v1.Backup, originalErr error)
 		v1.BackupConditionReconciling.SetStatusBool(updBackup, true)
 		v1.BackupConditionReconciling.SetError(updBackup, "", originalErr)
 		v1.BackupConditionReady.Message(updBackup, "Retrying")
+		if failedSnapshot != nil {
+			addSnapshotToHistory(&updBackup.Status, *failedSnapshot, backup.Spec, nil)
+		}
 
 		_, err = h.backups.UpdateStatus(updBackup)
 		return err
@@ -3,6 +3,7 @@ package backup
 import (
 	"regexp"
 	"testing"
+	"time"
 
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
 	"github.com/stretchr/testify/assert"
@@ -235,6 +236,16 @@ func TestValidateBackupSpecOneTimeNonDefaultRetention(t *testing.T) {
 	require.Equal(t, backup.Spec.RetentionCount, int64(DefaultRetentionCountOneTime))
 }
 
+func TestValidateBackupSpecOneTimeInvalidMaxAge(t *testing.T) {
+	for _, maxAge := range []time.Duration{0, -time.Hour} {
+		spec := v1.BackupSpec{Retention: &v1.RetentionPolicy{MaxAge: &metav1.Duration{Duration: maxAge}}}
+		assert.ErrorContains(t, ValidateBackupSpec(spec), "maxAge", "maxAge %v", maxAge)
+	}
+
+	spec := v1.BackupSpec{Retention: &v1.RetentionPolicy{MaxAge: &metav1.Duration{Duration: time.Hour}}}
+	assert.NoError(t, ValidateBackupSpec(spec))
+}
+
 func TestBackupIsSingularAndComplete(t *testing.T) {
 	newInput := func(gen, obvgen int64, backupType v1.BackupType) *v1.Backup {
 		return &v1.Backup{
@@ -277,3 +288,107 @@ func TestBackupIsSingularAndComplete(t *testing.T) {
 		})
 	}
 }
+
+func TestPendingTrigger(t *testing.T) {
+	newInput := func(annotation string, lastTrigger *v1.BackupTrigger) *v1.Backup {
+		backup := &v1.Backup{
+			Status: v1.BackupStatus{
+				LastTrigger: lastTrigger,
+			},
+		}
+		if annotation != "" {
+			backup.SetAnnotations(map[string]string{v1.TriggerSnapshotAnnotation: annotation})
+		}
+		return backup
+	}
+
+	testCases := []struct {
+		name          string
+		input         *v1.Backup
+		expectedToken string
+		expected      bool
+	}{
+		{
+			name:     "No annotation",
+			input:    newInput("", nil),
+			expected: false,
+		},
+		{
+			name:          "First trigger",
+			input:         newInput("1700000000", nil),
+			expectedToken: "1700000000",
+			expected:      true,
+		},
+		{
+			name:     "Trigger already handled",
+			input:    newInput("1700000000", &v1.BackupTrigger{Token: "1700000000"}),
+			expected: false,
+		},
+		{
+			name:          "New trigger",
+			input:         newInput("1700000100", &v1.BackupTrigger{Token: "1700000000"}),
+			expectedToken: "1700000100",
+			expected:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			token, result := pendingTrigger(testCase.input)
+			assert.Equal(t, testCase.expected, result)
+			assert.Equal(t, testCase.expectedToken, token)
+		})
+	}
+}
+
+func TestTriggerFieldManager(t *testing.T) {
+	backup := &v1.Backup{}
+	backup.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{
+			Manager:  "rancher",
+			Time:     &metav1.Time{Time: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:schedule":{}}}`)},
+		},
+		{
+			Manager:  "kubectl-annotate",
+			Time:     &metav1.Time{Time: time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)},
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{".":{},"f:resources.cattle.io/trigger-snapshot":{}}}}`)},
+		},
+	})
+
+	assert.Equal(t, "kubectl-annotate", triggerFieldManager(backup))
+}
+
+func TestTriggerUser(t *testing.T) {
+	backup := &v1.Backup{}
+	backup.Annotations = map[string]string{v1.TriggerSnapshotAnnotation: "1", v1.TriggerSnapshotUserAnnotation: "alice"}
+
+	assert.Equal(t, "alice", (&handler{triggerUserRecorded: true}).triggerUser(backup))
+	assert.Empty(t, (&handler{}).triggerUser(backup), "the annotation can be set by anyone without the webhook")
+}
+
+func TestSelectorReferences(t *testing.T) {
+	resourceSet := &v1.ResourceSet{
+		ObjectMeta:        metav1.ObjectMeta{Name: "team"},
+		ResourceSelectors: []v1.ResourceSelector{{APIVersion: "v1"}, {APIVersion: "apps/v1"}},
+	}
+	generated := resourceSet.DeepCopy()
+	generated.ResourceSelectors = append(generated.ResourceSelectors, v1.ResourceSelector{APIVersion: "team.example.com/v1"})
+
+	assert.Equal(t, []v1.SelectorReference{
+		{ResourceSet: "team", Position: 1},
+		{ResourceSet: "team", Position: 2},
+	}, selectorReferences(resourceSet, resourceSet))
+	assert.Equal(t, []v1.SelectorReference{
+		{ResourceSet: "team", Position: 1},
+		{ResourceSet: "team", Position: 2},
+		{ResourceSet: "team", Position: 1, Generated: true},
+	}, selectorReferences(resourceSet, generated))
+}
+
+func TestRemoveFinalizer(t *testing.T) {
+	finalizers := []string{"other.io/finalizer", v1.BackupArchivesFinalizer}
+
+	assert.Equal(t, []string{"other.io/finalizer"}, removeFinalizer(finalizers))
+	assert.Empty(t, removeFinalizer([]string{v1.BackupArchivesFinalizer}))
+}
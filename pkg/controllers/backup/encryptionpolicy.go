@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sEncryptionconfig "k8s.io/apiserver/pkg/server/options/encryptionconfig"
+	"k8s.io/apiserver/pkg/storage/value"
+)
+
+// secretsResource is always sensitive
+var secretsResource = schema.GroupResource{Resource: "secrets"}
+
+// EncryptionPolicy refuses to store backups whose sensitive resources would not be encrypted
+type EncryptionPolicy struct {
+	// RequireEncryption applies to every Backup, which can require encryption but not opt out of it
+	RequireEncryption bool
+	// SensitiveResources must be encrypted in addition to secrets, in resource.group format, example tokens.management.cattle.io
+	SensitiveResources []string
+}
+
+// requiresEncryption returns true when the backup must encrypt its sensitive resources, the operator's policy is a
+// floor that a Backup can't lower
+func (p EncryptionPolicy) requiresEncryption(spec v1.BackupSpec) bool {
+	return p.RequireEncryption || (spec.RequireEncryption != nil && *spec.RequireEncryption)
+}
+
+func (p EncryptionPolicy) sensitiveResources() map[schema.GroupResource]bool {
+	sensitive := map[schema.GroupResource]bool{secretsResource: true}
+	for _, resource := range p.SensitiveResources {
+		if resource = strings.TrimSpace(resource); resource != "" {
+			sensitive[schema.ParseGroupResource(resource)] = true
+		}
+	}
+	return sensitive
+}
+
+// checkEncryption returns an error listing the gathered sensitive resources that would be stored unencrypted. Resources
+// not covered by the encryption config, or covered by the identity provider, are stored as they are.
+func (p EncryptionPolicy) checkEncryption(ctx context.Context, gathered map[resourcesets.GVResource][]unstructured.Unstructured,
+	transformers k8sEncryptionconfig.StaticTransformers) error {
+	sensitive := p.sensitiveResources()
+	unencrypted := make(map[string]bool)
+	for gvResource, objects := range gathered {
+		if len(objects) == 0 {
+			continue
+		}
+		gr := schema.ParseGroupResource(gvResource.Name + "." + gvResource.GroupVersion.Group)
+		if !sensitive[gr] {
+			continue
+		}
+		encrypted, err := isEncrypted(ctx, transformers, gr)
+		if err != nil {
+			return err
+		}
+		if !encrypted {
+			unencrypted[gr.String()] = true
+		}
+	}
+	if len(unencrypted) == 0 {
+		return nil
+	}
+	resources := make([]string, 0, len(unencrypted))
+	for resource := range unencrypted {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	return fmt.Errorf("encryption is required but the following sensitive resources would be stored unencrypted: %s; "+
+		"set an encryptionConfigSecretName whose config encrypts them with a non-identity provider", strings.Join(resources, ", "))
+}
+
+// isEncrypted returns true when the transformers encrypt the resource, identity providers return the data unchanged
+func isEncrypted(ctx context.Context, transformers k8sEncryptionconfig.StaticTransformers, gr schema.GroupResource) (bool, error) {
+	probe := []byte(`{"kind":"EncryptionProbe"}`)
+	out, err := transformers.TransformerForResource(gr).TransformToStorage(ctx, probe, value.DefaultContext("encryption-probe"))
+	if err != nil {
+		return false, fmt.Errorf("error checking the encryption of %v: %v", gr, err)
+	}
+	return !bytes.Equal(probe, out), nil
+}
@@ -0,0 +1,148 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets"
+	"github.com/rancher/backup-restore-operator/pkg/util/encryptionconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sEncryptionconfig "k8s.io/apiserver/pkg/server/options/encryptionconfig"
+)
+
+const (
+	secretboxEncryptionConfig = `apiVersion: apiserver.config.k8s.io/v1
+kind: EncryptionConfiguration
+resources:
+  - resources:
+      - "*.*"
+    providers:
+      - secretbox:
+          keys:
+            - name: key1
+              secret: YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXoxMjM0NTY=
+`
+	identityEncryptionConfig = `apiVersion: apiserver.config.k8s.io/v1
+kind: EncryptionConfiguration
+resources:
+  - resources:
+      - secrets
+    providers:
+      - identity: {}
+`
+)
+
+var (
+	secrets = resourcesets.GVResource{GroupVersion: schema.GroupVersion{Version: "v1"}, Name: "secrets", Namespaced: true}
+	tokens  = resourcesets.GVResource{GroupVersion: schema.GroupVersion{Group: "management.cattle.io", Version: "v3"}, Name: "tokens"}
+	users   = resourcesets.GVResource{GroupVersion: schema.GroupVersion{Group: "management.cattle.io", Version: "v3"}, Name: "users"}
+)
+
+func loadTransformers(t *testing.T, config string) k8sEncryptionconfig.StaticTransformers {
+	if config == "" {
+		return k8sEncryptionconfig.StaticTransformers{}
+	}
+	path := filepath.Join(t.TempDir(), encryptionconfig.EncryptionProviderConfigKey)
+	require.NoError(t, os.WriteFile(path, []byte(config), 0600))
+	transformers, err := encryptionconfig.PrepareEncryptionTransformersFromConfig(context.Background(), path)
+	require.NoError(t, err)
+	return transformers
+}
+
+func TestRequiresEncryption(t *testing.T) {
+	enabled, disabled := true, false
+	testCases := []struct {
+		name     string
+		policy   EncryptionPolicy
+		spec     v1.BackupSpec
+		expected bool
+	}{
+		{
+			name: "Not required",
+		},
+		{
+			name:     "Required by the operator",
+			policy:   EncryptionPolicy{RequireEncryption: true},
+			expected: true,
+		},
+		{
+			name:     "Required by the backup",
+			spec:     v1.BackupSpec{RequireEncryption: &enabled},
+			expected: true,
+		},
+		{
+			name:     "Backup can't opt out of the operator policy",
+			policy:   EncryptionPolicy{RequireEncryption: true},
+			spec:     v1.BackupSpec{RequireEncryption: &disabled},
+			expected: true,
+		},
+		{
+			name: "Backup doesn't require encryption",
+			spec: v1.BackupSpec{RequireEncryption: &disabled},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, testCase.policy.requiresEncryption(testCase.spec))
+		})
+	}
+}
+
+func TestCheckEncryption(t *testing.T) {
+	object := []unstructured.Unstructured{{}}
+	testCases := []struct {
+		name          string
+		policy        EncryptionPolicy
+		config        string
+		gathered      map[resourcesets.GVResource][]unstructured.Unstructured
+		expectedError string
+	}{
+		{
+			name:          "Secrets without an encryption config",
+			gathered:      map[resourcesets.GVResource][]unstructured.Unstructured{secrets: object, users: object},
+			expectedError: "encryption is required but the following sensitive resources would be stored unencrypted: secrets;",
+		},
+		{
+			name:     "Secrets encrypted",
+			config:   secretboxEncryptionConfig,
+			gathered: map[resourcesets.GVResource][]unstructured.Unstructured{secrets: object},
+		},
+		{
+			name:          "Secrets covered by the identity provider",
+			config:        identityEncryptionConfig,
+			gathered:      map[resourcesets.GVResource][]unstructured.Unstructured{secrets: object},
+			expectedError: "stored unencrypted: secrets;",
+		},
+		{
+			name:          "Configured sensitive resources",
+			policy:        EncryptionPolicy{SensitiveResources: []string{" tokens.management.cattle.io", ""}},
+			config:        identityEncryptionConfig,
+			gathered:      map[resourcesets.GVResource][]unstructured.Unstructured{secrets: object, tokens: object, users: object},
+			expectedError: "stored unencrypted: secrets, tokens.management.cattle.io;",
+		},
+		{
+			name:     "Sensitive resources without objects",
+			policy:   EncryptionPolicy{SensitiveResources: []string{"tokens.management.cattle.io"}},
+			gathered: map[resourcesets.GVResource][]unstructured.Unstructured{secrets: nil, tokens: {}, users: object},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.policy.checkEncryption(context.Background(), testCase.gathered, loadTransformers(t, testCase.config))
+			if testCase.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.expectedError)
+		})
+	}
+}
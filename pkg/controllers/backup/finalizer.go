@@ -0,0 +1,56 @@
+package backup
+
+import (
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/wrangler/v3/pkg/slice"
+	"github.com/sirupsen/logrus"
+)
+
+// syncArchivesFinalizer sets the archives finalizer on backups whose deletion policy is Delete, and removes it from the
+// others. It returns true when the backup was updated.
+func (h *handler) syncArchivesFinalizer(backup *v1.Backup) (*v1.Backup, bool, error) {
+	wantFinalizer := backup.Spec.DeletionPolicy == v1.DeleteArchives
+	if wantFinalizer == slice.ContainsString(backup.Finalizers, v1.BackupArchivesFinalizer) {
+		return backup, false, nil
+	}
+	backup = backup.DeepCopy()
+	if wantFinalizer {
+		backup.Finalizers = append(backup.Finalizers, v1.BackupArchivesFinalizer)
+	} else {
+		backup.Finalizers = removeFinalizer(backup.Finalizers)
+	}
+	updated, err := h.backups.Update(backup)
+	if err != nil {
+		return backup, false, err
+	}
+	return updated, true, nil
+}
+
+// onBackupRemove deletes the archives taken in this cluster by a backup whose deletion policy is Delete, before
+// removing its finalizer
+func (h *handler) onBackupRemove(backup *v1.Backup) (*v1.Backup, error) {
+	if !slice.ContainsString(backup.Finalizers, v1.BackupArchivesFinalizer) {
+		return backup, nil
+	}
+	if backup.Spec.DeletionPolicy == v1.DeleteArchives {
+		deleted, err := h.deleteAllArchives(backup)
+		if err != nil {
+			logrus.Errorf("Error deleting archives of deleted backup %v: %v", backup.Name, err)
+			return backup, err
+		}
+		logrus.Infof("Deleted %d archives of deleted backup %v", len(deleted), backup.Name)
+	}
+	backup = backup.DeepCopy()
+	backup.Finalizers = removeFinalizer(backup.Finalizers)
+	return h.backups.Update(backup)
+}
+
+func removeFinalizer(finalizers []string) []string {
+	var result []string
+	for _, finalizer := range finalizers {
+		if finalizer != v1.BackupArchivesFinalizer {
+			result = append(result, finalizer)
+		}
+	}
+	return result
+}
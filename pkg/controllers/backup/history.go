@@ -0,0 +1,40 @@
+package backup
+
+import (
+	"path"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+)
+
+// FailedSnapshotHistoryLimit is the number of failed snapshots kept in a backup's history
+const FailedSnapshotHistoryLimit = 3
+
+// addSnapshotToHistory records snapshot as the newest entry of the backup's history. Entries of archives deleted by the
+// retention policy are dropped, and at most FailedSnapshotHistoryLimit failed entries are kept. Successful entries are
+// bounded by the retentionCount of the backup, unless its retention policy decides which archives are kept, in which
+// case the history lists every archive it keeps.
+func addSnapshotToHistory(status *v1.BackupStatus, snapshot v1.BackupSnapshot, spec v1.BackupSpec, deletedArchives []string) {
+	deleted := make(map[string]bool)
+	for _, archive := range deletedArchives {
+		// archives in S3 are deleted by their object key, which includes the folder
+		deleted[path.Base(archive)] = true
+	}
+
+	var succeeded, failed int64
+	var history []v1.BackupSnapshot
+	for _, entry := range append([]v1.BackupSnapshot{snapshot}, status.History...) {
+		if entry.Result == v1.SnapshotFailed {
+			if failed >= FailedSnapshotHistoryLimit {
+				continue
+			}
+			failed++
+		} else {
+			if deleted[path.Base(entry.Filename)] || (spec.Retention == nil && succeeded >= spec.RetentionCount) {
+				continue
+			}
+			succeeded++
+		}
+		history = append(history, entry)
+	}
+	status.History = history
+}
@@ -0,0 +1,81 @@
+package backup
+
+import (
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddSnapshotToHistory(t *testing.T) {
+	succeeded := func(filename string) v1.BackupSnapshot {
+		return v1.BackupSnapshot{Filename: filename, Result: v1.SnapshotSucceeded}
+	}
+	failed := func(message string) v1.BackupSnapshot {
+		return v1.BackupSnapshot{Result: v1.SnapshotFailed, Message: message}
+	}
+
+	testCases := []struct {
+		name            string
+		history         []v1.BackupSnapshot
+		snapshot        v1.BackupSnapshot
+		retentionCount  int64
+		retention       *v1.RetentionPolicy
+		deletedArchives []string
+		expected        []v1.BackupSnapshot
+	}{
+		{
+			name:           "First snapshot",
+			snapshot:       succeeded("b-1.tar.gz"),
+			retentionCount: 3,
+			expected:       []v1.BackupSnapshot{succeeded("b-1.tar.gz")},
+		},
+		{
+			name:           "Newest snapshot first",
+			history:        []v1.BackupSnapshot{succeeded("b-1.tar.gz")},
+			snapshot:       succeeded("b-2.tar.gz"),
+			retentionCount: 3,
+			expected:       []v1.BackupSnapshot{succeeded("b-2.tar.gz"), succeeded("b-1.tar.gz")},
+		},
+		{
+			name:            "Snapshots deleted by retention are dropped",
+			history:         []v1.BackupSnapshot{succeeded("b-2.tar.gz"), succeeded("b-1.tar.gz")},
+			snapshot:        succeeded("b-3.tar.gz"),
+			retentionCount:  2,
+			deletedArchives: []string{"folder/b-1.tar.gz"},
+			expected:        []v1.BackupSnapshot{succeeded("b-3.tar.gz"), succeeded("b-2.tar.gz")},
+		},
+		{
+			name:           "Successful snapshots are bounded by the retention count",
+			history:        []v1.BackupSnapshot{succeeded("b-2.tar.gz"), succeeded("b-1.tar.gz")},
+			snapshot:       succeeded("b-3.tar.gz"),
+			retentionCount: 2,
+			expected:       []v1.BackupSnapshot{succeeded("b-3.tar.gz"), succeeded("b-2.tar.gz")},
+		},
+		{
+			name:            "Retention policy bounds snapshots by the archives it deletes only",
+			history:         []v1.BackupSnapshot{succeeded("b-3.tar.gz"), succeeded("b-2.tar.gz"), succeeded("b-1.tar.gz")},
+			snapshot:        succeeded("b-4.tar.gz"),
+			retentionCount:  2,
+			retention:       &v1.RetentionPolicy{KeepDaily: 7, KeepWeekly: 4},
+			deletedArchives: []string{"b-2.tar.gz"},
+			expected:        []v1.BackupSnapshot{succeeded("b-4.tar.gz"), succeeded("b-3.tar.gz"), succeeded("b-1.tar.gz")},
+		},
+		{
+			name:           "Failed snapshots are bounded separately",
+			history:        []v1.BackupSnapshot{failed("3"), succeeded("b-1.tar.gz"), failed("2"), failed("1")},
+			snapshot:       failed("4"),
+			retentionCount: 1,
+			expected:       []v1.BackupSnapshot{failed("4"), failed("3"), succeeded("b-1.tar.gz"), failed("2")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			status := &v1.BackupStatus{History: testCase.history}
+			spec := v1.BackupSpec{RetentionCount: testCase.retentionCount, Retention: testCase.retention}
+			addSnapshotToHistory(status, testCase.snapshot, spec, testCase.deletedArchives)
+			assert.Equal(t, testCase.expected, status.History)
+		})
+	}
+}
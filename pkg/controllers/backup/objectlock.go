@@ -0,0 +1,59 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/objectstore"
+)
+
+// objectLockOptions returns the object lock settings of an archive of the backup uploaded at uploadedAt
+func objectLockOptions(spec v1.BackupSpec, lock *v1.ObjectLock, uploadedAt time.Time) (objectstore.UploadOptions, error) {
+	if lock == nil {
+		return objectstore.UploadOptions{}, nil
+	}
+	options := objectstore.UploadOptions{LegalHold: lock.LegalHold}
+	if lock.Mode == "" {
+		return options, nil
+	}
+	retainFor, err := objectLockPeriod(spec, lock)
+	if err != nil {
+		return options, err
+	}
+	options.RetentionMode = minio.RetentionMode(lock.Mode)
+	options.RetainUntil = uploadedAt.Add(retainFor)
+	return options, nil
+}
+
+// objectLockPeriod returns how long an uploaded archive is locked: the object lock's retainFor, or how long the backup's
+// retention policy keeps the archive
+func objectLockPeriod(spec v1.BackupSpec, lock *v1.ObjectLock) (time.Duration, error) {
+	if lock.RetainFor != nil {
+		return lock.RetainFor.Duration, nil
+	}
+	if spec.Retention != nil {
+		if spec.Retention.MaxAge != nil {
+			return spec.Retention.MaxAge.Duration, nil
+		}
+		// the oldest archive a keep rule can keep, an archive dropped earlier is deleted once its lock expires
+		period := max(
+			time.Duration(spec.Retention.KeepHourly)*time.Hour,
+			time.Duration(spec.Retention.KeepDaily)*24*time.Hour,
+			time.Duration(spec.Retention.KeepWeekly)*7*24*time.Hour,
+			time.Duration(spec.Retention.KeepMonthly)*31*24*time.Hour,
+		)
+		if period > 0 {
+			return period, nil
+		}
+	} else if spec.Schedule != "" {
+		schedule, err := parseSchedule(spec)
+		if err != nil {
+			return 0, err
+		}
+		next := schedule.Next(time.Now())
+		return time.Duration(spec.RetentionCount) * schedule.Next(next).Sub(next), nil
+	}
+	return 0, fmt.Errorf("objectLock mode %v needs retainFor, as the backup's retention policy doesn't bound how long archives are kept", lock.Mode)
+}
@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestObjectLockPeriod(t *testing.T) {
+	testCases := []struct {
+		name     string
+		spec     v1.BackupSpec
+		lock     v1.ObjectLock
+		expected time.Duration
+		wantErr  bool
+	}{
+		{
+			name:     "Explicit retention",
+			spec:     v1.BackupSpec{Schedule: "@hourly", RetentionCount: 5},
+			lock:     v1.ObjectLock{Mode: "COMPLIANCE", RetainFor: &metav1.Duration{Duration: 48 * time.Hour}},
+			expected: 48 * time.Hour,
+		},
+		{
+			name:     "Retention count times the schedule interval",
+			spec:     v1.BackupSpec{Schedule: "@hourly", RetentionCount: 5},
+			lock:     v1.ObjectLock{Mode: "GOVERNANCE"},
+			expected: 5 * time.Hour,
+		},
+		{
+			name:     "Retention policy max age",
+			spec:     v1.BackupSpec{Schedule: "@hourly", Retention: &v1.RetentionPolicy{KeepDaily: 7, MaxAge: &metav1.Duration{Duration: 72 * time.Hour}}},
+			lock:     v1.ObjectLock{Mode: "GOVERNANCE"},
+			expected: 72 * time.Hour,
+		},
+		{
+			name:     "Longest keep rule of the retention policy",
+			spec:     v1.BackupSpec{Schedule: "@hourly", Retention: &v1.RetentionPolicy{KeepHourly: 24, KeepDaily: 7, KeepWeekly: 2}},
+			lock:     v1.ObjectLock{Mode: "GOVERNANCE"},
+			expected: 14 * 24 * time.Hour,
+		},
+		{
+			name:    "One-time backup without retention",
+			spec:    v1.BackupSpec{RetentionCount: 1},
+			lock:    v1.ObjectLock{Mode: "COMPLIANCE"},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			period, err := objectLockPeriod(testCase.spec, &testCase.lock)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, period)
+		})
+	}
+}
+
+func TestObjectLockOptions(t *testing.T) {
+	uploadedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	spec := v1.BackupSpec{Schedule: "@daily", RetentionCount: 3}
+
+	options, err := objectLockOptions(spec, nil, uploadedAt)
+	require.NoError(t, err)
+	assert.Empty(t, options.RetentionMode)
+
+	options, err = objectLockOptions(spec, &v1.ObjectLock{LegalHold: true}, uploadedAt)
+	require.NoError(t, err)
+	assert.True(t, options.LegalHold)
+	assert.Empty(t, options.RetentionMode, "the bucket's default retention applies without mode")
+
+	options, err = objectLockOptions(spec, &v1.ObjectLock{Mode: "COMPLIANCE"}, uploadedAt)
+	require.NoError(t, err)
+	assert.Equal(t, minio.Compliance, options.RetentionMode)
+	assert.Equal(t, uploadedAt.Add(72*time.Hour), options.RetainUntil)
+}
@@ -1,157 +1,183 @@
 package backup
 
 import (
-	"context"
 	"fmt"
 	"os"
-	"path/filepath"
-	"regexp"
-	"sort"
-	"strings"
 	"time"
 
 	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/s3utils"
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
 	"github.com/rancher/backup-restore-operator/pkg/objectstore"
+	"github.com/rancher/backup-restore-operator/pkg/storage"
 	"github.com/sirupsen/logrus"
 )
 
-type backupInfo struct {
-	filename          string
-	creationTimestamp time.Time
+// deleteBackupsFollowingRetentionPolicy deletes the backup's archives not kept by its retention policy and returns the names of the deleted archives
+func (h *handler) deleteBackupsFollowingRetentionPolicy(backup *v1.Backup) ([]string, error) {
+	encrypted := backup.Spec.EncryptionConfigSecretName != ""
+	sealed := backup.Spec.ArchiveEncryptionSecretName != ""
+	return h.deleteArchives(backup, func(archives []storage.Archive) []storage.Archive {
+		// archives taken before the encryption setting changed are left alone
+		var current []storage.Archive
+		for _, archive := range archives {
+			if archive.Encrypted == encrypted && archive.Sealed == sealed {
+				current = append(current, archive)
+			}
+		}
+		return archivesToDelete(current, backup.Spec, time.Now())
+	})
 }
 
-func (h *handler) deleteBackupsFollowingRetentionPolicy(backup *v1.Backup) error {
-	retentionCount := int(backup.Spec.RetentionCount)
+// deleteAllArchives deletes all archives taken by the backup in this cluster and returns their names
+func (h *handler) deleteAllArchives(backup *v1.Backup) ([]string, error) {
+	return h.deleteArchives(backup, func(archives []storage.Archive) []storage.Archive {
+		return archives
+	})
+}
+
+// deleteArchives lists the archives taken by the backup in this cluster, newest first, and deletes the ones returned by selectArchives
+func (h *handler) deleteArchives(backup *v1.Backup, selectArchives func([]storage.Archive) []storage.Archive) ([]string, error) {
 	if backup.Spec.StorageLocation == nil {
 		if h.defaultBackupMountPath != "" {
-			return h.deleteBackupsFromMountPath(retentionCount, h.defaultBackupMountPath, backup.Name, backup.Spec.EncryptionConfigSecretName != "")
+			return h.deleteBackupsFromMountPath(backup, h.defaultBackupMountPath, selectArchives)
 		} else if h.defaultS3BackupLocation != nil {
 			// not checking for nil, since if this wasn't provided, the default local location would get used
 			s3Client, err := objectstore.GetS3Client(h.ctx, h.defaultS3BackupLocation, h.dynamicClient)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			return h.deleteS3Backups(backup, h.defaultS3BackupLocation, s3Client, retentionCount, backup.Spec.EncryptionConfigSecretName != "")
+			return h.deleteS3Backups(backup, h.defaultS3BackupLocation, s3Client, selectArchives)
 		}
 	} else if backup.Spec.StorageLocation.S3 != nil {
 		s3Client, err := objectstore.GetS3Client(h.ctx, backup.Spec.StorageLocation.S3, h.dynamicClient)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		return h.deleteS3Backups(backup, backup.Spec.StorageLocation.S3, s3Client, retentionCount, backup.Spec.EncryptionConfigSecretName != "")
+		return h.deleteS3Backups(backup, backup.Spec.StorageLocation.S3, s3Client, selectArchives)
 	}
-	return nil
+	return nil, nil
 }
 
-func (h *handler) deleteBackupsFromMountPath(retentionCount int, backupLocation, name string, encrypted bool) error {
-	var fileMatchPattern string
-	if encrypted {
-		fileMatchPattern = filepath.Join(backupLocation, fmt.Sprintf("%s-%s*.tar.gz.enc", name, h.kubeSystemNS))
-	} else {
-		fileMatchPattern = filepath.Join(backupLocation, fmt.Sprintf("%s-%s*.tar.gz", name, h.kubeSystemNS))
-	}
-	logrus.Infof("Finding files starting with %v", fileMatchPattern)
-	fileMatches, err := filepath.Glob(fileMatchPattern)
+func (h *handler) deleteBackupsFromMountPath(backup *v1.Backup, backupLocation string, selectArchives func([]storage.Archive) []storage.Archive) ([]string, error) {
+	logrus.Infof("Finding archives of backup %v in %v", backup.Name, backupLocation)
+	archives, err := storage.ListMountPath(backupLocation)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if len(fileMatches) <= retentionCount {
-		return nil
+	var deleted []string
+	for _, archive := range selectArchives(h.ownArchives(backup, archives)) {
+		logrus.Infof("File %v was taken at %v, deleting it following backup %v's policy", archive.Filename, archive.Timestamp, backup.Name)
+		if err := os.Remove(archive.Key); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, archive.Filename)
 	}
-	var backupFiles []backupInfo
-	for _, file := range fileMatches {
-		fileInfo, err := os.Stat(file)
-		if err != nil {
-			logrus.Errorf("Error getting file information for %v: %v", file, err)
+	return deleted, nil
+}
+
+func (h *handler) deleteS3Backups(backup *v1.Backup, s3 *v1.S3ObjectStore, svc *minio.Client, selectArchives func([]storage.Archive) []storage.Archive) ([]string, error) {
+	archives, err := storage.ListS3(h.ctx, svc, s3)
+	if err != nil {
+		logrus.Error("error to fetch s3 file:", err)
+		return nil, err
+	}
+	var deleted []string
+	for _, archive := range selectArchives(h.ownArchives(backup, archives)) {
+		// locked backup files are deleted by a later run, once their lock expired
+		if s3.ObjectLock != nil && objectstore.IsObjectLocked(h.ctx, svc, s3.BucketName, archive.Key) {
+			logrus.Infof("Skipping deletion of locked s3 backup file [%s]", archive.Key)
 			continue
 		}
-		b := backupInfo{
-			filename:          fileInfo.Name(),
-			creationTimestamp: fileInfo.ModTime(),
+		logrus.Infof("Deleting s3 backup file [%s] taken at %v following backup %v's policy", archive.Key, archive.Timestamp, backup.Name)
+		err := svc.RemoveObject(h.ctx, s3.BucketName, archive.Key, minio.RemoveObjectOptions{})
+		if err != nil {
+			// the bucket can lock objects by default without objectLock being configured
+			if objectstore.IsObjectLocked(h.ctx, svc, s3.BucketName, archive.Key) {
+				logrus.Infof("Skipping deletion of locked s3 backup file [%s]: %v", archive.Key, err)
+				continue
+			}
+			logrus.Errorf("Error detected during deletion: %v", err)
+			return deleted, err
 		}
-		backupFiles = append(backupFiles, b)
+		logrus.Infof("Success delete s3 backup file [%s]", archive.Key)
+		deleted = append(deleted, archive.Key)
 	}
-	sort.Slice(backupFiles, func(i, j int) bool {
-		return !backupFiles[i].creationTimestamp.Before(backupFiles[j].creationTimestamp)
-	})
-	for _, file := range backupFiles[retentionCount:] {
-		logrus.Infof("File %v was created at %v, deleting it to follow backup's policy of retaining %v backups", file.filename, file.creationTimestamp, retentionCount)
-		if err := os.Remove(filepath.Join(backupLocation, file.filename)); err != nil {
-			return err
+	return deleted, nil
+}
+
+// ownArchives returns the archives taken by the backup in this cluster
+func (h *handler) ownArchives(backup *v1.Backup, archives []storage.Archive) []storage.Archive {
+	var own []storage.Archive
+	for _, archive := range archives {
+		if archive.BackupName == backup.Name && archive.ClusterUID == h.kubeSystemNS {
+			own = append(own, archive)
 		}
 	}
-	return nil
+	return own
 }
 
-func (h *handler) deleteS3Backups(backup *v1.Backup, s3 *v1.S3ObjectStore, svc *minio.Client, retentionCount int, encrypted bool) error {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	prefix := ""
-	isRecursive := false
-	if len(s3.Folder) != 0 {
-		prefix = s3.Folder
-		// Recurse will show us the files in the folder
-		isRecursive = true
+// archivesToDelete returns the archives, sorted newest first, that are not kept by the backup's retention policy.
+// Without a retention policy, the newest retentionCount archives are kept.
+func archivesToDelete(archives []storage.Archive, spec v1.BackupSpec, now time.Time) []storage.Archive {
+	policy := spec.Retention
+	if policy == nil {
+		if len(archives) <= int(spec.RetentionCount) {
+			return nil
+		}
+		return archives[spec.RetentionCount:]
 	}
-	opts := minio.ListObjectsOptions{
-		Prefix:    prefix,
-		Recursive: isRecursive,
+
+	// periods are evaluated in the time zone of the schedule, so that days and months start at its midnight
+	loc := time.Local
+	if spec.TimeZone != "" {
+		if tz, err := time.LoadLocation(spec.TimeZone); err == nil {
+			loc = tz
+		}
 	}
-	if s3utils.IsGoogleEndpoint(*svc.EndpointURL()) {
-		logrus.Info("Endpoint is Google GCS")
-		opts.UseV1 = true
+	rules := []struct {
+		count  int
+		period func(t time.Time) string
+	}{
+		{policy.KeepHourly, func(t time.Time) string { return t.Format("2006-01-02T15") }},
+		{policy.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{policy.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%d", year, week)
+		}},
+		{policy.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") }},
 	}
 
-	objectCh := svc.ListObjects(ctx, s3.BucketName, opts)
-	// default-backup-([a-z0-9-]).*([tar]).gz
-	// default-test-ecm-backup-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-([0-9-#]).*tar.gz$ OR
-	// default-test-ecm-backup-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-([0-9-#]).*tar.gz.enc$
-	var re *regexp.Regexp
-	if encrypted {
-		re = regexp.MustCompile(fmt.Sprintf("%s-%s-([0-9-#]).*tar.gz.enc$", backup.Name, h.kubeSystemNS))
-	} else {
-		re = regexp.MustCompile(fmt.Sprintf("%s-%s-([0-9-#]).*tar.gz$", backup.Name, h.kubeSystemNS))
-	}
-	var backupFiles []backupInfo
-	for object := range objectCh {
-		if object.Err != nil {
-			logrus.Error("error to fetch s3 file:", object.Err)
-			return object.Err
+	keep := make([]bool, len(archives))
+	hasKeepRule := false
+	for _, rule := range rules {
+		if rule.count == 0 {
+			continue
 		}
-		// only parse backup file names that matches backup format
-		if re.MatchString(object.Key) {
-			filename := object.Key
-
-			if len(s3.Folder) != 0 {
-				// example object.Key with folder: folder/timestamp_etcd.zip
-				// folder and separator needs to be stripped so time can be parsed below
-				logrus.Debugf("Stripping [%s] from [%s]", fmt.Sprintf("%s/", prefix), filename)
-				filename = strings.TrimPrefix(filename, fmt.Sprintf("%s/", prefix))
+		hasKeepRule = true
+		// keep the newest archive of each of the rule's most recent periods that have an archive
+		periods := make(map[string]bool)
+		for i, archive := range archives {
+			period := rule.period(archive.Timestamp.In(loc))
+			if periods[period] {
+				continue
 			}
-			b := backupInfo{
-				filename:          object.Key,
-				creationTimestamp: object.LastModified,
+			if len(periods) == rule.count {
+				break
 			}
-			backupFiles = append(backupFiles, b)
+			periods[period] = true
+			keep[i] = true
 		}
 	}
-	if len(backupFiles) <= retentionCount {
-		return nil
-	}
-	sort.Slice(backupFiles, func(i, j int) bool {
-		return !backupFiles[i].creationTimestamp.Before(backupFiles[j].creationTimestamp)
-	})
-	for _, backupFile := range backupFiles[retentionCount:] {
-		logrus.Infof("Deleting s3 backup file [%s] to follow retention policy of max %v backups", backupFile.filename, retentionCount)
-		err := svc.RemoveObject(context.Background(), s3.BucketName, backupFile.filename, minio.RemoveObjectOptions{})
-		if err != nil {
-			logrus.Errorf("Error detected during deletion: %v", err)
-			return err
+
+	var toDelete []storage.Archive
+	for i, archive := range archives {
+		if i < policy.MinKeep {
+			continue
+		}
+		expired := policy.MaxAge != nil && now.Sub(archive.Timestamp) > policy.MaxAge.Duration
+		if expired || (hasKeepRule && !keep[i]) {
+			toDelete = append(toDelete, archive)
 		}
-		logrus.Infof("Success delete s3 backup file [%s]", backupFile.filename)
 	}
-	return nil
+	return toDelete
 }
@@ -0,0 +1,99 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestArchivesToDelete(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	// archives taken every 6 hours over the last 40 days, newest first
+	var archives []storage.Archive
+	for ts := now; ts.After(now.Add(-40 * 24 * time.Hour)); ts = ts.Add(-6 * time.Hour) {
+		archives = append(archives, storage.Archive{
+			ArchiveName: storage.ArchiveName{Timestamp: ts},
+			Filename:    ts.Format(time.RFC3339),
+		})
+	}
+	day := func(d int) string {
+		return time.Date(2024, 2, d, 18, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	}
+
+	testCases := []struct {
+		name     string
+		spec     v1.BackupSpec
+		expected []string
+	}{
+		{
+			name:     "Retention count",
+			spec:     v1.BackupSpec{RetentionCount: 3},
+			expected: []string{archives[0].Filename, archives[1].Filename, archives[2].Filename},
+		},
+		{
+			name: "Daily",
+			spec: v1.BackupSpec{RetentionCount: 1, TimeZone: "UTC", Retention: &v1.RetentionPolicy{KeepDaily: 3}},
+			// the newest archive of today, yesterday and the day before
+			expected: []string{archives[0].Filename, day(29), day(28)},
+		},
+		{
+			name: "Grandfather-father-son",
+			spec: v1.BackupSpec{TimeZone: "UTC", Retention: &v1.RetentionPolicy{KeepHourly: 2, KeepDaily: 2, KeepWeekly: 2, KeepMonthly: 2}},
+			// hourly: Mar 1 12:00 and 06:00, daily: Mar 1 and Feb 29, weekly: the week of Feb 26 and the one of Feb 19 (Sunday the 25th),
+			// monthly: March and February
+			expected: []string{archives[0].Filename, archives[1].Filename, day(29), day(25)},
+		},
+		{
+			name:     "Max age",
+			spec:     v1.BackupSpec{Retention: &v1.RetentionPolicy{MaxAge: &metav1.Duration{Duration: 13 * time.Hour}}},
+			expected: []string{archives[0].Filename, archives[1].Filename, archives[2].Filename},
+		},
+		{
+			name:     "Max age overrides keep rules",
+			spec:     v1.BackupSpec{TimeZone: "UTC", Retention: &v1.RetentionPolicy{KeepDaily: 7, MaxAge: &metav1.Duration{Duration: 36 * time.Hour}}},
+			expected: []string{archives[0].Filename, day(29)},
+		},
+		{
+			name:     "Minimum keep",
+			spec:     v1.BackupSpec{Retention: &v1.RetentionPolicy{MaxAge: &metav1.Duration{Duration: time.Hour}, MinKeep: 2}},
+			expected: []string{archives[0].Filename, archives[1].Filename},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			toDelete := archivesToDelete(archives, testCase.spec, now)
+			deleted := make(map[string]bool)
+			for _, archive := range toDelete {
+				deleted[archive.Filename] = true
+			}
+			var kept []string
+			for _, archive := range archives {
+				if !deleted[archive.Filename] {
+					kept = append(kept, archive.Filename)
+				}
+			}
+			assert.Equal(t, testCase.expected, kept)
+		})
+	}
+}
+
+func TestOwnArchives(t *testing.T) {
+	h := handler{kubeSystemNS: "24e1b8ce-1f00-4bbe-94bb-248ad7606dc8"}
+	backup := &v1.Backup{}
+	backup.SetName("nightly")
+	archives := []storage.Archive{
+		{ArchiveName: storage.ArchiveName{BackupName: "nightly", ClusterUID: h.kubeSystemNS}, Filename: "own"},
+		{ArchiveName: storage.ArchiveName{BackupName: "nightly-test", ClusterUID: h.kubeSystemNS}, Filename: "other backup"},
+		{ArchiveName: storage.ArchiveName{BackupName: "nightly", ClusterUID: "other-cluster"}, Filename: "other cluster"},
+		{ArchiveName: storage.ArchiveName{BackupName: "nightly", ClusterUID: h.kubeSystemNS, Encrypted: true}, Filename: "encrypted"},
+	}
+
+	own := h.ownArchives(backup, archives)
+
+	assert.Equal(t, []storage.Archive{archives[0], archives[3]}, own)
+}
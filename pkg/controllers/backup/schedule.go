@@ -0,0 +1,48 @@
+package backup
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/robfig/cron/v3"
+)
+
+// parseSchedule parses the cron schedule of a backup, evaluating it in spec.TimeZone when one is set
+func parseSchedule(spec v1.BackupSpec) (cron.Schedule, error) {
+	schedule := spec.Schedule
+	if spec.TimeZone != "" {
+		if strings.HasPrefix(schedule, "CRON_TZ=") || strings.HasPrefix(schedule, "TZ=") {
+			return nil, fmt.Errorf("time zone must be set either in timeZone or in the schedule, not both")
+		}
+		if _, err := time.LoadLocation(spec.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %v: %v", spec.TimeZone, err)
+		}
+		schedule = fmt.Sprintf("CRON_TZ=%s %s", spec.TimeZone, schedule)
+	}
+	return cron.ParseStandard(schedule)
+}
+
+// nextSnapshotTime returns the time of the next snapshot following a run that started at runStart and ended at runEnd,
+// honoring the concurrency policy and adding a random jitter of up to spec.JitterSeconds
+func nextSnapshotTime(schedule cron.Schedule, spec v1.BackupSpec, runStart, runEnd time.Time) time.Time {
+	from := runEnd
+	if spec.ConcurrencyPolicy == v1.AllowConcurrent {
+		from = runStart
+	}
+	next := schedule.Next(from)
+	if spec.JitterSeconds > 0 {
+		next = next.Add(time.Duration(rand.Int63n(spec.JitterSeconds)) * time.Second)
+	}
+	return next
+}
+
+// missedStartingDeadline checks if a snapshot scheduled at scheduledAt can no longer be started at now
+func missedStartingDeadline(spec v1.BackupSpec, scheduledAt, now time.Time) bool {
+	if spec.StartingDeadlineSeconds <= 0 {
+		return false
+	}
+	return now.Sub(scheduledAt) > time.Duration(spec.StartingDeadlineSeconds)*time.Second
+}
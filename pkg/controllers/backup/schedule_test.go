@@ -0,0 +1,106 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheduleTimeZone(t *testing.T) {
+	spec := v1.BackupSpec{
+		Schedule: "0 2 * * *",
+		TimeZone: "America/New_York",
+	}
+
+	schedule, err := parseSchedule(spec)
+	require.NoError(t, err)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	next := schedule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2024, 1, 1, 2, 0, 0, 0, loc).UTC(), next.UTC())
+}
+
+func TestParseScheduleInvalidTimeZone(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec v1.BackupSpec
+	}{
+		{
+			name: "unknown time zone",
+			spec: v1.BackupSpec{Schedule: "@midnight", TimeZone: "Mars/Olympus_Mons"},
+		},
+		{
+			name: "time zone set twice",
+			spec: v1.BackupSpec{Schedule: "CRON_TZ=UTC @midnight", TimeZone: "Europe/Berlin"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := parseSchedule(testCase.spec)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNextSnapshotTimeConcurrencyPolicy(t *testing.T) {
+	runStart := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	// the snapshot took longer than the 15 minute interval of the schedule
+	runEnd := runStart.Add(20 * time.Minute)
+
+	testCases := []struct {
+		name     string
+		policy   v1.ConcurrencyPolicy
+		expected time.Time
+	}{
+		{
+			name:     "default skips the slot missed while running",
+			expected: runStart.Add(30 * time.Minute),
+		},
+		{
+			name:     "forbid skips the slot missed while running",
+			policy:   v1.ForbidConcurrent,
+			expected: runStart.Add(30 * time.Minute),
+		},
+		{
+			name:     "allow takes the slot missed while running",
+			policy:   v1.AllowConcurrent,
+			expected: runStart.Add(15 * time.Minute),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			spec := v1.BackupSpec{Schedule: "*/15 * * * *", TimeZone: "UTC", ConcurrencyPolicy: testCase.policy}
+			schedule, err := parseSchedule(spec)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, nextSnapshotTime(schedule, spec, runStart, runEnd).UTC())
+		})
+	}
+}
+
+func TestNextSnapshotTimeJitter(t *testing.T) {
+	spec := v1.BackupSpec{Schedule: "@hourly", TimeZone: "UTC", JitterSeconds: 300}
+	schedule, err := parseSchedule(spec)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	scheduled := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		next := nextSnapshotTime(schedule, spec, now, now)
+		assert.False(t, next.Before(scheduled), "jitter must not move the snapshot before its scheduled time")
+		assert.True(t, next.Before(scheduled.Add(300*time.Second)), "jitter must stay below jitterSeconds")
+	}
+}
+
+func TestMissedStartingDeadline(t *testing.T) {
+	scheduledAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.False(t, missedStartingDeadline(v1.BackupSpec{}, scheduledAt, scheduledAt.Add(24*time.Hour)), "missed snapshots are taken without a deadline")
+	assert.False(t, missedStartingDeadline(v1.BackupSpec{StartingDeadlineSeconds: 600}, scheduledAt, scheduledAt.Add(5*time.Minute)))
+	assert.True(t, missedStartingDeadline(v1.BackupSpec{StartingDeadlineSeconds: 600}, scheduledAt, scheduledAt.Add(11*time.Minute)))
+}
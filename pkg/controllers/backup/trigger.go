@@ -0,0 +1,56 @@
+package backup
+
+import (
+	"encoding/json"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// pendingTrigger returns the value of the trigger-snapshot annotation if it requests a snapshot that wasn't taken yet
+func pendingTrigger(backup *v1.Backup) (string, bool) {
+	token := backup.Annotations[v1.TriggerSnapshotAnnotation]
+	if token == "" {
+		return "", false
+	}
+	if backup.Status.LastTrigger != nil && backup.Status.LastTrigger.Token == token {
+		return "", false
+	}
+	return token, true
+}
+
+// triggerUser returns the user who set the trigger-snapshot annotation on the backup, as recorded by the admission webhook
+func (h *handler) triggerUser(backup *v1.Backup) string {
+	if !h.triggerUserRecorded {
+		return ""
+	}
+	return backup.Annotations[v1.TriggerSnapshotUserAnnotation]
+}
+
+// triggerFieldManager returns the field manager that most recently set the trigger-snapshot annotation on the backup
+func triggerFieldManager(backup *v1.Backup) string {
+	var manager string
+	var lastSetAt int64
+	for _, entry := range backup.ManagedFields {
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]map[string]map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			logrus.Debugf("Error parsing managed fields of backup %v: %v", backup.Name, err)
+			continue
+		}
+		if _, ok := fields["f:metadata"]["f:annotations"]["f:"+v1.TriggerSnapshotAnnotation]; !ok {
+			continue
+		}
+		var setAt int64
+		if entry.Time != nil {
+			setAt = entry.Time.Unix()
+		}
+		if manager == "" || setAt >= lastSetAt {
+			manager = entry.Manager
+			lastSetAt = setAt
+		}
+	}
+	return manager
+}
@@ -2,20 +2,24 @@ package backup
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/archiveformat"
 	"github.com/rancher/backup-restore-operator/pkg/objectstore"
+	"github.com/rancher/backup-restore-operator/pkg/util/archiveencryption"
+	archivecompression "github.com/rancher/backup-restore-operator/pkg/util/compression"
 	"github.com/sirupsen/logrus"
 )
 
-func (h *handler) uploadToS3(backup *v1.Backup, objectStore *v1.S3ObjectStore, tmpBackupPath, gzipFile string) error {
+// uploadToS3 compresses the backup and uploads it to the object store, recording the size and location of the archive in snapshot
+func (h *handler) uploadToS3(backup *v1.Backup, objectStore *v1.S3ObjectStore, tmpBackupPath, gzipFile string, archiveKeys *archiveencryption.Keys, snapshot *v1.BackupSnapshot) error {
 	tmpBackupGzipFilepath, err := os.MkdirTemp("", "uploadpath")
 	if err != nil {
 		return err
@@ -28,34 +32,103 @@ func (h *handler) uploadToS3(backup *v1.Backup, objectStore *v1.S3ObjectStore, t
 		gzipFile = fmt.Sprintf("%s/%s", strings.TrimRight(objectStore.Folder, "/"), gzipFile)
 		gzipFile = strings.Trim(gzipFile, "/")
 	}
-	if err := CreateTarAndGzip(tmpBackupPath, tmpBackupGzipFilepath, gzipFile, backup.Name); err != nil {
+	if err := writeArchive(tmpBackupPath, tmpBackupGzipFilepath, gzipFile, backup.Name, backup.Spec.Compression, archiveKeys); err != nil {
 		return removeTempUploadDir(tmpBackupGzipFilepath, err)
 	}
+	fileInfo, err := os.Stat(filepath.Join(tmpBackupGzipFilepath, gzipFile))
+	if err != nil {
+		return removeTempUploadDir(tmpBackupGzipFilepath, err)
+	}
+	snapshot.Size = fileInfo.Size()
+	snapshot.Location = fmt.Sprintf("s3://%s/%s", objectStore.BucketName, gzipFile)
 	s3Client, err := objectstore.GetS3Client(h.ctx, objectStore, h.dynamicClient)
 	if err != nil {
 		return removeTempUploadDir(tmpBackupGzipFilepath, err)
 	}
-	if err := objectstore.UploadBackupFile(s3Client, objectStore.BucketName, gzipFile, filepath.Join(tmpBackupGzipFilepath, gzipFile)); err != nil {
+	uploadOptions, err := objectstore.NewUploadOptions(h.ctx, objectStore, h.dynamicClient)
+	if err != nil {
+		return removeTempUploadDir(tmpBackupGzipFilepath, err)
+	}
+	lockOptions, err := objectLockOptions(backup.Spec, objectStore.ObjectLock, time.Now())
+	if err != nil {
+		return removeTempUploadDir(tmpBackupGzipFilepath, err)
+	}
+	uploadOptions.RetentionMode, uploadOptions.RetainUntil, uploadOptions.LegalHold = lockOptions.RetentionMode, lockOptions.RetainUntil, lockOptions.LegalHold
+	if err := objectstore.UploadBackupFile(s3Client, objectStore.BucketName, gzipFile, filepath.Join(tmpBackupGzipFilepath, gzipFile), uploadOptions); err != nil {
 		return removeTempUploadDir(tmpBackupGzipFilepath, err)
 	}
 	return os.RemoveAll(tmpBackupGzipFilepath)
 }
 
-func CreateTarAndGzip(backupPath, targetGzipPath, targetGzipFile, backupCRName string) error {
-	logrus.Infof("Compressing backup CR %v", backupCRName)
+// writeArchive creates the archive of the backup, encrypted as a whole when archiveKeys are set
+func writeArchive(backupPath, targetGzipPath, targetGzipFile, backupCRName string, compression *v1.Compression, archiveKeys *archiveencryption.Keys) error {
+	if err := CreateTarAndGzip(backupPath, targetGzipPath, targetGzipFile, backupCRName, compression); err != nil {
+		return err
+	}
+	if archiveKeys == nil {
+		return nil
+	}
+	logrus.Infof("Encrypting archive of backup CR %v", backupCRName)
+	return sealArchive(filepath.Join(targetGzipPath, targetGzipFile), archiveKeys)
+}
+
+// sealArchive replaces the archive at archivePath with its encryption to the recipients of archiveKeys
+func sealArchive(archivePath string, archiveKeys *archiveencryption.Keys) error {
+	src, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	sealedPath := archivePath + ".tmp"
+	dst, err := os.Create(sealedPath)
+	if err != nil {
+		return fmt.Errorf("error creating encrypted backup file: %v", err)
+	}
+	defer os.Remove(sealedPath)
+	defer dst.Close()
+	w, err := archiveKeys.Encrypt(dst)
+	if err != nil {
+		return fmt.Errorf("error encrypting backup file: %v", err)
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		return fmt.Errorf("error encrypting backup file: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error encrypting backup file: %v", err)
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Rename(sealedPath, archivePath)
+}
+
+// CreateTarAndGzip writes the tarball of backupPath compressed with the algorithm and level of compression, gzip at its
+// default level when it is nil. The format and names files are written first, so that readers know the layout of the
+// archive before its resources.
+func CreateTarAndGzip(backupPath, targetGzipPath, targetGzipFile, backupCRName string, compression *v1.Compression) error {
+	logrus.Infof("Compressing backup CR %v with %v", backupCRName, archivecompression.Algorithm(compression))
 	gzipFile, err := os.Create(filepath.Join(targetGzipPath, targetGzipFile))
 	if err != nil {
 		return fmt.Errorf("error creating backup tar gzip file: %v", err)
 	}
+	defer gzipFile.Close()
 	// writes to gw will be compressed and written to gzipFile
-	gw := gzip.NewWriter(gzipFile)
+	gw, err := archivecompression.NewWriter(gzipFile, compression)
+	if err != nil {
+		return err
+	}
 	defer gw.Close()
 	// writes to tw will be written to gw
 	tw := tar.NewWriter(gw)
 	defer tw.Close()
+	if err := archiveformat.WriteFormat(tw, archiveformat.CurrentFormat()); err != nil {
+		return err
+	}
+	namesFile := filepath.Join(backupPath, filepath.FromSlash(archiveformat.NamesPath))
+	namesWritten := false
 
 	walkFunc := func(currPath string, info os.FileInfo, err error) error {
-		if currPath == backupPath {
+		if currPath == backupPath || (namesWritten && currPath == namesFile) {
 			return nil
 		}
 		if err != nil {
@@ -87,6 +160,15 @@ func CreateTarAndGzip(backupPath, targetGzipPath, targetGzipFile, backupCRName s
 		}
 		return fInfo.Close()
 	}
+	// the names file follows the format file, so that readers can parse the paths of resources as they read them
+	if namesInfo, err := os.Stat(namesFile); err == nil {
+		if err := walkFunc(namesFile, namesInfo, nil); err != nil {
+			return err
+		}
+		namesWritten = true
+	} else if !os.IsNotExist(err) {
+		return err
+	}
 	return filepath.Walk(backupPath, walkFunc)
 }
 
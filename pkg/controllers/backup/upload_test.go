@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rancher/backup-restore-operator/pkg/archiveformat"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets"
+	"github.com/rancher/backup-restore-operator/pkg/util/compression"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sEncryptionconfig "k8s.io/apiserver/pkg/server/options/encryptionconfig"
+)
+
+func TestCreateTarAndGzipWithUnsafeNames(t *testing.T) {
+	widgets := resourcesets.GVResource{GroupVersion: schema.GroupVersion{Group: "example.io", Version: "v1"}, Name: "widgets", Namespaced: true}
+	objectNames := []string{"plain", "a/b", "a/../../c", strings.Repeat("n", 253)}
+	var objects []unstructured.Unstructured
+	for _, name := range objectNames {
+		objects = append(objects, unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.io/v1",
+			"kind":       "Widget",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "ns"},
+		}})
+	}
+	rh := resourcesets.ResourceHandler{
+		Ctx:                 context.Background(),
+		TransformerMap:      k8sEncryptionconfig.StaticTransformers{},
+		GVResourceToObjects: map[resourcesets.GVResource][]unstructured.Unstructured{widgets: objects},
+	}
+	backupPath, archivePath := t.TempDir(), t.TempDir()
+	written, err := rh.WriteBackupObjects(backupPath)
+	require.NoError(t, err)
+	assert.Equal(t, len(objectNames), written)
+
+	require.NoError(t, CreateTarAndGzip(backupPath, archivePath, "archive.tar.gz", "backup", nil))
+	f, err := os.Open(filepath.Join(archivePath, "archive.tar.gz"))
+	require.NoError(t, err)
+	defer f.Close()
+	decompressed, _, err := compression.NewReader(f)
+	require.NoError(t, err)
+	reader, err := archiveformat.NewReader(decompressed)
+	require.NoError(t, err)
+	assert.Equal(t, archiveformat.CurrentFormat(), reader.Format())
+
+	var restoredNames []string
+	var paths []string
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		paths = append(paths, entry.Header.Name)
+		if entry.Resource == nil {
+			continue
+		}
+		var object map[string]interface{}
+		require.NoError(t, json.Unmarshal(entry.Data, &object))
+		assert.Equal(t, entry.Resource.Name, object["metadata"].(map[string]interface{})["name"])
+		assert.Equal(t, "ns", entry.Resource.Namespace)
+		restoredNames = append(restoredNames, entry.Resource.Name)
+	}
+	assert.ElementsMatch(t, objectNames, restoredNames)
+	require.GreaterOrEqual(t, len(paths), 2)
+	assert.Equal(t, []string{archiveformat.FormatPath, archiveformat.NamesPath}, paths[:2])
+	assert.Equal(t, 1, strings.Count(strings.Join(paths, ","), archiveformat.NamesPath), "the names file is written once")
+}
@@ -0,0 +1,260 @@
+package backuparchive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	backupControllers "github.com/rancher/backup-restore-operator/pkg/generated/controllers/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/objectstore"
+	"github.com/rancher/backup-restore-operator/pkg/storage"
+	"github.com/rancher/backup-restore-operator/pkg/util"
+	v1core "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+type handler struct {
+	ctx                     context.Context
+	archives                backupControllers.BackupArchiveController
+	backups                 backupControllers.BackupController
+	dynamicClient           dynamic.Interface
+	defaultBackupMountPath  string
+	defaultS3BackupLocation *v1.S3ObjectStore
+	kubeSystemNS            string
+	orphanRetention         time.Duration
+}
+
+// storageLocation is a location the catalog lists archives from
+type storageLocation struct {
+	// hash identifies the location, it is the value of the storage location label of its BackupArchives
+	hash         string
+	locationType string
+	mountPath    string
+	objectStore  *v1.S3ObjectStore
+	// isDefault is true for the operator's default storage location, which a Restore uses when none is set
+	isDefault bool
+}
+
+// Register starts the catalog, which lists the operator's default storage location and the S3 locations of all Backup
+// CRs every syncInterval, and keeps a BackupArchive for every archive found in them. When orphanRetention is set, the
+// archives of deleted Backups older than it are deleted from storage.
+func Register(
+	ctx context.Context,
+	archives backupControllers.BackupArchiveController,
+	backups backupControllers.BackupController,
+	namespaces v1core.NamespaceController,
+	dynamicInterface dynamic.Interface,
+	defaultLocalBackupLocation string,
+	defaultS3 *v1.S3ObjectStore,
+	syncInterval time.Duration,
+	orphanRetention time.Duration) {
+
+	controller := &handler{
+		ctx:                     ctx,
+		archives:                archives,
+		backups:                 backups,
+		dynamicClient:           dynamicInterface,
+		defaultBackupMountPath:  defaultLocalBackupLocation,
+		defaultS3BackupLocation: defaultS3,
+		orphanRetention:         orphanRetention,
+	}
+
+	if orphanRetention > 0 {
+		// only the archives of this cluster are swept, its kube-system namespace UID is in their filename
+		kubeSystemNS, err := namespaces.Get("kube-system", k8sv1.GetOptions{})
+		if err != nil {
+			logrus.Fatalf("Error getting namespace kube-system %v", err)
+		}
+		controller.kubeSystemNS = string(kubeSystemNS.UID)
+		logrus.Infof("Archives of deleted backups are deleted %v after they were taken", orphanRetention)
+	}
+
+	go controller.syncPeriodically(syncInterval)
+}
+
+func (h *handler) syncPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.sync()
+		h.sweepOrphans()
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *handler) sync() {
+	logrus.Debug("Syncing backup archive catalog from storage locations")
+	locations, err := h.storageLocations()
+	if err != nil {
+		logrus.Errorf("Error finding storage locations for the backup archive catalog: %v", err)
+		return
+	}
+	for _, location := range locations {
+		if err := h.syncStorageLocation(location); err != nil {
+			logrus.Errorf("Error syncing backup archive catalog from %v storage location: %v", location.locationType, err)
+		}
+	}
+}
+
+func (h *handler) storageLocations() ([]storageLocation, error) {
+	var locations []storageLocation
+	if h.defaultBackupMountPath != "" {
+		locations = append(locations, storageLocation{
+			hash:         locationHash(util.PVBackup, h.defaultBackupMountPath),
+			locationType: util.PVBackup,
+			mountPath:    h.defaultBackupMountPath,
+			isDefault:    true,
+		})
+	} else if h.defaultS3BackupLocation != nil {
+		locations = append(locations, newS3StorageLocation(h.defaultS3BackupLocation, true))
+	}
+
+	backups, err := h.backups.List(k8sv1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, location := range locations {
+		seen[location.hash] = true
+	}
+	for _, backup := range backups.Items {
+		if backup.Spec.StorageLocation == nil || backup.Spec.StorageLocation.S3 == nil {
+			continue
+		}
+		location := newS3StorageLocation(backup.Spec.StorageLocation.S3, false)
+		if seen[location.hash] {
+			continue
+		}
+		seen[location.hash] = true
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+func newS3StorageLocation(objectStore *v1.S3ObjectStore, isDefault bool) storageLocation {
+	return storageLocation{
+		hash:         locationHash(util.S3Backup, objectStore.Endpoint, objectStore.BucketName, strings.Trim(objectStore.Folder, "/")),
+		locationType: util.S3Backup,
+		objectStore:  objectStore,
+		isDefault:    isDefault,
+	}
+}
+
+func locationHash(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "/")))
+	return hex.EncodeToString(sum[:])[:10]
+}
+
+// syncStorageLocation makes the BackupArchives of the storage location match the archives found in it
+func (h *handler) syncStorageLocation(location storageLocation) error {
+	var found []storage.Archive
+	var err error
+	if location.locationType == util.PVBackup {
+		found, err = storage.ListMountPath(location.mountPath)
+	} else {
+		s3Client, clientErr := objectstore.GetS3Client(h.ctx, location.objectStore, h.dynamicClient)
+		if clientErr != nil {
+			return clientErr
+		}
+		found, err = storage.ListS3(h.ctx, s3Client, location.objectStore)
+	}
+	if err != nil {
+		return err
+	}
+
+	desired := make(map[string]*v1.BackupArchive)
+	for _, archive := range found {
+		backupArchive := newBackupArchive(location, archive)
+		desired[backupArchive.Name] = backupArchive
+	}
+
+	existing, err := h.archives.List(k8sv1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", v1.BackupArchiveStorageLocationLabel, location.hash)})
+	if err != nil {
+		return err
+	}
+	for i := range existing.Items {
+		backupArchive := &existing.Items[i]
+		want, ok := desired[backupArchive.Name]
+		if !ok {
+			logrus.Infof("Archive %v no longer exists in its storage location, deleting BackupArchive %v", backupArchive.Spec.Filename, backupArchive.Name)
+			if err := h.archives.Delete(backupArchive.Name, &k8sv1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			continue
+		}
+		delete(desired, backupArchive.Name)
+		if equality.Semantic.DeepEqual(backupArchive.Spec, want.Spec) {
+			continue
+		}
+		backupArchive.Spec = want.Spec
+		if _, err := h.archives.Update(backupArchive); err != nil {
+			return err
+		}
+	}
+	for _, backupArchive := range desired {
+		logrus.Debugf("Adding archive %v to the backup archive catalog", backupArchive.Spec.Filename)
+		if _, err := h.archives.Create(backupArchive); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func newBackupArchive(location storageLocation, archive storage.Archive) *v1.BackupArchive {
+	backupArchive := &v1.BackupArchive{
+		ObjectMeta: k8sv1.ObjectMeta{
+			Name: backupArchiveName(archive.Filename, location.hash),
+			Labels: map[string]string{
+				v1.BackupArchiveStorageLocationLabel: location.hash,
+			},
+		},
+		Spec: v1.BackupArchiveSpec{
+			Filename:            archive.Filename,
+			BackupName:          archive.BackupName,
+			ClusterUID:          archive.ClusterUID,
+			Timestamp:           archive.Timestamp.Format(time.RFC3339),
+			Size:                archive.Size,
+			Encrypted:           archive.Encrypted,
+			Sealed:              archive.Sealed,
+			StorageLocationType: location.locationType,
+		},
+	}
+	if !location.isDefault && location.objectStore != nil {
+		backupArchive.Spec.StorageLocation = &v1.StorageLocation{S3: location.objectStore.DeepCopy()}
+	}
+	return backupArchive
+}
+
+// backupArchiveName returns the name of the BackupArchive of an archive: its lowercased filename without extension,
+// followed by the hash of its storage location, since archives with the same filename can be stored in several locations.
+// The characters a name can't hold are replaced, such as the + of a positive UTC offset in the timestamp.
+func backupArchiveName(filename, locationHash string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		case r == '+':
+			return 'p'
+		default:
+			return '-'
+		}
+	}, strings.ToLower(storage.TrimArchiveExtension(filename)))
+	if maxLength := validation.DNS1123SubdomainMaxLength - len(locationHash) - 1; len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], ".-")
+	}
+	return name + "-" + locationHash
+}
@@ -0,0 +1,141 @@
+package backuparchive
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/storage"
+	"github.com/rancher/backup-restore-operator/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestBackupArchiveName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		filename string
+		expected string
+	}{
+		{
+			name:     "Archive",
+			filename: "b-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-2024-01-02T03-04-05Z.tar.gz",
+			expected: "b-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-2024-01-02t03-04-05z-0123456789",
+		},
+		{
+			name:     "Encrypted archive",
+			filename: "b-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-2024-01-02T03-04-05-04-00.tar.gz.enc",
+			expected: "b-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-2024-01-02t03-04-05-04-00-0123456789",
+		},
+		{
+			name:     "Archive with a positive UTC offset",
+			filename: "b-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-2024-01-02T03-04-05+05-30.tar.gz",
+			expected: "b-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-2024-01-02t03-04-05p05-30-0123456789",
+		},
+		{
+			name:     "Sealed zstd archive",
+			filename: "b-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-2024-01-02T03-04-05Z.tar.zst.enc.age",
+			expected: "b-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-2024-01-02t03-04-05z-0123456789",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			name := backupArchiveName(testCase.filename, "0123456789")
+			assert.Equal(t, testCase.expected, name)
+			assert.Empty(t, validation.IsDNS1123Subdomain(name))
+		})
+	}
+}
+
+func TestBackupArchiveNameTruncated(t *testing.T) {
+	filename := strings.Repeat("a", 250) + "-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-2024-01-02T03-04-05Z.tar.gz"
+
+	name := backupArchiveName(filename, "0123456789")
+
+	assert.Empty(t, validation.IsDNS1123Subdomain(name))
+	assert.True(t, strings.HasSuffix(name, "-0123456789"))
+}
+
+func TestNewBackupArchive(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	archive := storage.Archive{
+		ArchiveName: storage.ArchiveName{
+			BackupName: "b",
+			ClusterUID: "24e1b8ce-1f00-4bbe-94bb-248ad7606dc8",
+			Timestamp:  timestamp,
+			Encrypted:  true,
+		},
+		Filename: "b-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-2024-01-02T03-04-05Z.tar.gz.enc",
+		Size:     1024,
+	}
+	objectStore := &v1.S3ObjectStore{Endpoint: "s3.example.com", BucketName: "backups", Folder: "cluster"}
+
+	defaultArchive := newBackupArchive(newS3StorageLocation(objectStore, true), archive)
+	assert.Nil(t, defaultArchive.Spec.StorageLocation, "archives in the default location must be restorable without a storage location")
+
+	backupArchive := newBackupArchive(newS3StorageLocation(objectStore, false), archive)
+	require.NotNil(t, backupArchive.Spec.StorageLocation)
+	assert.Equal(t, objectStore, backupArchive.Spec.StorageLocation.S3)
+	assert.Equal(t, v1.BackupArchiveSpec{
+		Filename:            archive.Filename,
+		BackupName:          "b",
+		ClusterUID:          "24e1b8ce-1f00-4bbe-94bb-248ad7606dc8",
+		Timestamp:           "2024-01-02T03:04:05Z",
+		Size:                1024,
+		Encrypted:           true,
+		StorageLocationType: util.S3Backup,
+		StorageLocation:     backupArchive.Spec.StorageLocation,
+	}, backupArchive.Spec)
+	assert.Equal(t, defaultArchive.Name, backupArchive.Name, "both archives are in the same bucket and folder")
+}
+
+func TestIsOrphan(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	newInput := func(backupName, clusterUID string, age time.Duration) *v1.BackupArchive {
+		return &v1.BackupArchive{
+			Spec: v1.BackupArchiveSpec{
+				BackupName: backupName,
+				ClusterUID: clusterUID,
+				Timestamp:  now.Add(-age).Format(time.RFC3339),
+			},
+		}
+	}
+	existingBackups := map[string]bool{"nightly": true}
+	retention := 24 * time.Hour
+
+	testCases := []struct {
+		name     string
+		input    *v1.BackupArchive
+		expected bool
+	}{
+		{
+			name:     "Archive of a deleted backup",
+			input:    newInput("deleted", "this-cluster", 48*time.Hour),
+			expected: true,
+		},
+		{
+			name:     "Recent archive of a deleted backup",
+			input:    newInput("deleted", "this-cluster", time.Hour),
+			expected: false,
+		},
+		{
+			name:     "Archive of an existing backup",
+			input:    newInput("nightly", "this-cluster", 48*time.Hour),
+			expected: false,
+		},
+		{
+			name:     "Archive of another cluster",
+			input:    newInput("deleted", "other-cluster", 48*time.Hour),
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, isOrphan(testCase.input, "this-cluster", existingBackups, retention, now))
+		})
+	}
+}
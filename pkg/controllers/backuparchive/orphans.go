@@ -0,0 +1,92 @@
+package backuparchive
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/objectstore"
+	"github.com/rancher/backup-restore-operator/pkg/storage"
+	"github.com/rancher/backup-restore-operator/pkg/util"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// sweepOrphans deletes the archives taken in this cluster by Backups that no longer exist, once they are older than the
+// orphan retention. Archives of other clusters sharing the storage location are never deleted.
+func (h *handler) sweepOrphans() {
+	if h.orphanRetention <= 0 {
+		return
+	}
+	backupArchives, err := h.archives.List(k8sv1.ListOptions{})
+	if err != nil {
+		logrus.Errorf("Error listing backup archives to find orphans: %v", err)
+		return
+	}
+	backups, err := h.backups.List(k8sv1.ListOptions{})
+	if err != nil {
+		logrus.Errorf("Error listing backups to find orphaned archives: %v", err)
+		return
+	}
+	existing := make(map[string]bool)
+	for _, backup := range backups.Items {
+		existing[backup.Name] = true
+	}
+
+	now := time.Now()
+	for i := range backupArchives.Items {
+		backupArchive := &backupArchives.Items[i]
+		if !isOrphan(backupArchive, h.kubeSystemNS, existing, h.orphanRetention, now) {
+			continue
+		}
+		logrus.Infof("Deleting archive %v of deleted backup %v, taken at %v", backupArchive.Spec.Filename, backupArchive.Spec.BackupName, backupArchive.Spec.Timestamp)
+		if err := h.deleteArchive(backupArchive); err != nil {
+			logrus.Errorf("Error deleting orphaned archive %v: %v", backupArchive.Spec.Filename, err)
+			continue
+		}
+		if err := h.archives.Delete(backupArchive.Name, &k8sv1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			logrus.Errorf("Error deleting BackupArchive %v: %v", backupArchive.Name, err)
+		}
+	}
+}
+
+// isOrphan returns true for the archives taken in the cluster by backups that don't exist anymore, older than retention
+func isOrphan(backupArchive *v1.BackupArchive, clusterUID string, existingBackups map[string]bool, retention time.Duration, now time.Time) bool {
+	if backupArchive.Spec.ClusterUID != clusterUID || existingBackups[backupArchive.Spec.BackupName] {
+		return false
+	}
+	timestamp, err := time.Parse(time.RFC3339, backupArchive.Spec.Timestamp)
+	if err != nil {
+		return false
+	}
+	return now.Sub(timestamp) > retention
+}
+
+func (h *handler) deleteArchive(backupArchive *v1.BackupArchive) error {
+	objectStore := h.defaultS3BackupLocation
+	if backupArchive.Spec.StorageLocation != nil {
+		objectStore = backupArchive.Spec.StorageLocation.S3
+	} else if backupArchive.Spec.StorageLocationType == util.PVBackup {
+		if h.defaultBackupMountPath == "" {
+			return errors.New("the operator's default storage location is not a persistent volume anymore")
+		}
+		err := os.Remove(filepath.Join(h.defaultBackupMountPath, backupArchive.Spec.Filename))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if objectStore == nil {
+		return errors.New("the operator's default storage location is not S3 anymore")
+	}
+	s3Client, err := objectstore.GetS3Client(h.ctx, objectStore, h.dynamicClient)
+	if err != nil {
+		return err
+	}
+	return s3Client.RemoveObject(h.ctx, objectStore.BucketName, storage.ObjectKey(objectStore, backupArchive.Spec.Filename), minio.RemoveObjectOptions{})
+}
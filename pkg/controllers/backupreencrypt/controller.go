@@ -0,0 +1,327 @@
+package backupreencrypt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	backupControllers "github.com/rancher/backup-restore-operator/pkg/generated/controllers/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/storage"
+	"github.com/rancher/backup-restore-operator/pkg/util/archiveencryption"
+	"github.com/rancher/backup-restore-operator/pkg/util/encryptionconfig"
+	v1core "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sEncryptionconfig "k8s.io/apiserver/pkg/server/options/encryptionconfig"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+type handler struct {
+	ctx                     context.Context
+	reencrypts              backupControllers.BackupReencryptController
+	backups                 backupControllers.BackupController
+	secrets                 v1core.SecretController
+	dynamicClient           dynamic.Interface
+	defaultBackupMountPath  string
+	defaultS3BackupLocation *v1.S3ObjectStore
+	kubeSystemNS            string
+	encryptionProviderPath  string
+}
+
+// Register starts the controller of BackupReencrypts, which re-encrypt the archives of a Backup one at a time,
+// recording the progress of each archive in their status
+func Register(
+	ctx context.Context,
+	reencrypts backupControllers.BackupReencryptController,
+	backups backupControllers.BackupController,
+	secrets v1core.SecretController,
+	namespaces v1core.NamespaceController,
+	dynamicInterface dynamic.Interface,
+	defaultLocalBackupLocation string,
+	defaultS3 *v1.S3ObjectStore,
+	encryptionProviderPath string) {
+
+	controller := &handler{
+		ctx:                     ctx,
+		reencrypts:              reencrypts,
+		backups:                 backups,
+		secrets:                 secrets,
+		dynamicClient:           dynamicInterface,
+		defaultBackupMountPath:  defaultLocalBackupLocation,
+		defaultS3BackupLocation: defaultS3,
+		encryptionProviderPath:  encryptionProviderPath,
+	}
+
+	// only the archives taken by this cluster are re-encrypted, its kube-system namespace UID is in their filename
+	kubeSystemNS, err := namespaces.Get("kube-system", k8sv1.GetOptions{})
+	if err != nil {
+		logrus.Fatalf("Error getting namespace kube-system %v", err)
+	}
+	controller.kubeSystemNS = string(kubeSystemNS.UID)
+
+	reencrypts.OnChange(ctx, "backup-reencrypt", controller.OnBackupReencryptChange)
+}
+
+func (h *handler) OnBackupReencryptChange(_ string, reencrypt *v1.BackupReencrypt) (*v1.BackupReencrypt, error) {
+	if reencrypt == nil || reencrypt.DeletionTimestamp != nil {
+		return reencrypt, nil
+	}
+	if reencrypt.Status.CompletionTimestamp != "" {
+		return reencrypt, nil
+	}
+	if err := validateBackupReencryptSpec(reencrypt.Spec); err != nil {
+		return h.setReconcilingCondition(reencrypt, err)
+	}
+	location, err := h.storageLocation(reencrypt)
+	if err != nil {
+		return h.setReconcilingCondition(reencrypt, err)
+	}
+
+	if reencrypt.Status.Archives == nil {
+		archives, err := h.archivesToReencrypt(reencrypt.Spec.BackupName, location)
+		if err != nil {
+			return h.setReconcilingCondition(reencrypt, err)
+		}
+		logrus.Infof("Re-encrypting %d archives of backup %v for BackupReencrypt %v", len(archives), reencrypt.Spec.BackupName, reencrypt.Name)
+		return h.updateStatus(reencrypt, func(status *v1.BackupReencryptStatus) {
+			status.Archives = make([]v1.ArchiveReencryption, 0, len(archives))
+			for _, archive := range archives {
+				status.Archives = append(status.Archives, v1.ArchiveReencryption{Filename: archive.Filename, Phase: v1.ArchiveReencryptionPending})
+			}
+			status.Total = len(archives)
+		})
+	}
+
+	next := nextPendingArchive(reencrypt.Status.Archives)
+	if next < 0 {
+		return h.updateStatus(reencrypt, func(status *v1.BackupReencryptStatus) {
+			status.CompletionTimestamp = time.Now().Format(time.RFC3339)
+		})
+	}
+
+	oldTransformers, err := h.getTransformers(reencrypt.Spec.OldEncryptionConfigSecretName)
+	if err != nil {
+		return h.setReconcilingCondition(reencrypt, err)
+	}
+	newTransformers, err := h.getTransformers(reencrypt.Spec.NewEncryptionConfigSecretName)
+	if err != nil {
+		return h.setReconcilingCondition(reencrypt, err)
+	}
+	var archiveKeys *archiveencryption.Keys
+	if reencrypt.Spec.ArchiveEncryptionSecretName != "" {
+		archiveEncryptionSecret, err := archiveencryption.GetArchiveEncryptionSecret(h.secrets, reencrypt.Spec.ArchiveEncryptionSecretName)
+		if err != nil {
+			return h.setReconcilingCondition(reencrypt, err)
+		}
+		if archiveKeys, err = archiveencryption.GetKeysFromSecret(h.ctx, archiveEncryptionSecret); err != nil {
+			return h.setReconcilingCondition(reencrypt, err)
+		}
+	}
+
+	filename := reencrypt.Status.Archives[next].Filename
+	logrus.Infof("Re-encrypting archive %v for BackupReencrypt %v", filename, reencrypt.Name)
+	count, reencryptErr := h.reencrypt(location, filename, oldTransformers, newTransformers, archiveKeys)
+	if reencryptErr != nil {
+		logrus.Errorf("Error re-encrypting archive %v for BackupReencrypt %v: %v", filename, reencrypt.Name, reencryptErr)
+	} else {
+		logrus.Infof("Re-encrypted and verified %d resources of archive %v", count, filename)
+	}
+	// a failed archive is left as it was stored, and the next archives are still re-encrypted
+	return h.updateStatus(reencrypt, func(status *v1.BackupReencryptStatus) {
+		archive := &status.Archives[next]
+		archive.ResourceCount = count
+		if reencryptErr != nil {
+			archive.Phase = v1.ArchiveReencryptionFailed
+			archive.Message = reencryptErr.Error()
+		} else {
+			archive.Phase = v1.ArchiveReencryptionReencrypted
+		}
+	})
+}
+
+func validateBackupReencryptSpec(spec v1.BackupReencryptSpec) error {
+	if spec.BackupName == "" {
+		return fmt.Errorf("backupName must be set")
+	}
+	if spec.OldEncryptionConfigSecretName == "" || spec.NewEncryptionConfigSecretName == "" {
+		return fmt.Errorf("oldEncryptionConfigSecretName and newEncryptionConfigSecretName must be set")
+	}
+	return nil
+}
+
+func nextPendingArchive(archives []v1.ArchiveReencryption) int {
+	for i, archive := range archives {
+		if archive.Phase == v1.ArchiveReencryptionPending {
+			return i
+		}
+	}
+	return -1
+}
+
+func (h *handler) getTransformers(encryptionConfigSecretName string) (k8sEncryptionconfig.StaticTransformers, error) {
+	encryptionConfigSecret, err := encryptionconfig.GetEncryptionConfigSecret(h.secrets, encryptionConfigSecretName)
+	if err != nil {
+		return nil, err
+	}
+	return encryptionconfig.GetEncryptionTransformersFromSecret(h.ctx, encryptionConfigSecret, h.encryptionProviderPath)
+}
+
+// archivesToReencrypt returns the archives taken by the backup in this cluster whose resources are encrypted, oldest first
+func (h *handler) archivesToReencrypt(backupName string, location *v1.StorageLocation) ([]storage.Archive, error) {
+	archives, err := h.listArchives(location)
+	if err != nil {
+		return nil, err
+	}
+	var toReencrypt []storage.Archive
+	for i := len(archives) - 1; i >= 0; i-- {
+		archive := archives[i]
+		if archive.BackupName == backupName && archive.ClusterUID == h.kubeSystemNS && archive.Encrypted {
+			toReencrypt = append(toReencrypt, archive)
+		}
+	}
+	return toReencrypt, nil
+}
+
+// reencrypt re-encrypts an archive in a local copy, verifies it, replaces the stored archive with it, and verifies the
+// stored archive. It returns the number of resources in the archive.
+func (h *handler) reencrypt(location *v1.StorageLocation, filename string, oldTransformers, newTransformers k8sEncryptionconfig.StaticTransformers,
+	archiveKeys *archiveencryption.Keys) (int, error) {
+	sourcePath, removeSource, err := h.fetchArchive(location, filename)
+	if err != nil {
+		return 0, err
+	}
+	defer removeSource()
+
+	tmpDir, err := os.MkdirTemp("", "reencrypt")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(tmpDir)
+	reencryptedPath := filepath.Join(tmpDir, filename)
+	count, err := reencryptFile(h.ctx, sourcePath, reencryptedPath, oldTransformers, newTransformers, archiveKeys)
+	if err != nil {
+		return count, err
+	}
+	if err := verifyFile(h.ctx, reencryptedPath, newTransformers, archiveKeys, count); err != nil {
+		return count, err
+	}
+
+	if err := h.storeArchive(location, filename, reencryptedPath); err != nil {
+		return count, fmt.Errorf("error storing re-encrypted archive: %v", err)
+	}
+	storedPath, removeStored, err := h.fetchArchive(location, filename)
+	if err != nil {
+		return count, fmt.Errorf("error reading re-encrypted archive back from storage: %v", err)
+	}
+	defer removeStored()
+	if err := verifyFile(h.ctx, storedPath, newTransformers, archiveKeys, count); err != nil {
+		return count, fmt.Errorf("error verifying stored archive: %v", err)
+	}
+	return count, nil
+}
+
+func reencryptFile(ctx context.Context, sourcePath, targetPath string, oldTransformers, newTransformers k8sEncryptionconfig.StaticTransformers,
+	archiveKeys *archiveencryption.Keys) (int, error) {
+	src, err := os.Open(sourcePath)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	dst, err := os.Create(targetPath)
+	if err != nil {
+		return 0, err
+	}
+	defer dst.Close()
+	count, err := reencryptArchive(ctx, src, dst, oldTransformers, newTransformers, archiveKeys)
+	if err != nil {
+		return count, err
+	}
+	return count, dst.Close()
+}
+
+func verifyFile(ctx context.Context, path string, transformers k8sEncryptionconfig.StaticTransformers, archiveKeys *archiveencryption.Keys, expectedCount int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	count, err := verifyArchive(ctx, f, transformers, archiveKeys)
+	if err != nil {
+		return err
+	}
+	if count != expectedCount {
+		return fmt.Errorf("re-encrypted archive has %d resources instead of %d", count, expectedCount)
+	}
+	return nil
+}
+
+// updateStatus applies update to the status of the BackupReencrypt, recomputing its counts and conditions
+func (h *handler) updateStatus(reencrypt *v1.BackupReencrypt, update func(status *v1.BackupReencryptStatus)) (*v1.BackupReencrypt, error) {
+	var updated *v1.BackupReencrypt
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		updReencrypt, err := h.reencrypts.Get(reencrypt.Name, k8sv1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		update(&updReencrypt.Status)
+		updReencrypt.Status.ObservedGeneration = updReencrypt.Generation
+		updReencrypt.Status.Reencrypted, updReencrypt.Status.Failed = 0, 0
+		for _, archive := range updReencrypt.Status.Archives {
+			switch archive.Phase {
+			case v1.ArchiveReencryptionReencrypted:
+				updReencrypt.Status.Reencrypted++
+			case v1.ArchiveReencryptionFailed:
+				updReencrypt.Status.Failed++
+			}
+		}
+		v1.BackupReencryptConditionReconciling.SetStatusBool(updReencrypt, false)
+		v1.BackupReencryptConditionReconciling.Reason(updReencrypt, "")
+		v1.BackupReencryptConditionReconciling.Message(updReencrypt, "")
+		if updReencrypt.Status.CompletionTimestamp != "" {
+			v1.BackupReencryptConditionReady.SetStatusBool(updReencrypt, updReencrypt.Status.Failed == 0)
+			v1.BackupReencryptConditionReady.Message(updReencrypt, fmt.Sprintf("Re-encrypted %d of %d archives", updReencrypt.Status.Reencrypted, updReencrypt.Status.Total))
+		} else {
+			v1.BackupReencryptConditionReady.SetStatusBool(updReencrypt, false)
+			v1.BackupReencryptConditionReady.Message(updReencrypt, fmt.Sprintf("Re-encrypting archive %d of %d", updReencrypt.Status.Reencrypted+updReencrypt.Status.Failed+1, updReencrypt.Status.Total))
+		}
+		updated, err = h.reencrypts.UpdateStatus(updReencrypt)
+		return err
+	})
+	if err != nil {
+		return reencrypt, err
+	}
+	return updated, nil
+}
+
+func (h *handler) setReconcilingCondition(reencrypt *v1.BackupReencrypt, originalErr error) (*v1.BackupReencrypt, error) {
+	if !v1.BackupReencryptConditionReconciling.IsUnknown(reencrypt) && v1.BackupReencryptConditionReconciling.GetReason(reencrypt) == "Error" {
+		reconcileMsg := v1.BackupReencryptConditionReconciling.GetMessage(reencrypt)
+		if strings.Contains(reconcileMsg, originalErr.Error()) || strings.EqualFold(reconcileMsg, originalErr.Error()) {
+			// no need to update object status again, because if another UpdateStatus is called without needing it, controller will
+			// process the same object immediately without its default backoff
+			return reencrypt, originalErr
+		}
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		updReencrypt, err := h.reencrypts.Get(reencrypt.Name, k8sv1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		v1.BackupReencryptConditionReconciling.SetStatusBool(updReencrypt, true)
+		v1.BackupReencryptConditionReconciling.SetError(updReencrypt, "", originalErr)
+		v1.BackupReencryptConditionReady.Message(updReencrypt, "Retrying")
+		_, err = h.reencrypts.UpdateStatus(updReencrypt)
+		return err
+	})
+	if err != nil {
+		return reencrypt, errors.New(originalErr.Error() + err.Error())
+	}
+	return reencrypt, originalErr
+}
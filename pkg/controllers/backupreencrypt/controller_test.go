@@ -0,0 +1,54 @@
+package backupreencrypt
+
+import (
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBackupReencryptSpec(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    v1.BackupReencryptSpec
+		wantErr bool
+	}{
+		{
+			name: "Valid",
+			spec: v1.BackupReencryptSpec{BackupName: "nightly", OldEncryptionConfigSecretName: "old", NewEncryptionConfigSecretName: "new"},
+		},
+		{
+			name:    "Missing backup",
+			spec:    v1.BackupReencryptSpec{OldEncryptionConfigSecretName: "old", NewEncryptionConfigSecretName: "new"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing new encryption config",
+			spec:    v1.BackupReencryptSpec{BackupName: "nightly", OldEncryptionConfigSecretName: "old"},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := validateBackupReencryptSpec(testCase.spec)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNextPendingArchive(t *testing.T) {
+	archives := []v1.ArchiveReencryption{
+		{Filename: "a", Phase: v1.ArchiveReencryptionReencrypted},
+		{Filename: "b", Phase: v1.ArchiveReencryptionFailed},
+		{Filename: "c", Phase: v1.ArchiveReencryptionPending},
+		{Filename: "d", Phase: v1.ArchiveReencryptionPending},
+	}
+
+	assert.Equal(t, 2, nextPendingArchive(archives))
+	assert.Equal(t, -1, nextPendingArchive(archives[:2]))
+}
@@ -0,0 +1,136 @@
+package backupreencrypt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/objectstore"
+	"github.com/rancher/backup-restore-operator/pkg/storage"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// storageLocation returns the storage location of the archives to re-encrypt: the BackupReencrypt's own, otherwise the
+// one of its Backup. Nil means the operator's default storage location.
+func (h *handler) storageLocation(reencrypt *v1.BackupReencrypt) (*v1.StorageLocation, error) {
+	if reencrypt.Spec.StorageLocation != nil {
+		return reencrypt.Spec.StorageLocation, nil
+	}
+	backup, err := h.backups.Get(reencrypt.Spec.BackupName, k8sv1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting Backup %v: %w", reencrypt.Spec.BackupName, err)
+	}
+	return backup.Spec.StorageLocation, nil
+}
+
+// objectStore returns the S3 object store of the location, nil when the archives are in the default mount path
+func (h *handler) objectStore(location *v1.StorageLocation) (*v1.S3ObjectStore, error) {
+	if location != nil {
+		if location.S3 == nil {
+			return nil, fmt.Errorf("storage location has no S3 details")
+		}
+		return location.S3, nil
+	}
+	if h.defaultBackupMountPath != "" {
+		return nil, nil
+	}
+	if h.defaultS3BackupLocation == nil {
+		return nil, fmt.Errorf("backup location not specified on the Backup, and not configured at the operator level")
+	}
+	return h.defaultS3BackupLocation, nil
+}
+
+func (h *handler) listArchives(location *v1.StorageLocation) ([]storage.Archive, error) {
+	objectStore, err := h.objectStore(location)
+	if err != nil {
+		return nil, err
+	}
+	if objectStore == nil {
+		return storage.ListMountPath(h.defaultBackupMountPath)
+	}
+	s3Client, err := objectstore.GetS3Client(h.ctx, objectStore, h.dynamicClient)
+	if err != nil {
+		return nil, err
+	}
+	return storage.ListS3(h.ctx, s3Client, objectStore)
+}
+
+// fetchArchive returns a local path of the archive, and a function removing it once it is no longer needed
+func (h *handler) fetchArchive(location *v1.StorageLocation, filename string) (string, func(), error) {
+	objectStore, err := h.objectStore(location)
+	if err != nil {
+		return "", nil, err
+	}
+	if objectStore == nil {
+		return filepath.Join(h.defaultBackupMountPath, filename), func() {}, nil
+	}
+	s3Client, err := objectstore.GetS3Client(h.ctx, objectStore, h.dynamicClient)
+	if err != nil {
+		return "", nil, err
+	}
+	sse, err := objectstore.GetServerSideEncryption(h.ctx, objectStore, h.dynamicClient)
+	if err != nil {
+		return "", nil, err
+	}
+	path, err := objectstore.DownloadFromS3WithPrefix(s3Client, storage.ObjectKey(objectStore, filename), objectStore.BucketName, sse)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, func() { os.Remove(path) }, nil
+}
+
+// storeArchive replaces the stored archive with the file at path
+func (h *handler) storeArchive(location *v1.StorageLocation, filename, path string) error {
+	objectStore, err := h.objectStore(location)
+	if err != nil {
+		return err
+	}
+	if objectStore == nil {
+		return replaceFile(filepath.Join(h.defaultBackupMountPath, filename), path)
+	}
+	s3Client, err := objectstore.GetS3Client(h.ctx, objectStore, h.dynamicClient)
+	if err != nil {
+		return err
+	}
+	uploadOptions, err := objectstore.NewUploadOptions(h.ctx, objectStore, h.dynamicClient)
+	if err != nil {
+		return err
+	}
+	// object lock keeps the previous version of the archive, the re-encrypted version is locked for retainFor when set,
+	// otherwise the bucket's default retention applies
+	if lock := objectStore.ObjectLock; lock != nil {
+		uploadOptions.LegalHold = lock.LegalHold
+		if lock.Mode != "" && lock.RetainFor != nil {
+			uploadOptions.RetentionMode = minio.RetentionMode(lock.Mode)
+			uploadOptions.RetainUntil = time.Now().Add(lock.RetainFor.Duration)
+		}
+	}
+	return objectstore.UploadBackupFile(s3Client, objectStore.BucketName, storage.ObjectKey(objectStore, filename), path, uploadOptions)
+}
+
+// replaceFile atomically replaces target with a copy of source, which can be on another file system
+func replaceFile(target, source string) error {
+	src, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmpPath := target + ".tmp"
+	dst, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	defer dst.Close()
+	if _, err := io.Copy(dst, src); err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, target)
+}
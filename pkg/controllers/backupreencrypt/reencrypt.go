@@ -0,0 +1,185 @@
+package backupreencrypt
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/archiveformat"
+	"github.com/rancher/backup-restore-operator/pkg/util/archiveencryption"
+	"github.com/rancher/backup-restore-operator/pkg/util/compression"
+	k8sEncryptionconfig "k8s.io/apiserver/pkg/server/options/encryptionconfig"
+	"k8s.io/apiserver/pkg/storage/value"
+)
+
+// reencryptArchive copies the archive read from src to dst, decrypting its resources with oldTransformers and encrypting
+// them with newTransformers, the same way the backup controller encrypts resources. Archives encrypted as a whole are
+// decrypted with the identities of archiveKeys and encrypted again to its recipients. The archive is compressed again
+// with its own algorithm, at the default level. It returns the number of resources in the archive.
+func reencryptArchive(ctx context.Context, src io.Reader, dst io.Writer, oldTransformers, newTransformers k8sEncryptionconfig.StaticTransformers,
+	archiveKeys *archiveencryption.Keys) (int, error) {
+	archive, sealed, err := openArchive(src, archiveKeys)
+	if err != nil {
+		return 0, err
+	}
+	br := bufio.NewReader(archive)
+	algorithm, err := compression.Detect(br)
+	if err != nil {
+		return 0, err
+	}
+
+	out := dst
+	var sealedWriter io.WriteCloser
+	if sealed {
+		if sealedWriter, err = archiveKeys.Encrypt(dst); err != nil {
+			return 0, err
+		}
+		out = sealedWriter
+	}
+	gw, err := compression.NewWriter(out, &v1.Compression{Algorithm: algorithm})
+	if err != nil {
+		return 0, err
+	}
+	tw := tar.NewWriter(gw)
+
+	count := 0
+	err = walkArchive(br, func(entry *archiveformat.Entry) error {
+		hdr, data := entry.Header, entry.Data
+		if resource := entry.Resource; resource != nil {
+			gr, aad := resource.GVR.GroupResource(), resource.AdditionalAuthenticatedData()
+			plaintext, err := decryptResource(ctx, data, oldTransformers.TransformerForResource(gr), aad)
+			if err != nil {
+				return fmt.Errorf("error decrypting %v: %v", hdr.Name, err)
+			}
+			if data, err = encryptResource(ctx, plaintext, newTransformers.TransformerForResource(gr), aad); err != nil {
+				return fmt.Errorf("error encrypting %v: %v", hdr.Name, err)
+			}
+			count++
+		}
+		hdr.Size = int64(len(data))
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	})
+	if err != nil {
+		return count, err
+	}
+	if err := tw.Close(); err != nil {
+		return count, err
+	}
+	if err := gw.Close(); err != nil {
+		return count, err
+	}
+	if sealedWriter != nil {
+		return count, sealedWriter.Close()
+	}
+	return count, nil
+}
+
+// verifyArchive checks that every resource of the archive can be decrypted with transformers, and returns their number
+func verifyArchive(ctx context.Context, src io.Reader, transformers k8sEncryptionconfig.StaticTransformers, archiveKeys *archiveencryption.Keys) (int, error) {
+	archive, _, err := openArchive(src, archiveKeys)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = walkArchive(archive, func(entry *archiveformat.Entry) error {
+		resource := entry.Resource
+		if resource == nil {
+			return nil
+		}
+		plaintext, err := decryptResource(ctx, entry.Data, transformers.TransformerForResource(resource.GVR.GroupResource()), resource.AdditionalAuthenticatedData())
+		if err != nil {
+			return fmt.Errorf("error decrypting %v: %v", entry.Header.Name, err)
+		}
+		if !json.Valid(plaintext) {
+			return fmt.Errorf("resource %v is not valid JSON after decryption", entry.Header.Name)
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// openArchive returns the compressed stream of the archive, decrypted when it is encrypted as a whole
+func openArchive(src io.Reader, archiveKeys *archiveencryption.Keys) (io.Reader, bool, error) {
+	br := bufio.NewReader(src)
+	if !archiveencryption.IsEncrypted(br) {
+		return br, false, nil
+	}
+	if archiveKeys == nil {
+		return nil, true, fmt.Errorf("archive is encrypted, archiveEncryptionSecretName must be set to decrypt it")
+	}
+	decrypted, err := archiveKeys.Decrypt(br)
+	if err != nil {
+		return nil, true, fmt.Errorf("error decrypting archive: %v", err)
+	}
+	return decrypted, true, nil
+}
+
+// walkArchive calls fn with each entry of the archive, in order, with its resource parsed according to the format of
+// the archive
+func walkArchive(archive io.Reader, fn func(entry *archiveformat.Entry) error) error {
+	decompressed, _, err := compression.NewReader(archive)
+	if err != nil {
+		return err
+	}
+	defer decompressed.Close()
+	reader, err := archiveformat.NewReader(decompressed)
+	if err != nil {
+		return err
+	}
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// decryptResource returns the JSON of a resource file, encrypted resources are stored as a JSON string of their ciphertext
+func decryptResource(ctx context.Context, data []byte, transformer value.Transformer, additionalAuthenticatedData string) ([]byte, error) {
+	if len(data) == 0 || data[0] != '"' {
+		return data, nil
+	}
+	var encryptedBytes []byte
+	if err := json.Unmarshal(data, &encryptedBytes); err != nil {
+		return nil, fmt.Errorf("error unmarshaling encrypted data: %v", err)
+	}
+	if transformer == nil {
+		return nil, fmt.Errorf("resource is encrypted but not covered by the encryption config")
+	}
+	decrypted, _, err := transformer.TransformFromStorage(ctx, encryptedBytes, value.DefaultContext(additionalAuthenticatedData))
+	if err != nil {
+		return nil, err
+	}
+	return decrypted, nil
+}
+
+// encryptResource encrypts the JSON of a resource the same way as the backup controller
+func encryptResource(ctx context.Context, plaintext []byte, transformer value.Transformer, additionalAuthenticatedData string) ([]byte, error) {
+	if transformer == nil {
+		return plaintext, nil
+	}
+	encrypted, err := transformer.TransformToStorage(ctx, plaintext, value.DefaultContext(additionalAuthenticatedData))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(plaintext, encrypted) {
+		return plaintext, nil
+	}
+	return json.Marshal(encrypted)
+}
@@ -0,0 +1,208 @@
+package backupreencrypt
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/archiveformat"
+	"github.com/rancher/backup-restore-operator/pkg/util/archiveencryption"
+	"github.com/rancher/backup-restore-operator/pkg/util/compression"
+	"github.com/rancher/backup-restore-operator/pkg/util/encryptionconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sEncryptionconfig "k8s.io/apiserver/pkg/server/options/encryptionconfig"
+)
+
+const (
+	oldEncryptionConfig = `apiVersion: apiserver.config.k8s.io/v1
+kind: EncryptionConfiguration
+resources:
+  - resources:
+      - secrets
+    providers:
+      - secretbox:
+          keys:
+            - name: old
+              secret: YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXoxMjM0NTY=
+`
+	newEncryptionConfig = `apiVersion: apiserver.config.k8s.io/v1
+kind: EncryptionConfiguration
+resources:
+  - resources:
+      - secrets
+      - configmaps
+    providers:
+      - aescbc:
+          keys:
+            - name: new
+              secret: MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=
+`
+	secretJSON    = `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"creds","namespace":"cattle-system"}}`
+	configMapJSON = `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings","namespace":"cattle-system"}}`
+	namespaceJSON = `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"cattle-system"}}`
+)
+
+func loadTransformers(t *testing.T, config string) k8sEncryptionconfig.StaticTransformers {
+	path := filepath.Join(t.TempDir(), encryptionconfig.EncryptionProviderConfigKey)
+	require.NoError(t, os.WriteFile(path, []byte(config), 0600))
+	transformers, err := encryptionconfig.PrepareEncryptionTransformersFromConfig(context.Background(), path)
+	require.NoError(t, err)
+	return transformers
+}
+
+// newArchive returns an archive laid out like the ones of the backup controller, with resources encrypted by transformers
+func newArchive(t *testing.T, transformers k8sEncryptionconfig.StaticTransformers) []byte {
+	ctx := context.Background()
+	files := []struct {
+		path    string
+		content string
+		gr      schema.GroupResource
+		aad     string
+	}{
+		{"filters/filters.json", `{"resourceSelectors":[]}`, schema.GroupResource{}, ""},
+		{"secrets.#v1/cattle-system/creds.json", secretJSON, schema.GroupResource{Resource: "secrets"}, "cattle-system#creds"},
+		{"configmaps.#v1/cattle-system/settings.json", configMapJSON, schema.GroupResource{Resource: "configmaps"}, "cattle-system#settings"},
+		{"namespaces.#v1/cattle-system.json", namespaceJSON, schema.GroupResource{Resource: "namespaces"}, "cattle-system"},
+	}
+	var archive bytes.Buffer
+	gw := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gw)
+	for _, file := range files {
+		content := []byte(file.content)
+		if file.aad != "" {
+			var err error
+			content, err = encryptResource(ctx, content, transformers.TransformerForResource(file.gr), file.aad)
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: file.path, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return archive.Bytes()
+}
+
+// readResources returns the decrypted resources of an archive by path
+func readResources(t *testing.T, archive []byte, transformers k8sEncryptionconfig.StaticTransformers) map[string]string {
+	resources := make(map[string]string)
+	err := walkArchive(bytes.NewReader(archive), func(entry *archiveformat.Entry) error {
+		if entry.Resource == nil {
+			return nil
+		}
+		transformer := transformers.TransformerForResource(entry.Resource.GVR.GroupResource())
+		plaintext, err := decryptResource(context.Background(), entry.Data, transformer, entry.Resource.AdditionalAuthenticatedData())
+		require.NoError(t, err)
+		resources[entry.Header.Name] = string(plaintext)
+		return nil
+	})
+	require.NoError(t, err)
+	return resources
+}
+
+func TestReencryptArchive(t *testing.T) {
+	ctx := context.Background()
+	oldTransformers, newTransformers := loadTransformers(t, oldEncryptionConfig), loadTransformers(t, newEncryptionConfig)
+	archive := newArchive(t, oldTransformers)
+
+	var reencrypted bytes.Buffer
+	count, err := reencryptArchive(ctx, bytes.NewReader(archive), &reencrypted, oldTransformers, newTransformers, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	verified, err := verifyArchive(ctx, bytes.NewReader(reencrypted.Bytes()), newTransformers, nil)
+	require.NoError(t, err)
+	assert.Equal(t, count, verified)
+	_, err = verifyArchive(ctx, bytes.NewReader(reencrypted.Bytes()), oldTransformers, nil)
+	assert.Error(t, err, "the old encryption config can no longer decrypt the archive")
+
+	assert.Equal(t, map[string]string{
+		"secrets.#v1/cattle-system/creds.json":       secretJSON,
+		"configmaps.#v1/cattle-system/settings.json": configMapJSON,
+		"namespaces.#v1/cattle-system.json":          namespaceJSON,
+	}, readResources(t, reencrypted.Bytes(), newTransformers))
+
+	// resources newly covered by the encryption config are encrypted, the filters are copied as they are
+	err = walkArchive(bytes.NewReader(reencrypted.Bytes()), func(entry *archiveformat.Entry) error {
+		switch entry.Header.Name {
+		case "configmaps.#v1/cattle-system/settings.json":
+			assert.Equal(t, byte('"'), entry.Data[0])
+		case "filters/filters.json":
+			assert.Equal(t, `{"resourceSelectors":[]}`, string(entry.Data))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestReencryptArchiveKeepsCompression(t *testing.T) {
+	ctx := context.Background()
+	oldTransformers, newTransformers := loadTransformers(t, oldEncryptionConfig), loadTransformers(t, newEncryptionConfig)
+	gz, _, err := compression.NewReader(bytes.NewReader(newArchive(t, oldTransformers)))
+	require.NoError(t, err)
+	var archive bytes.Buffer
+	w, err := compression.NewWriter(&archive, &v1.Compression{Algorithm: v1.ZstdCompression})
+	require.NoError(t, err)
+	_, err = io.Copy(w, gz)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	var reencrypted bytes.Buffer
+	_, err = reencryptArchive(ctx, bytes.NewReader(archive.Bytes()), &reencrypted, oldTransformers, newTransformers, nil)
+	require.NoError(t, err)
+
+	algorithm, err := compression.Detect(bufio.NewReader(bytes.NewReader(reencrypted.Bytes())))
+	require.NoError(t, err)
+	assert.Equal(t, v1.ZstdCompression, algorithm)
+	assert.Equal(t, secretJSON, readResources(t, reencrypted.Bytes(), newTransformers)["secrets.#v1/cattle-system/creds.json"])
+}
+
+func TestReencryptArchiveWithWrongOldConfig(t *testing.T) {
+	newTransformers := loadTransformers(t, newEncryptionConfig)
+	archive := newArchive(t, loadTransformers(t, oldEncryptionConfig))
+
+	_, err := reencryptArchive(context.Background(), bytes.NewReader(archive), &bytes.Buffer{}, newTransformers, newTransformers, nil)
+	assert.Error(t, err)
+}
+
+func TestReencryptSealedArchive(t *testing.T) {
+	ctx := context.Background()
+	oldTransformers, newTransformers := loadTransformers(t, oldEncryptionConfig), loadTransformers(t, newEncryptionConfig)
+	oldIdentity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	newIdentity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	var sealed bytes.Buffer
+	w, err := age.Encrypt(&sealed, oldIdentity.Recipient())
+	require.NoError(t, err)
+	_, err = w.Write(newArchive(t, oldTransformers))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, err = reencryptArchive(ctx, bytes.NewReader(sealed.Bytes()), &bytes.Buffer{}, oldTransformers, newTransformers, nil)
+	assert.Error(t, err, "sealed archives require archive keys")
+
+	// the archive keys were rotated: the archive is decrypted with the old identity and sealed to the new recipient
+	archiveKeys := &archiveencryption.Keys{
+		Recipients: []age.Recipient{newIdentity.Recipient()},
+		Identities: []age.Identity{oldIdentity, newIdentity},
+	}
+	var reencrypted bytes.Buffer
+	count, err := reencryptArchive(ctx, bytes.NewReader(sealed.Bytes()), &reencrypted, oldTransformers, newTransformers, archiveKeys)
+	require.NoError(t, err)
+
+	verified, err := verifyArchive(ctx, bytes.NewReader(reencrypted.Bytes()), newTransformers, &archiveencryption.Keys{Identities: []age.Identity{newIdentity}})
+	require.NoError(t, err)
+	assert.Equal(t, count, verified)
+}
@@ -0,0 +1,191 @@
+package resourceset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	backupControllers "github.com/rancher/backup-restore-operator/pkg/generated/controllers/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/generate"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/include"
+	v1core "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	apiextv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+type handler struct {
+	ctx             context.Context
+	resourceSets    backupControllers.ResourceSetController
+	discoveryClient discovery.DiscoveryInterface
+	dynamicClient   dynamic.Interface
+	crds            apiextv1client.CustomResourceDefinitionInterface
+	secrets         v1core.SecretController
+	// resolveInterval is how often the selectors of a ResourceSet are resolved again, the objects they select and the
+	// resources served by the cluster change without the ResourceSet changing
+	resolveInterval time.Duration
+}
+
+// Register starts the controller of ResourceSets, which validates their selectors and resolves them against the
+// cluster every resolveInterval, reporting in their status what each selector selects
+func Register(
+	ctx context.Context,
+	resourceSets backupControllers.ResourceSetController,
+	discoveryClient discovery.DiscoveryInterface,
+	dynamicInterface dynamic.Interface,
+	crds apiextv1client.CustomResourceDefinitionInterface,
+	secrets v1core.SecretController,
+	resolveInterval time.Duration) {
+
+	controller := &handler{
+		ctx:             ctx,
+		resourceSets:    resourceSets,
+		discoveryClient: discoveryClient,
+		dynamicClient:   dynamicInterface,
+		crds:            crds,
+		secrets:         secrets,
+		resolveInterval: resolveInterval,
+	}
+
+	resourceSets.OnChange(ctx, "resource-set", controller.OnResourceSetChange)
+}
+
+func (h *handler) OnResourceSetChange(_ string, resourceSet *v1.ResourceSet) (*v1.ResourceSet, error) {
+	if resourceSet == nil || resourceSet.DeletionTimestamp != nil {
+		return resourceSet, nil
+	}
+	// the status is only resolved again when the ResourceSet changed or every resolveInterval, its own updates don't
+	// trigger a new resolution
+	if resourceSet.Status.ObservedGeneration == resourceSet.Generation && resourceSet.Status.LastResolvedTimestamp != "" {
+		if resolvedAt, err := time.Parse(time.RFC3339, resourceSet.Status.LastResolvedTimestamp); err == nil {
+			if next := resolvedAt.Add(h.resolveInterval); time.Now().Before(next) {
+				h.resourceSets.EnqueueAfter(resourceSet.Name, time.Until(next))
+				return resourceSet, nil
+			}
+		}
+	}
+
+	logrus.Debugf("Resolving the selectors of ResourceSet %v", resourceSet.Name)
+	var status v1.ResourceSetStatus
+	source := generate.NewClusterSource(h.ctx, h.crds, h.secrets)
+	resolved, err := generate.Resolve(resourceSet, source)
+	if err == nil {
+		resolved, err = include.Resolve(resolved, func(name string) (*v1.ResourceSet, error) {
+			included, err := h.resourceSets.Get(name, k8sv1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return generate.Resolve(included, source)
+		})
+	}
+	if err != nil {
+		status = statusForError(err)
+	} else {
+		rh := resourcesets.ResourceHandler{
+			DiscoveryClient: h.discoveryClient,
+			DynamicClient:   h.dynamicClient,
+			Ctx:             h.ctx,
+		}
+		coverages, err := rh.Coverage(h.ctx, resolved.ResourceSelectors, resolved.ExcludeSelectors)
+		if err != nil {
+			status = statusForError(err)
+		} else {
+			status = statusFromCoverage(coverages)
+		}
+	}
+
+	updated, err := h.updateStatus(resourceSet, status)
+	if err != nil {
+		return resourceSet, err
+	}
+	h.resourceSets.EnqueueAfter(resourceSet.Name, h.resolveInterval)
+	return updated, nil
+}
+
+// statusForError returns the status of a ResourceSet whose selectors could not be resolved
+func statusForError(err error) v1.ResourceSetStatus {
+	var status v1.ResourceSetStatus
+	v1.ResourceSetConditionReady.SetError(&status, "", err)
+	return status
+}
+
+// statusFromCoverage returns the status of a ResourceSet from the coverage of its selectors. It is Ready when every
+// selector is valid and was resolved.
+func statusFromCoverage(coverages []resourcesets.SelectorCoverage) v1.ResourceSetStatus {
+	status := v1.ResourceSetStatus{Selectors: make([]v1.ResourceSelectorStatus, 0, len(coverages))}
+	var selectorErrors []string
+	for i, coverage := range coverages {
+		selectorStatus := v1.ResourceSelectorStatus{UnknownAPIVersions: coverage.UnknownGroupVersions}
+		for gvResource, count := range coverage.ObjectCounts {
+			selectorStatus.Resources = append(selectorStatus.Resources, v1.SelectedResource{
+				APIVersion:  gvResource.GroupVersion.String(),
+				Resource:    gvResource.Name,
+				ObjectCount: count,
+			})
+			selectorStatus.ObjectCount += count
+		}
+		sort.Slice(selectorStatus.Resources, func(i, j int) bool {
+			a, b := selectorStatus.Resources[i], selectorStatus.Resources[j]
+			return a.APIVersion < b.APIVersion || (a.APIVersion == b.APIVersion && a.Resource < b.Resource)
+		})
+		for gvResource, reason := range coverage.SkippedResources {
+			selectorStatus.SkippedResources = append(selectorStatus.SkippedResources, v1.SkippedResource{
+				APIVersion: gvResource.GroupVersion.String(),
+				Resource:   gvResource.Name,
+				Reason:     reason,
+			})
+		}
+		sort.Slice(selectorStatus.SkippedResources, func(i, j int) bool {
+			a, b := selectorStatus.SkippedResources[i], selectorStatus.SkippedResources[j]
+			return a.APIVersion < b.APIVersion || (a.APIVersion == b.APIVersion && a.Resource < b.Resource)
+		})
+		if coverage.Err != nil {
+			selectorStatus.Error = coverage.Err.Error()
+			selectorErrors = append(selectorErrors, fmt.Sprintf("selector %d: %v", i+1, coverage.Err))
+		}
+		status.ObjectCount += selectorStatus.ObjectCount
+		status.Selectors = append(status.Selectors, selectorStatus)
+	}
+
+	if len(selectorErrors) > 0 {
+		v1.ResourceSetConditionReady.SetStatusBool(&status, false)
+		v1.ResourceSetConditionReady.Reason(&status, "Error")
+		v1.ResourceSetConditionReady.Message(&status, strings.Join(selectorErrors, "; "))
+	} else {
+		v1.ResourceSetConditionReady.SetStatusBool(&status, true)
+		v1.ResourceSetConditionReady.Message(&status, fmt.Sprintf("%d selectors select %d objects", len(coverages), status.ObjectCount))
+	}
+	return status
+}
+
+func (h *handler) updateStatus(resourceSet *v1.ResourceSet, status v1.ResourceSetStatus) (*v1.ResourceSet, error) {
+	var updated *v1.ResourceSet
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		updResourceSet, err := h.resourceSets.Get(resourceSet.Name, k8sv1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		// the conditions are carried over so that their transition times are kept
+		conditions := updResourceSet.Status.Conditions
+		updResourceSet.Status = status
+		updResourceSet.Status.Conditions = conditions
+		v1.ResourceSetConditionReady.SetStatus(updResourceSet, v1.ResourceSetConditionReady.GetStatus(&status))
+		v1.ResourceSetConditionReady.Reason(updResourceSet, v1.ResourceSetConditionReady.GetReason(&status))
+		v1.ResourceSetConditionReady.Message(updResourceSet, v1.ResourceSetConditionReady.GetMessage(&status))
+		updResourceSet.Status.ObservedGeneration = updResourceSet.Generation
+		updResourceSet.Status.LastResolvedTimestamp = time.Now().Format(time.RFC3339)
+		updated, err = h.resourceSets.UpdateStatus(updResourceSet)
+		return err
+	})
+	if err != nil {
+		return resourceSet, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,56 @@
+package resourceset
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestStatusFromCoverage(t *testing.T) {
+	management := schema.GroupVersion{Group: "management.cattle.io", Version: "v3"}
+	coverages := []resourcesets.SelectorCoverage{
+		{
+			ObjectCounts: map[resourcesets.GVResource]int{
+				{GroupVersion: management, Name: "users"}:    3,
+				{GroupVersion: management, Name: "clusters"}: 2,
+			},
+			SkippedResources: map[resourcesets.GVResource]string{
+				{GroupVersion: management, Name: "preferences"}: "resource can't be listed or get",
+			},
+		},
+		{UnknownGroupVersions: []string{"example.io/v1"}},
+	}
+
+	status := statusFromCoverage(coverages)
+	require.Len(t, status.Selectors, 2)
+	assert.Equal(t, []v1.SelectedResource{
+		{APIVersion: "management.cattle.io/v3", Resource: "clusters", ObjectCount: 2},
+		{APIVersion: "management.cattle.io/v3", Resource: "users", ObjectCount: 3},
+	}, status.Selectors[0].Resources)
+	assert.Equal(t, 5, status.Selectors[0].ObjectCount)
+	assert.Equal(t, []v1.SkippedResource{
+		{APIVersion: "management.cattle.io/v3", Resource: "preferences", Reason: "resource can't be listed or get"},
+	}, status.Selectors[0].SkippedResources)
+	assert.Equal(t, []string{"example.io/v1"}, status.Selectors[1].UnknownAPIVersions)
+	assert.Equal(t, 5, status.ObjectCount)
+	assert.True(t, v1.ResourceSetConditionReady.IsTrue(&status))
+
+	coverages = append(coverages, resourcesets.SelectorCoverage{Err: fmt.Errorf("kindsRegexp: missing closing )")})
+	status = statusFromCoverage(coverages)
+	require.Len(t, status.Selectors, 3)
+	assert.Equal(t, "kindsRegexp: missing closing )", status.Selectors[2].Error)
+	assert.True(t, v1.ResourceSetConditionReady.IsFalse(&status))
+	assert.Contains(t, v1.ResourceSetConditionReady.GetMessage(&status), "selector 3")
+}
+
+func TestStatusForError(t *testing.T) {
+	status := statusForError(fmt.Errorf("ResourceSet team includes itself: team -> team"))
+	assert.True(t, v1.ResourceSetConditionReady.IsFalse(&status))
+	assert.Equal(t, "ResourceSet team includes itself: team -> team", v1.ResourceSetConditionReady.GetMessage(&status))
+	assert.Empty(t, status.Selectors)
+}
@@ -10,8 +10,11 @@ import (
 	"time"
 
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/archiveformat"
 	restoreControllers "github.com/rancher/backup-restore-operator/pkg/generated/controllers/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets"
 	"github.com/rancher/backup-restore-operator/pkg/util"
+	"github.com/rancher/backup-restore-operator/pkg/util/archiveencryption"
 	"github.com/rancher/backup-restore-operator/pkg/util/encryptionconfig"
 	lasso "github.com/rancher/lasso/pkg/client"
 	v1core "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
@@ -71,8 +74,10 @@ type ObjectsFromBackupCR struct {
 	crdInfoToData                   map[objInfo]unstructured.Unstructured
 	clusterscopedResourceInfoToData map[objInfo]unstructured.Unstructured
 	namespacedResourceInfoToData    map[objInfo]unstructured.Unstructured
-	resourcesFromBackup             map[string]bool
+	resourcesFromBackup             map[resourcesets.ObjectKey]bool
 	backupResourceSet               v1.ResourceSet
+	// layout of the backup file, resources are identified by the path of their file in it
+	layout archiveformat.Layout
 }
 
 type objInfo struct {
@@ -146,9 +151,27 @@ func (h *handler) OnRestoreChange(_ string, restore *v1.Restore) (*v1.Restore, e
 	defer h.Unlock(*leaseHolderName(restore))
 
 	logrus.Infof("Processing Restore CR %v", restore.Name)
-	var backupSource string
+	if err := ValidateBackupSelection(restore.Spec); err != nil {
+		return h.setReconcilingCondition(restore, err)
+	}
+	backupLocation, err := h.restoreStorageLocation(restore)
+	if err != nil {
+		return h.setReconcilingCondition(restore, err)
+	}
 	backupName := restore.Spec.BackupFilename
-	logrus.Infof("Restoring from backup %v", restore.Spec.BackupFilename)
+	if backupName == "" {
+		backupName = selectedBackupFilename(restore)
+	}
+	if backupName == "" {
+		if backupName, err = h.selectBackupFilename(restore, backupLocation); err != nil {
+			return h.setReconcilingCondition(restore, err)
+		}
+		// the selected backup is recorded before restoring, so that a retried restore doesn't select a newer backup
+		logrus.Infof("Selected backup %v for restore CR %v", backupName, restore.Name)
+		return h.setBackupFilename(restore, backupName)
+	}
+	var backupSource string
+	logrus.Infof("Restoring from backup %v", backupName)
 
 	created := make(map[string]bool)
 	ownerToDependentsList := make(map[string][]restoreObj)
@@ -159,12 +182,11 @@ func (h *handler) OnRestoreChange(_ string, restore *v1.Restore) (*v1.Restore, e
 		crdInfoToData:                   make(map[objInfo]unstructured.Unstructured),
 		clusterscopedResourceInfoToData: make(map[objInfo]unstructured.Unstructured),
 		namespacedResourceInfoToData:    make(map[objInfo]unstructured.Unstructured),
-		resourcesFromBackup:             make(map[string]bool),
+		resourcesFromBackup:             make(map[resourcesets.ObjectKey]bool),
 		backupResourceSet:               v1.ResourceSet{},
 	}
 
 	transformerMap := k8sEncryptionconfig.StaticTransformers{}
-	var err error
 	if restore.Spec.EncryptionConfigSecretName != "" {
 		logrus.Infof("Processing encryption config %v for restore CR %v", restore.Spec.EncryptionConfigSecretName, restore.Name)
 		encryptionConfigSecret, err := encryptionconfig.GetEncryptionConfigSecret(h.secrets, restore.Spec.EncryptionConfigSecretName)
@@ -180,15 +202,29 @@ func (h *handler) OnRestoreChange(_ string, restore *v1.Restore) (*v1.Restore, e
 		}
 	}
 
-	backupLocation := restore.Spec.StorageLocation
+	var archiveKeys *archiveencryption.Keys
+	if restore.Spec.ArchiveEncryptionSecretName != "" {
+		logrus.Infof("Processing archive encryption secret %v for restore CR %v", restore.Spec.ArchiveEncryptionSecretName, restore.Name)
+		archiveEncryptionSecret, err := archiveencryption.GetArchiveEncryptionSecret(h.secrets, restore.Spec.ArchiveEncryptionSecretName)
+		if err != nil {
+			logrus.Errorf("Error fetching archive encryption secret: %v", err)
+			return h.setReconcilingCondition(restore, err)
+		}
+		archiveKeys, err = archiveencryption.GetKeysFromSecret(h.ctx, archiveEncryptionSecret)
+		if err != nil {
+			logrus.Errorf("Error processing archive encryption secret: %v", err)
+			return h.setReconcilingCondition(restore, err)
+		}
+	}
+
 	var foundBackup bool
 	if backupLocation == nil {
 		if h.defaultS3BackupLocation != nil {
-			backupFilePath, err := h.downloadFromS3(restore, h.defaultS3BackupLocation)
+			backupFilePath, err := h.downloadFromS3(backupName, h.defaultS3BackupLocation)
 			if err != nil {
 				return h.setReconcilingCondition(restore, err)
 			}
-			if err = h.LoadFromTarGzip(backupFilePath, transformerMap, &objFromBackupCR); err != nil {
+			if err = h.LoadFromTarGzip(backupFilePath, transformerMap, archiveKeys, &objFromBackupCR); err != nil {
 				return h.setReconcilingCondition(restore, err)
 			}
 			// remove the downloaded gzip file from s3
@@ -200,18 +236,18 @@ func (h *handler) OnRestoreChange(_ string, restore *v1.Restore) (*v1.Restore, e
 			backupSource = util.S3Backup
 		} else if h.defaultBackupMountPath != "" {
 			backupFilePath := filepath.Join(h.defaultBackupMountPath, backupName)
-			if err = h.LoadFromTarGzip(backupFilePath, transformerMap, &objFromBackupCR); err != nil {
+			if err = h.LoadFromTarGzip(backupFilePath, transformerMap, archiveKeys, &objFromBackupCR); err != nil {
 				return h.setReconcilingCondition(restore, err)
 			}
 			foundBackup = true
 			backupSource = util.PVBackup
 		}
 	} else if backupLocation.S3 != nil {
-		backupFilePath, err := h.downloadFromS3(restore, restore.Spec.StorageLocation.S3)
+		backupFilePath, err := h.downloadFromS3(backupName, backupLocation.S3)
 		if err != nil {
 			return h.setReconcilingCondition(restore, err)
 		}
-		if err = h.LoadFromTarGzip(backupFilePath, transformerMap, &objFromBackupCR); err != nil {
+		if err = h.LoadFromTarGzip(backupFilePath, transformerMap, archiveKeys, &objFromBackupCR); err != nil {
 			return h.setReconcilingCondition(restore, err)
 		}
 		// remove the downloaded gzip file from s3
@@ -272,7 +308,7 @@ func (h *handler) OnRestoreChange(_ string, restore *v1.Restore) (*v1.Restore, e
 	// prune by default
 	if restore.Spec.GetPrune() {
 		logrus.Infof("Pruning resources that are not part of the backup for restore CR %v", restore.Name)
-		if err := h.prune(objFromBackupCR.backupResourceSet.ResourceSelectors, transformerMap, objFromBackupCR, restore.Spec.DeleteTimeoutSeconds); err != nil {
+		if err := h.prune(objFromBackupCR.backupResourceSet.ResourceSelectors, objFromBackupCR.backupResourceSet.ExcludeSelectors, transformerMap, objFromBackupCR, restore.Spec.DeleteTimeoutSeconds); err != nil {
 			h.scaleUpControllersFromResourceSet(objFromBackupCR)
 			return h.setReconcilingCondition(restore, fmt.Errorf("error pruning during restore: %v", err))
 		}
@@ -293,6 +329,7 @@ func (h *handler) OnRestoreChange(_ string, restore *v1.Restore) (*v1.Restore, e
 		restore.Status.RestoreCompletionTS = time.Now().Format(time.RFC3339)
 		restore.Status.ObservedGeneration = restore.Generation
 		restore.Status.BackupSource = backupSource
+		restore.Status.BackupFilename = backupName
 		_, err = h.restores.UpdateStatus(restore)
 		return err
 	})
@@ -468,22 +505,12 @@ func (h *handler) generateDependencyGraph(ownerToDependentsList map[string][]res
 				continue
 			}
 
-			var apiGroup, version string
-			split := strings.SplitN(groupVersion, "/", 2)
-			if len(split) == 1 {
-				// resources under v1 version
-				version = split[0]
-			} else {
-				apiGroup = split[0]
-				version = split[1]
-			}
-			// kind + "." + apigroup + "#" + version
-			ownerDirPath := fmt.Sprintf("%s.%s#%s", ownerGVR.Resource, apiGroup, version)
 			ownerName := ownerRefData["name"].(string)
+			ownerResource := archiveformat.Resource{GVR: gv.WithResource(ownerGVR.Resource), Name: ownerName}
 			// Store resourceConfigPath of owner Ref because that's what we check for in "Created" map
 			ownerObj := restoreObj{
 				Name:               ownerName,
-				ResourceConfigPath: filepath.Join(ownerDirPath, ownerName+".json"),
+				ResourceConfigPath: objFromBackupCR.layout.Path(ownerResource),
 				GVR:                ownerGVR,
 			}
 			// If we are generating graph for the namespaced resources, and the ownerRef is clusterscoped, it should have been created by now
@@ -496,10 +523,10 @@ func (h *handler) generateDependencyGraph(ownerToDependentsList map[string][]res
 			if isOwnerNamespaced {
 				// if owner object is namespaced, then it has to be the same ns as the current dependent object as per k8s design
 				ownerObj.Namespace = currRestoreObj.Namespace
-				// the owner object's resourceFile in backup would also have namespace in the filename, so update
-				// ownerObj.ResourceConfigPath to include namespace subdir before the filename for owner
-				ownerFilename := filepath.Join(currRestoreObj.Namespace, ownerName+".json")
-				ownerObj.ResourceConfigPath = filepath.Join(ownerDirPath, ownerFilename)
+				// the owner object's resourceFile in backup would also have namespace in its path, so update
+				// ownerObj.ResourceConfigPath to the path of the namespaced owner
+				ownerResource.Namespace = currRestoreObj.Namespace
+				ownerObj.ResourceConfigPath = objFromBackupCR.layout.Path(ownerResource)
 			}
 			ownerObjDependents, ok := ownerToDependentsList[ownerObj.ResourceConfigPath]
 			if !ok {
@@ -814,19 +841,21 @@ func (h *handler) getOwnerNewUID(owner *restoreObj) (string, error) {
 	return ownerObjUID, nil
 }
 
-// getGVR parses the directory path to provide groupVersionResource
-func getGVR(resourceGVR string) schema.GroupVersionResource {
-	gvkParts := strings.Split(resourceGVR, "#")
-	version := gvkParts[1]
-	resourceGroup := strings.SplitN(gvkParts[0], ".", 2)
-	resource := strings.TrimSuffix(resourceGroup[0], ".")
-	var group string
-	if len(resourceGroup) > 1 {
-		group = resourceGroup[1]
-	}
-	gr := schema.ParseGroupResource(resource + "." + group)
-	gvr := gr.WithVersion(version)
-	return gvr
+// setBackupFilename records the filename of the backup selected for the restore in its status, along with the generation
+// of the restore it was selected for
+func (h *handler) setBackupFilename(restore *v1.Restore, backupFilename string) (*v1.Restore, error) {
+	generation := restore.Generation
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		updRestore, err := h.restores.Get(restore.Name, k8sv1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		updRestore.Status.BackupFilename = backupFilename
+		updRestore.Status.BackupFilenameGeneration = generation
+		restore, err = h.restores.UpdateStatus(updRestore)
+		return err
+	})
+	return restore, err
 }
 
 // https://github.com/kubernetes-sigs/cli-utils/tree/master/pkg/kstatus
@@ -1,28 +1,32 @@
 package restore
 
 import (
-	"archive/tar"
-	"compress/gzip"
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/archiveformat"
 	"github.com/rancher/backup-restore-operator/pkg/objectstore"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets"
+	"github.com/rancher/backup-restore-operator/pkg/util/archiveencryption"
+	"github.com/rancher/backup-restore-operator/pkg/util/compression"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	k8sEncryptionconfig "k8s.io/apiserver/pkg/server/options/encryptionconfig"
 	"k8s.io/apiserver/pkg/storage/value"
 )
 
-func (h *handler) downloadFromS3(restore *v1.Restore, objStore *v1.S3ObjectStore) (string, error) {
+func (h *handler) downloadFromS3(backupFilename string, objStore *v1.S3ObjectStore) (string, error) {
 	s3Client, err := objectstore.GetS3Client(h.ctx, objStore, h.dynamicClient)
 	if err != nil {
 		return "", err
 	}
-	prefix := restore.Spec.BackupFilename
+	prefix := backupFilename
 	if len(prefix) == 0 {
 		return "", fmt.Errorf("empty backup name")
 	}
@@ -31,7 +35,11 @@ func (h *handler) downloadFromS3(restore *v1.Restore, objStore *v1.S3ObjectStore
 		// remove the trailing / from the folder name
 		prefix = fmt.Sprintf("%s/%s", strings.TrimSuffix(folder, "/"), prefix)
 	}
-	targetFileLocation, err := objectstore.DownloadFromS3WithPrefix(s3Client, prefix, objStore.BucketName)
+	sse, err := objectstore.GetServerSideEncryption(h.ctx, objStore, h.dynamicClient)
+	if err != nil {
+		return "", err
+	}
+	targetFileLocation, err := objectstore.DownloadFromS3WithPrefix(s3Client, prefix, objStore.BucketName, sse)
 	if err != nil {
 		return "", err
 	}
@@ -40,69 +48,65 @@ func (h *handler) downloadFromS3(restore *v1.Restore, objStore *v1.S3ObjectStore
 
 // very initial parts: https://medium.com/@skdomino/taring-untaring-files-in-go-6b07cf56bc07
 func (h *handler) LoadFromTarGzip(tarGzFilePath string, transformerMap k8sEncryptionconfig.StaticTransformers,
-	cr *ObjectsFromBackupCR) error {
+	archiveKeys *archiveencryption.Keys, cr *ObjectsFromBackupCR) error {
 	r, err := os.Open(tarGzFilePath)
 	if err != nil {
 		return fmt.Errorf("error opening tarball backup file %v", err)
 	}
+	defer r.Close()
+
+	br := bufio.NewReader(r)
+	var archive io.Reader = br
+	// archives encrypted as a whole are detected by their header rather than their filename
+	if archiveencryption.IsEncrypted(br) {
+		if archiveKeys == nil {
+			return fmt.Errorf("backup file %v is encrypted, archiveEncryptionSecretName must be set to decrypt it", filepath.Base(tarGzFilePath))
+		}
+		if archive, err = archiveKeys.Decrypt(br); err != nil {
+			return fmt.Errorf("error decrypting backup file %v: %v", filepath.Base(tarGzFilePath), err)
+		}
+	}
 
-	gz, err := gzip.NewReader(r)
+	// the compression of the archive is detected by its magic bytes rather than its extension
+	decompressed, _, err := compression.NewReader(archive)
 	if err != nil {
-		return err
+		return fmt.Errorf("error reading backup file %v: %v", filepath.Base(tarGzFilePath), err)
 	}
-	tarball := tar.NewReader(gz)
+	defer decompressed.Close()
+	reader, err := archiveformat.NewReader(decompressed)
+	if err != nil {
+		return fmt.Errorf("error reading backup file %v: %v", filepath.Base(tarGzFilePath), err)
+	}
+	logrus.Infof("Backup file %v has format version %v", filepath.Base(tarGzFilePath), reader.Format().Version)
+	cr.layout = reader.Layout()
 
 	for {
-		tarContent, err := tarball.Next()
+		entry, err := reader.Next()
 		if err == io.EOF {
 			return nil
 		}
 		if err != nil {
 			return err
 		}
-		if tarContent.Typeflag != tar.TypeReg {
+		if entry.Header.Name == archiveformat.FiltersPath {
+			if err := json.Unmarshal(entry.Data, &cr.backupResourceSet); err != nil {
+				return fmt.Errorf("error unmarshaling backup filters file: %v", err)
+			}
 			continue
 		}
-		readData, err := io.ReadAll(tarball)
-		if err != nil {
-			return err
-		}
-		if strings.Contains(tarContent.Name, "filters") {
-			if strings.Contains(tarContent.Name, "filters.json") {
-				if err := json.Unmarshal(readData, &cr.backupResourceSet); err != nil {
-					return fmt.Errorf("error unmarshaling backup filters file: %v", err)
-				}
-			}
+		if entry.Resource == nil {
 			continue
 		}
-
-		// tarContent.Name = serviceaccounts.#v1/cattle-system/cattle.json OR users.management.cattle.io#v3/u-lqx8j.json
-		err = h.loadDataFromFile(tarContent, readData, transformerMap, cr)
-		if err != nil {
+		if err := h.loadDataFromFile(entry, transformerMap, cr); err != nil {
 			return err
 		}
 	}
 }
 
-func (h *handler) loadDataFromFile(tarContent *tar.Header, readData []byte,
-	transformerMap k8sEncryptionconfig.StaticTransformers, cr *ObjectsFromBackupCR) error {
-	var name, namespace, additionalAuthenticatedData string
-
-	cr.resourcesFromBackup[tarContent.Name] = true
-	splitPath := strings.Split(tarContent.Name, "/")
-	if len(splitPath) == 2 {
-		// cluster scoped resource, since no subdir for namespace
-		name = strings.TrimSuffix(splitPath[1], ".json")
-		additionalAuthenticatedData = name
-	} else {
-		// namespaced resource, splitPath[0] =  serviceaccounts.#v1, splitPath[1] = namespace
-		name = strings.TrimSuffix(splitPath[2], ".json")
-		namespace = splitPath[1]
-		additionalAuthenticatedData = fmt.Sprintf("%s#%s", namespace, name)
-	}
-
-	gvrStr := splitPath[0]
-	gvr := getGVR(gvrStr)
+func (h *handler) loadDataFromFile(entry *archiveformat.Entry, transformerMap k8sEncryptionconfig.StaticTransformers, cr *ObjectsFromBackupCR) error {
+	readData := entry.Data
+	name, namespace, gvr := entry.Resource.Name, entry.Resource.Namespace, entry.Resource.GVR
+	cr.resourcesFromBackup[resourcesets.ObjectKey{GroupResource: gvr.GroupResource(), Namespace: namespace, Name: name}] = true
 
 	decryptionTransformer := transformerMap.TransformerForResource(gvr.GroupResource())
 	// TODO: determine if decryptionTransformer is ever nil after 1.32 updates...
@@ -112,7 +116,7 @@ func (h *handler) loadDataFromFile(tarContent *tar.Header, readData []byte,
 			logrus.Errorf("Error unmarshaling encrypted data for resource [%v]: %v", gvr.GroupResource(), err)
 			return fmt.Errorf("error unmarshaling encrypted data for resource [%v]: %v", gvr.GroupResource(), err)
 		}
-		decrypted, _, err := decryptionTransformer.TransformFromStorage(h.ctx, encryptedBytes, value.DefaultContext(additionalAuthenticatedData))
+		decrypted, _, err := decryptionTransformer.TransformFromStorage(h.ctx, encryptedBytes, value.DefaultContext(entry.Resource.AdditionalAuthenticatedData()))
 		if err != nil {
 			logrus.Errorf("Error decrypting encrypted resource [%v]: %v, provide same encryption config as used for backup", gvr.GroupResource(), err)
 			return fmt.Errorf("error decrypting encrypted resource [%v]: %v, provide same encryption config as used for backup", gvr.GroupResource(), err)
@@ -132,7 +136,7 @@ func (h *handler) loadDataFromFile(tarContent *tar.Header, readData []byte,
 	info := objInfo{
 		Name:       name,
 		GVR:        gvr,
-		ConfigPath: tarContent.Name,
+		ConfigPath: entry.Header.Name,
 	}
 
 	if shouldSkipBuiltin(gvr.Resource, fileMap) {
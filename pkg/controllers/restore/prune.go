@@ -1,8 +1,6 @@
 package restore
 
 import (
-	"path/filepath"
-	"strings"
 	"time"
 
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
@@ -24,8 +22,18 @@ type pruneResourceInfo struct {
 	gvr       schema.GroupVersionResource
 }
 
-func (h *handler) prune(resourceSelectors []v1.ResourceSelector, transformerMap k8sEncryptionconfig.StaticTransformers,
+func (h *handler) prune(resourceSelectors, excludeSelectors []v1.ResourceSelector, transformerMap k8sEncryptionconfig.StaticTransformers,
 	cr ObjectsFromBackupCR, deleteTimeout int) error {
+	resourcesToDelete, err := h.resourcesToPrune(resourceSelectors, excludeSelectors, transformerMap, cr)
+	if err != nil {
+		return err
+	}
+	return h.pruneClusterScopedResources(resourcesToDelete, deleteTimeout)
+}
+
+// resourcesToPrune returns the objects selected by the resource selectors that are missing from the backup
+func (h *handler) resourcesToPrune(resourceSelectors, excludeSelectors []v1.ResourceSelector, transformerMap k8sEncryptionconfig.StaticTransformers,
+	cr ObjectsFromBackupCR) ([]pruneResourceInfo, error) {
 	var resourcesToDelete []pruneResourceInfo
 	rh := resourcesets.ResourceHandler{
 		DiscoveryClient: h.discoveryClient,
@@ -33,33 +41,28 @@ func (h *handler) prune(resourceSelectors []v1.ResourceSelector, transformerMap
 		TransformerMap:  transformerMap,
 	}
 
-	if err := rh.GatherResources(h.ctx, resourceSelectors); err != nil {
-		return err
+	// objects excluded from the backup are not gathered, so that they are not deleted for being missing from it
+	if err := rh.GatherResources(h.ctx, resourceSelectors, excludeSelectors); err != nil {
+		return nil, err
 	}
 
+	// objects are keyed regardless of the version of their group, the preferred version they are gathered in may have
+	// changed since the backup was taken
 	for gvResource, resObjects := range rh.GVResourceToObjects {
 		for _, resObj := range resObjects {
-			metadata := resObj.Object["metadata"].(map[string]interface{})
-			objName := metadata["name"].(string)
-			objNs, _ := metadata["namespace"].(string)
-			gv := gvResource.GroupVersion
-			resourcePath := gvResource.Name + "." + gv.Group + "#" + gv.Version
-			if gvResource.Namespaced {
-				resourcePath = filepath.Join(resourcePath, objNs)
-			}
-			resourceFilePath := filepath.Join(resourcePath, objName+".json")
-			logrus.Debugf("resourceFilePath: %v", resourceFilePath)
-			if !cr.resourcesFromBackup[resourceFilePath] {
-				logrus.Infof("Marking resource %v for deletion", strings.TrimSuffix(resourceFilePath, ".json"))
+			key := resourcesets.NewObjectKey(gvResource, resObj)
+			if !cr.resourcesFromBackup[key] {
+				gvr := gvResource.GroupVersion.WithResource(gvResource.Name)
+				logrus.Infof("Marking resource %v %v/%v for deletion", key.GroupResource, key.Namespace, key.Name)
 				resourcesToDelete = append(resourcesToDelete, pruneResourceInfo{
-					name:      objName,
-					namespace: objNs,
-					gvr:       gv.WithResource(gvResource.Name),
+					name:      key.Name,
+					namespace: resObj.GetNamespace(),
+					gvr:       gvr,
 				})
 			}
 		}
 	}
-	return h.pruneClusterScopedResources(resourcesToDelete, deleteTimeout)
+	return resourcesToDelete, nil
 }
 
 func (h *handler) pruneClusterScopedResources(resourcesToDelete []pruneResourceInfo, pruneTimeout int) error {
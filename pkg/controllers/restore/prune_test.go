@@ -0,0 +1,79 @@
+package restore
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestResourcesToPrune(t *testing.T) {
+	exampleV1 := schema.GroupVersion{Group: "example.io", Version: "v1"}
+	exampleV2 := schema.GroupVersion{Group: "example.io", Version: "v2"}
+	widgetResource := k8sv1.APIResource{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: k8sv1.Verbs{"get", "list", "delete"}}
+	// the first version listed for a group is its preferred version, v2 became preferred since the backup in v1
+	discoveryClient := &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*k8sv1.APIResourceList{
+		{GroupVersion: "example.io/v2", APIResources: []k8sv1.APIResource{widgetResource}},
+		{GroupVersion: "example.io/v1", APIResources: []k8sv1.APIResource{widgetResource}},
+	}}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			exampleV1.WithResource("widgets"): "WidgetList",
+			exampleV2.WithResource("widgets"): "WidgetList",
+		},
+		&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "example.io/v2", "kind": "Widget",
+			"metadata": map[string]interface{}{"namespace": "team-a", "name": "gear"}}},
+		&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "example.io/v2", "kind": "Widget",
+			"metadata": map[string]interface{}{"namespace": "team-a", "name": "spring"}}},
+		&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "example.io/v2", "kind": "Widget",
+			"metadata": map[string]interface{}{"namespace": "team-b", "name": "gear"}}},
+	)
+	h := &handler{ctx: context.Background(), discoveryClient: discoveryClient, dynamicClient: dynamicClient}
+	widgets := schema.GroupResource{Group: "example.io", Resource: "widgets"}
+	cr := ObjectsFromBackupCR{resourcesFromBackup: map[resourcesets.ObjectKey]bool{
+		{GroupResource: widgets, Namespace: "team-a", Name: "gear"}:   true,
+		{GroupResource: widgets, Namespace: "team-a", Name: "spring"}: true,
+	}}
+
+	testCases := []struct {
+		name              string
+		resourceSelectors []v1.ResourceSelector
+		expected          []string
+	}{
+		{
+			name:              "Group selected by regexp in its new preferred version",
+			resourceSelectors: []v1.ResourceSelector{{APIGroupRegexp: "^example.io$", KindsRegexp: "."}},
+			expected:          []string{"team-b/gear"},
+		},
+		{
+			name:              "Group selected in its new version",
+			resourceSelectors: []v1.ResourceSelector{{APIVersion: "example.io/v2", Kinds: []string{"widgets"}}},
+			expected:          []string{"team-b/gear"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resourcesToDelete, err := h.resourcesToPrune(tc.resourceSelectors, nil, nil, cr)
+			require.NoError(t, err)
+			var actual []string
+			for _, resource := range resourcesToDelete {
+				assert.Equal(t, widgets, resource.gvr.GroupResource())
+				actual = append(actual, resource.namespace+"/"+resource.name)
+			}
+			sort.Strings(actual)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package restore
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/objectstore"
+	"github.com/rancher/backup-restore-operator/pkg/storage"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// backupSelector is the selection of a backup from a storage location by the backupRef, latestFor and asOf fields of a restore
+type backupSelector struct {
+	backupName string
+	// clusterUIDPrefix restricts the selection to the backups of the clusters whose kube-system UID starts with it
+	clusterUIDPrefix string
+	index            int
+	asOf             time.Time
+}
+
+// ValidateBackupSelection checks that the restore selects a backup either by filename, or by backupRef, latestFor and
+// asOf, it is also used by the admission webhook
+func ValidateBackupSelection(spec v1.RestoreSpec) error {
+	if spec.BackupFilename != "" {
+		if spec.BackupRef != nil || spec.LatestFor != "" || spec.AsOf != "" {
+			return fmt.Errorf("backupFilename cannot be set together with backupRef, latestFor or asOf")
+		}
+		return nil
+	}
+	if spec.BackupRef != nil && spec.LatestFor != "" {
+		return fmt.Errorf("only one of backupRef and latestFor can be set")
+	}
+	if spec.BackupRef == nil && spec.LatestFor == "" && spec.AsOf == "" {
+		return fmt.Errorf("one of backupFilename, backupRef, latestFor or asOf must be set")
+	}
+	if spec.BackupRef != nil && spec.BackupRef.Name == "" {
+		return fmt.Errorf("backupRef must set the name of a Backup")
+	}
+	if spec.AsOf != "" {
+		if _, err := time.Parse(time.RFC3339, spec.AsOf); err != nil {
+			return fmt.Errorf("invalid asOf time %v, it must be in RFC3339 format: %v", spec.AsOf, err)
+		}
+	}
+	return nil
+}
+
+// restoreStorageLocation returns the storage location to restore from: the restore's own, otherwise the one of the Backup
+// it references. Nil means the operator's default storage location.
+func (h *handler) restoreStorageLocation(restore *v1.Restore) (*v1.StorageLocation, error) {
+	if restore.Spec.StorageLocation != nil || restore.Spec.BackupRef == nil {
+		return restore.Spec.StorageLocation, nil
+	}
+	backup, err := h.backups.Get(restore.Spec.BackupRef.Name, k8sv1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting Backup %v referenced by the restore: %w", restore.Spec.BackupRef.Name, err)
+	}
+	return backup.Spec.StorageLocation, nil
+}
+
+// selectedBackupFilename returns the filename of the backup selected for the current generation of the restore, empty
+// if none was selected yet or the selection changed since
+func selectedBackupFilename(restore *v1.Restore) string {
+	if restore.Status.BackupFilenameGeneration != restore.Generation {
+		return ""
+	}
+	return restore.Status.BackupFilename
+}
+
+// selectBackupFilename returns the filename of the backup selected by the backupRef, latestFor and asOf fields of the
+// restore, by listing the storage location
+func (h *handler) selectBackupFilename(restore *v1.Restore, location *v1.StorageLocation) (string, error) {
+	selector := backupSelector{clusterUIDPrefix: restore.Spec.LatestFor}
+	if restore.Spec.AsOf != "" {
+		asOf, err := time.Parse(time.RFC3339, restore.Spec.AsOf)
+		if err != nil {
+			return "", err
+		}
+		selector.asOf = asOf
+	}
+	if restore.Spec.BackupRef != nil {
+		selector.backupName = restore.Spec.BackupRef.Name
+		selector.index = restore.Spec.BackupRef.Index
+		backup, err := h.backups.Get(restore.Spec.BackupRef.Name, k8sv1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("error getting Backup %v referenced by the restore: %w", restore.Spec.BackupRef.Name, err)
+		}
+		// backups of the same name can be taken in several clusters, only select the ones of the Backup CR's cluster
+		if name, ok := storage.ParseArchiveName(backup.Status.Filename); ok {
+			selector.clusterUIDPrefix = name.ClusterUID
+		}
+	}
+
+	archives, err := h.listArchives(location)
+	if err != nil {
+		return "", err
+	}
+	archive, err := selector.selectArchive(archives)
+	if err != nil {
+		return "", err
+	}
+	return archive.Filename, nil
+}
+
+func (h *handler) listArchives(location *v1.StorageLocation) ([]storage.Archive, error) {
+	objectStore := h.defaultS3BackupLocation
+	if location != nil {
+		objectStore = location.S3
+	} else if objectStore == nil && h.defaultBackupMountPath != "" {
+		return storage.ListMountPath(h.defaultBackupMountPath)
+	}
+	if objectStore == nil {
+		return nil, fmt.Errorf("backup location not specified on the restore CR, and not configured at the operator level")
+	}
+	s3Client, err := objectstore.GetS3Client(h.ctx, objectStore, h.dynamicClient)
+	if err != nil {
+		return nil, err
+	}
+	return storage.ListS3(h.ctx, s3Client, objectStore)
+}
+
+// selectArchive returns the archive selected among archives, which are sorted newest first
+func (s backupSelector) selectArchive(archives []storage.Archive) (storage.Archive, error) {
+	var matches []storage.Archive
+	for _, archive := range archives {
+		if s.backupName != "" && archive.BackupName != s.backupName {
+			continue
+		}
+		if !strings.HasPrefix(archive.ClusterUID, s.clusterUIDPrefix) {
+			continue
+		}
+		if !s.asOf.IsZero() && archive.Timestamp.After(s.asOf) {
+			continue
+		}
+		matches = append(matches, archive)
+	}
+	if len(matches) == 0 {
+		return storage.Archive{}, fmt.Errorf("no backup matches the restore's selection")
+	}
+	if len(matches) <= s.index {
+		return storage.Archive{}, fmt.Errorf("found %d backups matching the restore's selection, cannot restore backup %d", len(matches), s.index)
+	}
+	return matches[s.index], nil
+}
@@ -0,0 +1,182 @@
+package restore
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBackupSelection(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    v1.RestoreSpec
+		wantErr bool
+	}{
+		{
+			name: "Backup filename",
+			spec: v1.RestoreSpec{BackupFilename: "backup.tar.gz"},
+		},
+		{
+			name: "Backup reference as of a time",
+			spec: v1.RestoreSpec{BackupRef: &v1.BackupReference{Name: "nightly"}, AsOf: "2024-01-02T00:00:00Z"},
+		},
+		{
+			name: "Latest for a cluster",
+			spec: v1.RestoreSpec{LatestFor: "24e1b8ce"},
+		},
+		{
+			name: "As of a time",
+			spec: v1.RestoreSpec{AsOf: "2024-01-02T00:00:00Z"},
+		},
+		{
+			name:    "Nothing selected",
+			spec:    v1.RestoreSpec{},
+			wantErr: true,
+		},
+		{
+			name:    "Backup filename with a selector",
+			spec:    v1.RestoreSpec{BackupFilename: "backup.tar.gz", LatestFor: "24e1b8ce"},
+			wantErr: true,
+		},
+		{
+			name:    "Backup reference and latest for a cluster",
+			spec:    v1.RestoreSpec{BackupRef: &v1.BackupReference{Name: "nightly"}, LatestFor: "24e1b8ce"},
+			wantErr: true,
+		},
+		{
+			name:    "Backup reference without name",
+			spec:    v1.RestoreSpec{BackupRef: &v1.BackupReference{}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid as of time",
+			spec:    v1.RestoreSpec{AsOf: "yesterday"},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := ValidateBackupSelection(testCase.spec)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSelectArchive(t *testing.T) {
+	newArchive := func(filename, backupName, clusterUID string, day int) storage.Archive {
+		return storage.Archive{
+			ArchiveName: storage.ArchiveName{
+				BackupName: backupName,
+				ClusterUID: clusterUID,
+				Timestamp:  time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC),
+			},
+			Filename: filename,
+		}
+	}
+	// sorted newest first, as listed from a storage location
+	archives := []storage.Archive{
+		newArchive("nightly-4", "nightly", "aaaa-1111", 4),
+		newArchive("other-3", "other", "aaaa-1111", 3),
+		newArchive("nightly-other-cluster-3", "nightly", "bbbb-2222", 3),
+		newArchive("nightly-2", "nightly", "aaaa-1111", 2),
+		newArchive("nightly-1", "nightly", "aaaa-1111", 1),
+	}
+
+	testCases := []struct {
+		name     string
+		selector backupSelector
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "Latest backup of a Backup CR",
+			selector: backupSelector{backupName: "nightly", clusterUIDPrefix: "aaaa-1111"},
+			expected: "nightly-4",
+		},
+		{
+			name:     "Older backup of a Backup CR",
+			selector: backupSelector{backupName: "nightly", clusterUIDPrefix: "aaaa-1111", index: 1},
+			expected: "nightly-2",
+		},
+		{
+			name:     "Latest backup of a cluster",
+			selector: backupSelector{clusterUIDPrefix: "bbbb"},
+			expected: "nightly-other-cluster-3",
+		},
+		{
+			name:     "Latest backup as of a time",
+			selector: backupSelector{asOf: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)},
+			expected: "other-3",
+		},
+		{
+			name:     "Backup of a Backup CR as of a time",
+			selector: backupSelector{backupName: "nightly", clusterUIDPrefix: "aaaa", asOf: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC), index: 1},
+			expected: "nightly-1",
+		},
+		{
+			name:     "No matching backup",
+			selector: backupSelector{backupName: "weekly"},
+			wantErr:  true,
+		},
+		{
+			name:     "Index out of range",
+			selector: backupSelector{backupName: "other", index: 1},
+			wantErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			archive, err := testCase.selector.selectArchive(archives)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, archive.Filename)
+		})
+	}
+}
+
+func TestSelectedBackupFilename(t *testing.T) {
+	newRestore := func(generation, selectedFor int64) *v1.Restore {
+		restore := &v1.Restore{Status: v1.RestoreStatus{BackupFilename: "nightly-1.tar.gz", BackupFilenameGeneration: selectedFor}}
+		restore.Generation = generation
+		return restore
+	}
+
+	testCases := []struct {
+		name     string
+		restore  *v1.Restore
+		expected string
+	}{
+		{
+			name:     "Backup selected for the current generation",
+			restore:  newRestore(2, 2),
+			expected: "nightly-1.tar.gz",
+		},
+		{
+			name:    "Selection changed since the backup was selected",
+			restore: newRestore(3, 2),
+		},
+		{
+			name:    "No backup selected",
+			restore: &v1.Restore{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, selectedBackupFilename(testCase.restore))
+		})
+	}
+}
@@ -32,6 +32,8 @@ func init() {
 
 type Interface interface {
 	Backup() BackupController
+	BackupArchive() BackupArchiveController
+	BackupReencrypt() BackupReencryptController
 	ResourceSet() ResourceSetController
 	Restore() RestoreController
 }
@@ -50,6 +52,14 @@ func (v *version) Backup() BackupController {
 	return generic.NewNonNamespacedController[*v1.Backup, *v1.BackupList](schema.GroupVersionKind{Group: "resources.cattle.io", Version: "v1", Kind: "Backup"}, "backups", v.controllerFactory)
 }
 
+func (v *version) BackupArchive() BackupArchiveController {
+	return generic.NewNonNamespacedController[*v1.BackupArchive, *v1.BackupArchiveList](schema.GroupVersionKind{Group: "resources.cattle.io", Version: "v1", Kind: "BackupArchive"}, "backuparchives", v.controllerFactory)
+}
+
+func (v *version) BackupReencrypt() BackupReencryptController {
+	return generic.NewNonNamespacedController[*v1.BackupReencrypt, *v1.BackupReencryptList](schema.GroupVersionKind{Group: "resources.cattle.io", Version: "v1", Kind: "BackupReencrypt"}, "backupreencrypts", v.controllerFactory)
+}
+
 func (v *version) ResourceSet() ResourceSetController {
 	return generic.NewNonNamespacedController[*v1.ResourceSet, *v1.ResourceSetList](schema.GroupVersionKind{Group: "resources.cattle.io", Version: "v1", Kind: "ResourceSet"}, "resourcesets", v.controllerFactory)
 }
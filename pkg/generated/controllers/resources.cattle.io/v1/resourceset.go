@@ -19,8 +19,19 @@ limitations under the License.
 package v1
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/wrangler/v3/pkg/apply"
+	"github.com/rancher/wrangler/v3/pkg/condition"
 	"github.com/rancher/wrangler/v3/pkg/generic"
+	"github.com/rancher/wrangler/v3/pkg/kv"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // ResourceSetController interface for managing ResourceSet resources.
@@ -37,3 +48,161 @@ type ResourceSetClient interface {
 type ResourceSetCache interface {
 	generic.NonNamespacedCacheInterface[*v1.ResourceSet]
 }
+
+// ResourceSetStatusHandler is executed for every added or modified ResourceSet. Should return the new status to be updated
+type ResourceSetStatusHandler func(obj *v1.ResourceSet, status v1.ResourceSetStatus) (v1.ResourceSetStatus, error)
+
+// ResourceSetGeneratingHandler is the top-level handler that is executed for every ResourceSet event. It extends ResourceSetStatusHandler by a returning a slice of child objects to be passed to apply.Apply
+type ResourceSetGeneratingHandler func(obj *v1.ResourceSet, status v1.ResourceSetStatus) ([]runtime.Object, v1.ResourceSetStatus, error)
+
+// RegisterResourceSetStatusHandler configures a ResourceSetController to execute a ResourceSetStatusHandler for every events observed.
+// If a non-empty condition is provided, it will be updated in the status conditions for every handler execution
+func RegisterResourceSetStatusHandler(ctx context.Context, controller ResourceSetController, condition condition.Cond, name string, handler ResourceSetStatusHandler) {
+	statusHandler := &resourceSetStatusHandler{
+		client:    controller,
+		condition: condition,
+		handler:   handler,
+	}
+	controller.AddGenericHandler(ctx, name, generic.FromObjectHandlerToHandler(statusHandler.sync))
+}
+
+// RegisterResourceSetGeneratingHandler configures a ResourceSetController to execute a ResourceSetGeneratingHandler for every events observed, passing the returned objects to the provided apply.Apply.
+// If a non-empty condition is provided, it will be updated in the status conditions for every handler execution
+func RegisterResourceSetGeneratingHandler(ctx context.Context, controller ResourceSetController, apply apply.Apply,
+	condition condition.Cond, name string, handler ResourceSetGeneratingHandler, opts *generic.GeneratingHandlerOptions) {
+	statusHandler := &resourceSetGeneratingHandler{
+		ResourceSetGeneratingHandler: handler,
+		apply:                        apply,
+		name:                         name,
+		gvk:                          controller.GroupVersionKind(),
+	}
+	if opts != nil {
+		statusHandler.opts = *opts
+	}
+	controller.OnChange(ctx, name, statusHandler.Remove)
+	RegisterResourceSetStatusHandler(ctx, controller, condition, name, statusHandler.Handle)
+}
+
+type resourceSetStatusHandler struct {
+	client    ResourceSetClient
+	condition condition.Cond
+	handler   ResourceSetStatusHandler
+}
+
+// sync is executed on every resource addition or modification. Executes the configured handlers and sends the updated status to the Kubernetes API
+func (a *resourceSetStatusHandler) sync(key string, obj *v1.ResourceSet) (*v1.ResourceSet, error) {
+	if obj == nil {
+		return obj, nil
+	}
+
+	origStatus := obj.Status.DeepCopy()
+	obj = obj.DeepCopy()
+	newStatus, err := a.handler(obj, obj.Status)
+	if err != nil {
+		// Revert to old status on error
+		newStatus = *origStatus.DeepCopy()
+	}
+
+	if a.condition != "" {
+		if errors.IsConflict(err) {
+			a.condition.SetError(&newStatus, "", nil)
+		} else {
+			a.condition.SetError(&newStatus, "", err)
+		}
+	}
+	if !equality.Semantic.DeepEqual(origStatus, &newStatus) {
+		if a.condition != "" {
+			// Since status has changed, update the lastUpdatedTime
+			a.condition.LastUpdated(&newStatus, time.Now().UTC().Format(time.RFC3339))
+		}
+
+		var newErr error
+		obj.Status = newStatus
+		newObj, newErr := a.client.UpdateStatus(obj)
+		if err == nil {
+			err = newErr
+		}
+		if newErr == nil {
+			obj = newObj
+		}
+	}
+	return obj, err
+}
+
+type resourceSetGeneratingHandler struct {
+	ResourceSetGeneratingHandler
+	apply apply.Apply
+	opts  generic.GeneratingHandlerOptions
+	gvk   schema.GroupVersionKind
+	name  string
+	seen  sync.Map
+}
+
+// Remove handles the observed deletion of a resource, cascade deleting every associated resource previously applied
+func (a *resourceSetGeneratingHandler) Remove(key string, obj *v1.ResourceSet) (*v1.ResourceSet, error) {
+	if obj != nil {
+		return obj, nil
+	}
+
+	obj = &v1.ResourceSet{}
+	obj.Namespace, obj.Name = kv.RSplit(key, "/")
+	obj.SetGroupVersionKind(a.gvk)
+
+	if a.opts.UniqueApplyForResourceVersion {
+		a.seen.Delete(key)
+	}
+
+	return nil, generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects()
+}
+
+// Handle executes the configured ResourceSetGeneratingHandler and pass the resulting objects to apply.Apply, finally returning the new status of the resource
+func (a *resourceSetGeneratingHandler) Handle(obj *v1.ResourceSet, status v1.ResourceSetStatus) (v1.ResourceSetStatus, error) {
+	if !obj.DeletionTimestamp.IsZero() {
+		return status, nil
+	}
+
+	objs, newStatus, err := a.ResourceSetGeneratingHandler(obj, status)
+	if err != nil {
+		return newStatus, err
+	}
+	if !a.isNewResourceVersion(obj) {
+		return newStatus, nil
+	}
+
+	err = generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects(objs...)
+	if err != nil {
+		return newStatus, err
+	}
+	a.storeResourceVersion(obj)
+	return newStatus, nil
+}
+
+// isNewResourceVersion detects if a specific resource version was already successfully processed.
+// Only used if UniqueApplyForResourceVersion is set in generic.GeneratingHandlerOptions
+func (a *resourceSetGeneratingHandler) isNewResourceVersion(obj *v1.ResourceSet) bool {
+	if !a.opts.UniqueApplyForResourceVersion {
+		return true
+	}
+
+	// Apply once per resource version
+	key := obj.Namespace + "/" + obj.Name
+	previous, ok := a.seen.Load(key)
+	return !ok || previous != obj.ResourceVersion
+}
+
+// storeResourceVersion keeps track of the latest resource version of an object for which Apply was executed
+// Only used if UniqueApplyForResourceVersion is set in generic.GeneratingHandlerOptions
+func (a *resourceSetGeneratingHandler) storeResourceVersion(obj *v1.ResourceSet) {
+	if !a.opts.UniqueApplyForResourceVersion {
+		return
+	}
+
+	key := obj.Namespace + "/" + obj.Name
+	a.seen.Store(key, obj.ResourceVersion)
+}
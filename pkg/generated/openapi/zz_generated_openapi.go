@@ -29,22 +29,45 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.AwsConfig":           schema_pkg_apis_resourcescattleio_v1_AwsConfig(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Backup":              schema_pkg_apis_resourcescattleio_v1_Backup(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupList":          schema_pkg_apis_resourcescattleio_v1_BackupList(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupSpec":          schema_pkg_apis_resourcescattleio_v1_BackupSpec(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupStatus":        schema_pkg_apis_resourcescattleio_v1_BackupStatus(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ClientConfig":        schema_pkg_apis_resourcescattleio_v1_ClientConfig(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ControllerReference": schema_pkg_apis_resourcescattleio_v1_ControllerReference(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSelector":    schema_pkg_apis_resourcescattleio_v1_ResourceSelector(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSet":         schema_pkg_apis_resourcescattleio_v1_ResourceSet(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSetList":     schema_pkg_apis_resourcescattleio_v1_ResourceSetList(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Restore":             schema_pkg_apis_resourcescattleio_v1_Restore(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreList":         schema_pkg_apis_resourcescattleio_v1_RestoreList(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreSpec":         schema_pkg_apis_resourcescattleio_v1_RestoreSpec(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreStatus":       schema_pkg_apis_resourcescattleio_v1_RestoreStatus(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.S3ObjectStore":       schema_pkg_apis_resourcescattleio_v1_S3ObjectStore(ref),
-		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation":     schema_pkg_apis_resourcescattleio_v1_StorageLocation(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ArchiveReencryption":    schema_pkg_apis_resourcescattleio_v1_ArchiveReencryption(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.AwsConfig":              schema_pkg_apis_resourcescattleio_v1_AwsConfig(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Backup":                 schema_pkg_apis_resourcescattleio_v1_Backup(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupArchive":          schema_pkg_apis_resourcescattleio_v1_BackupArchive(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupArchiveList":      schema_pkg_apis_resourcescattleio_v1_BackupArchiveList(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupArchiveSpec":      schema_pkg_apis_resourcescattleio_v1_BackupArchiveSpec(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupList":             schema_pkg_apis_resourcescattleio_v1_BackupList(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReencrypt":        schema_pkg_apis_resourcescattleio_v1_BackupReencrypt(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReencryptList":    schema_pkg_apis_resourcescattleio_v1_BackupReencryptList(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReencryptSpec":    schema_pkg_apis_resourcescattleio_v1_BackupReencryptSpec(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReencryptStatus":  schema_pkg_apis_resourcescattleio_v1_BackupReencryptStatus(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReference":        schema_pkg_apis_resourcescattleio_v1_BackupReference(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupSnapshot":         schema_pkg_apis_resourcescattleio_v1_BackupSnapshot(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupSpec":             schema_pkg_apis_resourcescattleio_v1_BackupSpec(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupStatus":           schema_pkg_apis_resourcescattleio_v1_BackupStatus(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupTrigger":          schema_pkg_apis_resourcescattleio_v1_BackupTrigger(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ClientConfig":           schema_pkg_apis_resourcescattleio_v1_ClientConfig(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Compression":            schema_pkg_apis_resourcescattleio_v1_Compression(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ControllerReference":    schema_pkg_apis_resourcescattleio_v1_ControllerReference(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.HelmReleaseReference":   schema_pkg_apis_resourcescattleio_v1_HelmReleaseReference(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ObjectLock":             schema_pkg_apis_resourcescattleio_v1_ObjectLock(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSelector":       schema_pkg_apis_resourcescattleio_v1_ResourceSelector(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSelectorStatus": schema_pkg_apis_resourcescattleio_v1_ResourceSelectorStatus(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSet":            schema_pkg_apis_resourcescattleio_v1_ResourceSet(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSetGenerator":   schema_pkg_apis_resourcescattleio_v1_ResourceSetGenerator(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSetList":        schema_pkg_apis_resourcescattleio_v1_ResourceSetList(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSetStatus":      schema_pkg_apis_resourcescattleio_v1_ResourceSetStatus(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Restore":                schema_pkg_apis_resourcescattleio_v1_Restore(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreList":            schema_pkg_apis_resourcescattleio_v1_RestoreList(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreSpec":            schema_pkg_apis_resourcescattleio_v1_RestoreSpec(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreStatus":          schema_pkg_apis_resourcescattleio_v1_RestoreStatus(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RetentionPolicy":        schema_pkg_apis_resourcescattleio_v1_RetentionPolicy(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.S3ObjectStore":          schema_pkg_apis_resourcescattleio_v1_S3ObjectStore(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SelectedResource":       schema_pkg_apis_resourcescattleio_v1_SelectedResource(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SelectorOverlap":        schema_pkg_apis_resourcescattleio_v1_SelectorOverlap(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SelectorReference":      schema_pkg_apis_resourcescattleio_v1_SelectorReference(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ServerSideEncryption":   schema_pkg_apis_resourcescattleio_v1_ServerSideEncryption(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SkippedResource":        schema_pkg_apis_resourcescattleio_v1_SkippedResource(ref),
+		"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation":        schema_pkg_apis_resourcescattleio_v1_StorageLocation(ref),
 		v1.APIGroup{}.OpenAPIModelName():                  schema_pkg_apis_meta_v1_APIGroup(ref),
 		v1.APIGroupList{}.OpenAPIModelName():              schema_pkg_apis_meta_v1_APIGroupList(ref),
 		v1.APIResource{}.OpenAPIModelName():               schema_pkg_apis_meta_v1_APIResource(ref),
@@ -98,6 +121,47 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 	}
 }
 
+func schema_pkg_apis_resourcescattleio_v1_ArchiveReencryption(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ArchiveReencryption is the progress of the re-encryption of an archive",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"filename": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"resourceCount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of resources re-encrypted in the archive",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"filename", "phase"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_resourcescattleio_v1_AwsConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -166,11 +230,11 @@ func schema_pkg_apis_resourcescattleio_v1_Backup(ref common.ReferenceCallback) c
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_BackupList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_BackupArchive(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "BackupList is a list of Backup resources",
+				Description: "BackupArchive is a catalog entry for a backup archive found in a storage location. BackupArchives are maintained by the operator, which periodically lists the storage locations it knows of, so that archives can be found and restored in a cluster that has no Backup CR for them, such as a freshly provisioned cluster after a disaster.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
@@ -190,303 +254,364 @@ func schema_pkg_apis_resourcescattleio_v1_BackupList(ref common.ReferenceCallbac
 					"metadata": {
 						SchemaProps: spec.SchemaProps{
 							Default: map[string]interface{}{},
-							Ref:     ref(v1.ListMeta{}.OpenAPIModelName()),
+							Ref:     ref(v1.ObjectMeta{}.OpenAPIModelName()),
 						},
 					},
-					"items": {
+					"spec": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Backup"),
-									},
-								},
-							},
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupArchiveSpec"),
 						},
 					},
 				},
-				Required: []string{"metadata", "items"},
+				Required: []string{"spec"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Backup", v1.ListMeta{}.OpenAPIModelName()},
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupArchiveSpec", v1.ObjectMeta{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_BackupSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_BackupArchiveList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "BackupArchiveList is a list of BackupArchive resources",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"storageLocation": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation"),
-						},
-					},
-					"resourceSetName": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name of the ResourceSet CR to use for backup",
-							Default:     "",
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"encryptionConfigSecretName": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name of the Secret containing the encryption config",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"schedule": {
+					"metadata": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Cron schedule for recurring backups",
-							Type:        []string{"string"},
-							Format:      "",
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ListMeta{}.OpenAPIModelName()),
 						},
 					},
-					"retentionCount": {
+					"items": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupArchive"),
+									},
+								},
+							},
 						},
 					},
 				},
-				Required: []string{"resourceSetName"},
+				Required: []string{"metadata", "items"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation"},
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupArchive", v1.ListMeta{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_BackupStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_BackupArchiveSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
 				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"conditions": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-map-keys": []interface{}{
-									"type",
-								},
-								"x-kubernetes-list-type": "map",
-							},
+					"filename": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Filename of the archive, to be used as backupFilename of a Restore",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
+					},
+					"backupName": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("github.com/rancher/wrangler/v3/pkg/genericcondition.GenericCondition"),
-									},
-								},
-							},
+							Description: "BackupName is the name of the Backup CR that created the archive",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"lastSnapshotTs": {
+					"clusterUID": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "ClusterUID is the UID of the kube-system namespace of the cluster the archive was taken from",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"nextSnapshotAt": {
+					"timestamp": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Timestamp is the time the archive was taken at, in RFC3339 format",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"observedGeneration": {
+					"size": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"integer"},
 							Format: "int64",
 						},
 					},
-					"storageLocation": {
+					"encrypted": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
+							Type:   []string{"boolean"},
 							Format: "",
 						},
 					},
-					"backupType": {
+					"sealed": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Sealed archives are encrypted as a whole, with the keys of an archive encryption secret",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"filename": {
+					"storageLocationType": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "StorageLocationType is either PV or S3",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"summary": {
+					"storageLocation": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "StorageLocation is the S3 location of the archive, to be used as storageLocation of a Restore. It is unset for archives in the operator's default storage location.",
+							Ref:         ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation"),
 						},
 					},
 				},
+				Required: []string{"filename", "backupName", "clusterUID", "timestamp", "storageLocationType"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/rancher/wrangler/v3/pkg/genericcondition.GenericCondition"},
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation"},
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_ClientConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_BackupList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ClientConfig allows configuration of more advanced minio client settings any provider specific settings will be grouped accordingly, otherwise settings apply to all S3 providers.",
+				Description: "BackupList is a list of Backup resources",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"aws": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.AwsConfig"),
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"bucketLookup": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "BucketLookup controls the bucket lookup mode. Supported values: \"auto\", \"dns\", \"path\".",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ListMeta{}.OpenAPIModelName()),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Backup"),
+									},
+								},
+							},
+						},
+					},
 				},
+				Required: []string{"metadata", "items"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.AwsConfig"},
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Backup", v1.ListMeta{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_ControllerReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_BackupReencrypt(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ControllerReference identifies a controller to scale down during restore operations.",
+				Description: "BackupReencrypt re-encrypts the stored archives of a Backup after its encryption config was rotated, so that the previous encryption config is no longer needed to restore them",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"apiVersion": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion of the controller resource (e.g., \"apps/v1\")",
-							Default:     "",
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"resource": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Resource type (e.g., \"deployments\", \"statefulsets\")",
-							Default:     "",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"namespace": {
+					"metadata": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Namespace where the controller lives",
-							Default:     "",
-							Type:        []string{"string"},
-							Format:      "",
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ObjectMeta{}.OpenAPIModelName()),
 						},
 					},
-					"name": {
+					"spec": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name of the controller",
-							Default:     "",
-							Type:        []string{"string"},
-							Format:      "",
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReencryptSpec"),
 						},
 					},
-					"replicas": {
+					"status": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Populated at restore time from the live cluster",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReencryptStatus"),
 						},
 					},
 				},
-				Required: []string{"apiVersion", "resource", "namespace", "name"},
+				Required: []string{"spec"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReencryptSpec", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReencryptStatus", v1.ObjectMeta{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_ResourceSelector(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_BackupReencryptList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "BackupReencryptList is a list of BackupReencrypt resources",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Default: "",
-							Type:    []string{"string"},
-							Format:  "",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"kinds": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "set",
-							},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ListMeta{}.OpenAPIModelName()),
 						},
+					},
+					"items": {
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Default: "",
-										Type:    []string{"string"},
-										Format:  "",
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReencrypt"),
 									},
 								},
 							},
 						},
 					},
-					"kindsRegexp": {
+				},
+				Required: []string{"metadata", "items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReencrypt", v1.ListMeta{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_BackupReencryptSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"backupName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Name of the Backup whose encrypted archives taken in this cluster are re-encrypted",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"resourceNames": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "set",
-							},
+					"storageLocation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Storage location of the archives, the Backup's storage location is used when unset",
+							Ref:         ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation"),
 						},
+					},
+					"oldEncryptionConfigSecretName": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: "",
-										Type:    []string{"string"},
-										Format:  "",
-									},
-								},
-							},
+							Description: "Name of the Secret containing the encryption config the archives were encrypted with",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"resourceNameRegexp": {
+					"newEncryptionConfigSecretName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Name of the Secret containing the encryption config the archives are re-encrypted with",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"namespaces": {
+					"archiveEncryptionSecretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the Secret containing the age keys or KMS plugin of archives encrypted as a whole. These archives are decrypted with its identities and encrypted again to its recipients.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"backupName", "oldEncryptionConfigSecretName", "newEncryptionConfigSecretName"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation"},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_BackupReencryptStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"conditions": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "set",
+								"x-kubernetes-list-map-keys": []interface{}{
+									"type",
+								},
+								"x-kubernetes-list-type": "map",
 							},
 						},
 						SchemaProps: spec.SchemaProps{
@@ -494,470 +619,1780 @@ func schema_pkg_apis_resourcescattleio_v1_ResourceSelector(ref common.ReferenceC
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Default: "",
-										Type:    []string{"string"},
-										Format:  "",
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/wrangler/v3/pkg/genericcondition.GenericCondition"),
 									},
 								},
 							},
 						},
 					},
-					"namespaceRegexp": {
+					"observedGeneration": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
-					"labelSelectors": {
+					"total": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref(v1.LabelSelector{}.OpenAPIModelName()),
+							Description: "Total number of archives to re-encrypt",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"fieldSelectors": {
+					"reencrypted": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: "",
-										Type:    []string{"string"},
-										Format:  "",
-									},
-								},
-							},
+							Description: "Number of archives re-encrypted and verified",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"excludeKinds": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "set",
-							},
+					"failed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of archives that could not be re-encrypted",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
+					},
+					"archives": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
+							Description: "Archives lists the progress of each archive to re-encrypt, archives are re-encrypted in order",
+							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Default: "",
-										Type:    []string{"string"},
-										Format:  "",
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ArchiveReencryption"),
 									},
 								},
 							},
 						},
 					},
-					"excludeResourceNameRegexp": {
+					"completionTimestamp": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Time the last archive was processed",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
-				Required: []string{"apiVersion"},
 			},
 		},
 		Dependencies: []string{
-			v1.LabelSelector{}.OpenAPIModelName()},
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ArchiveReencryption", "github.com/rancher/wrangler/v3/pkg/genericcondition.GenericCondition"},
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_ResourceSet(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_BackupReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "BackupReference selects one of the backups taken by a Backup CR",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Description: "Name of the Backup CR",
+							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"apiVersion": {
+					"index": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Description: "Index of the backup to restore among the backups taken by the Backup CR, from newest to oldest. The latest backup is restored by default.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_BackupSnapshot(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BackupSnapshot describes a single snapshot taken by a Backup",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"filename": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the backup archive",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"metadata": {
+					"size": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref(v1.ObjectMeta{}.OpenAPIModelName()),
+							Description: "Size of the archive in bytes",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"resourceSelectors": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "atomic",
-							},
+					"objectCount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of objects stored in the archive",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
+					},
+					"startedAt": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSelector"),
-									},
-								},
-							},
+							Description: "Time the snapshot was started",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"controllerReferences": {
+					"duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time it took to take and store the snapshot, example \"1m30s\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"storageLocation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type of storage the archive was written to, S3 or PV",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"location": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Full location of the archive, example \"s3://bucket/folder/archive.tar.gz\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"result": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Error that caused a failed snapshot",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"selectorOverlaps": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
 								"x-kubernetes-list-type": "atomic",
 							},
 						},
 						SchemaProps: spec.SchemaProps{
-							Description: "ControllerReferences lists controllers to scale down during restore operations.",
+							Description: "SelectorOverlaps lists the objects selected by several selectors of the ResourceSet, which are stored once",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Default: map[string]interface{}{},
-										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ControllerReference"),
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SelectorOverlap"),
 									},
 								},
 							},
 						},
 					},
 				},
-				Required: []string{"resourceSelectors"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ControllerReference", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSelector", v1.ObjectMeta{}.OpenAPIModelName()},
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SelectorOverlap"},
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_ResourceSetList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_BackupSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ResourceSetList is a list of ResourceSet resources",
-				Type:        []string{"object"},
+				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"storageLocation": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation"),
+						},
+					},
+					"resourceSetName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the ResourceSet CR to use for backup",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"encryptionConfigSecretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the Secret containing the encryption config",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"archiveEncryptionSecretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the Secret containing the age recipients or KMS plugin the whole archive is encrypted to",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"compression": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Compression of the archive, gzip at its default level when unset",
+							Ref:         ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Compression"),
+						},
+					},
+					"requireEncryption": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When true, the backup fails instead of storing secrets and the operator's sensitive resources unencrypted. Encryption is required regardless when the operator's requireEncryption setting is enabled, false doesn't override it.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"schedule": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cron schedule for recurring backups",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"retentionCount": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"retention": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tiered retention of the backup's archives. When set, it replaces retentionCount for deleting the archives of recurring backups, and is also applied to one-time backups each time they take a snapshot.",
+							Ref:         ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RetentionPolicy"),
+						},
+					},
+					"timeZone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IANA name of the time zone the schedule is evaluated in, example \"Europe/Berlin\". Defaults to the operator's local time zone.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"jitterSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Upper bound in seconds of a random delay added to each scheduled snapshot, to spread load when many backups share a schedule",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"startingDeadlineSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Deadline in seconds for starting a scheduled snapshot. A snapshot missed by more than this, for instance because the operator was down, is skipped and the next one is scheduled instead. Missed snapshots are always taken when unset.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"suspend": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When set to true, no further snapshots are taken until it is set back to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"concurrencyPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How to schedule the next snapshot when the current one is still running at the time it is due: from the start (Allow) or from the end (Forbid) of the current one, defaults to Forbid. Snapshots never run concurrently.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"deletionPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "What happens to the archives taken by the backup in this cluster when the Backup is deleted, defaults to Retain",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"resourceSetName"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Compression", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RetentionPolicy", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation"},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_BackupStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"conditions": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"type",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/wrangler/v3/pkg/genericcondition.GenericCondition"),
+									},
+								},
+							},
+						},
+					},
+					"lastSnapshotTs": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"nextSnapshotAt": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"storageLocation": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"backupType": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"filename": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"summary": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"history": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Snapshots taken by this backup, newest first. Entries are pruned along with the archives deleted by the retention policy, and only the most recent failed attempts are kept.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupSnapshot"),
+									},
+								},
+							},
+						},
+					},
+					"lastTrigger": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Last snapshot triggered through the trigger-snapshot annotation",
+							Ref:         ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupTrigger"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupSnapshot", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupTrigger", "github.com/rancher/wrangler/v3/pkg/genericcondition.GenericCondition"},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_BackupTrigger(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BackupTrigger records a snapshot that was requested through the trigger-snapshot annotation",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"token": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Value of the annotation that requested the snapshot",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"fieldManager": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Field manager that set the annotation, example \"kubectl-annotate\". It names the client which set the annotation.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"user": {
+						SchemaProps: spec.SchemaProps{
+							Description: "User who set the annotation, recorded by the admission webhook. Empty when the webhook is disabled.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"triggeredAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time the triggered snapshot was taken",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_ClientConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ClientConfig allows configuration of more advanced minio client settings any provider specific settings will be grouped accordingly, otherwise settings apply to all S3 providers.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"aws": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.AwsConfig"),
+						},
+					},
+					"bucketLookup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BucketLookup controls the bucket lookup mode. Supported values: \"auto\", \"dns\", \"path\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.AwsConfig"},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_Compression(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Compression describes how the archive of a backup is compressed",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"algorithm": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Compression algorithm, gzip when unset",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"level": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Compression level, from 1 to 9 for gzip and from 1 to 22 for zstd. The default level of the algorithm is used when unset",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_ControllerReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ControllerReference identifies a controller to scale down during restore operations.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion of the controller resource (e.g., \"apps/v1\")",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resource type (e.g., \"deployments\", \"statefulsets\")",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespace where the controller lives",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the controller",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"replicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Populated at restore time from the live cluster",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"apiVersion", "resource", "namespace", "name"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_HelmReleaseReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "HelmReleaseReference identifies a Helm release",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+				},
+				Required: []string{"name", "namespace"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_ObjectLock(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ObjectLock configures S3 Object Lock on uploaded backup files, to make them immutable. The bucket must have object lock enabled.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"mode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Retention mode of uploaded backup files, GOVERNANCE or COMPLIANCE. When unset, the bucket's default retention applies.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"retainFor": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How long uploaded backup files are retained, example \"720h\". Defaults to how long the backup's retention policy keeps them: its maxAge, or its retentionCount times the interval of its schedule.",
+							Ref:         ref(v1.Duration{}.OpenAPIModelName()),
+						},
+					},
+					"legalHold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Places a legal hold on uploaded backup files, which prevents their deletion until the hold is removed",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			v1.Duration{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_ResourceSelector(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion selects the resources of one group version, example \"v1\" or \"management.cattle.io/v3\". It is required unless APIGroupRegexp is set.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiGroupRegexp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIGroupRegexp selects the resources of every API group whose name matches, in the version the API server prefers for the group, so that an object served by several versions of its group is selected once. The core group is named \"\" and is matched by \"^$\", \".\" matches every group. It can't be combined with APIVersion.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"kinds": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"kindsRegexp": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"resourceNames": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"resourceNameRegexp": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"namespaces": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"namespaceRegexp": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"namespaceSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Selects namespaced resources in the namespaces whose labels match. It is ignored for cluster scoped resources, and for resources which can't be listed",
+							Ref:         ref(v1.LabelSelector{}.OpenAPIModelName()),
+						},
+					},
+					"labelSelectors": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref(v1.LabelSelector{}.OpenAPIModelName()),
+						},
+					},
+					"fieldSelectors": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"excludeKinds": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"excludeResourceNameRegexp": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"match": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Match is a CEL expression evaluated on each object selected by the other fields, as the variable \"object\". Only the objects for which it returns true are selected, example `!has(object.type) || object.type != \"helm.sh/release.v1\"`. Objects missing a field the expression reads fail the backup, check them with has().",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			v1.LabelSelector{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_ResourceSelectorStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ResourceSelectorStatus is what a resource selector selects in the cluster",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resources lists the resources matched by the selector, with the number of their objects it selects",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SelectedResource"),
+									},
+								},
+							},
+						},
+					},
+					"objectCount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of objects selected",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"unknownAPIVersions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UnknownAPIVersions lists the API versions selected which the cluster doesn't serve",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"skippedResources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SkippedResources lists the resources matched whose objects are not backed up, because they can't be listed and the selector doesn't name the objects to get",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SkippedResource"),
+									},
+								},
+							},
+						},
+					},
+					"error": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Error is set when the selector is invalid, or its objects could not be gathered",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SelectedResource", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SkippedResource"},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_ResourceSet(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ObjectMeta{}.OpenAPIModelName()),
+						},
+					},
+					"resourceSelectors": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSelector"),
+									},
+								},
+							},
+						},
+					},
+					"excludeSelectors": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "ExcludeSelectors select objects which are not backed up, nor deleted by restores with prune, even though resourceSelectors select them. An object is excluded whichever version of its group the selectors use.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSelector"),
+									},
+								},
+							},
+						},
+					},
+					"include": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Include lists the names of other ResourceSets whose ResourceSelectors and ControllerReferences are merged into this one when a backup is taken, and recorded with the merged selectors in the backup. Included ResourceSets can include others in turn.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"generate": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Generate builds resource selectors from what is installed in the cluster each time a backup is taken, they are merged after ResourceSelectors and recorded with them in the backup",
+							Ref:         ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSetGenerator"),
+						},
+					},
+					"controllerReferences": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "ControllerReferences lists controllers to scale down during restore operations.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ControllerReference"),
+									},
+								},
+							},
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSetStatus"),
+						},
+					},
+				},
+				Required: []string{"resourceSelectors"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ControllerReference", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSelector", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSetGenerator", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSetStatus", v1.ObjectMeta{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_ResourceSetGenerator(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ResourceSetGenerator selects what resource selectors are generated from, the selectors of each field set are merged",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"crdGroupRegexp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CRDGroupRegexp selects the CustomResourceDefinitions whose group matches, and their objects in the version each CRD stores them in",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"helmReleases": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "HelmReleases selects the objects of the manifest of the deployed revision of each Helm release",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.HelmReleaseReference"),
+									},
+								},
+							},
+						},
+					},
+					"labelSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LabelSelector selects the objects of every resource whose labels match",
+							Ref:         ref(v1.LabelSelector{}.OpenAPIModelName()),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.HelmReleaseReference", v1.LabelSelector{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_ResourceSetList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ResourceSetList is a list of ResourceSet resources",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ListMeta{}.OpenAPIModelName()),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSet"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"metadata", "items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSet", v1.ListMeta{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_ResourceSetStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"conditions": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"type",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/wrangler/v3/pkg/genericcondition.GenericCondition"),
+									},
+								},
+							},
+						},
+					},
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"selectors": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Selectors lists what each resource selector selects in the cluster, in the order of resourceSelectors and the generated selectors, followed by the selectors of the included ResourceSets",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSelectorStatus"),
+									},
+								},
+							},
+						},
+					},
+					"objectCount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of objects selected by all the selectors, objects selected by several selectors are counted once per selector",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"lastResolvedTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time the selectors were last resolved against the cluster",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSelectorStatus", "github.com/rancher/wrangler/v3/pkg/genericcondition.GenericCondition"},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_Restore(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ObjectMeta{}.OpenAPIModelName()),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreStatus"),
+						},
+					},
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreSpec", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreStatus", v1.ObjectMeta{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_RestoreList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RestoreList is a list of Restore resources",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ListMeta{}.OpenAPIModelName()),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Restore"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"metadata", "items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Restore", v1.ListMeta{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_RestoreSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"backupFilename": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BackupFilename is the exact filename of the backup to restore. When unset, the backup is selected from the storage location by backupRef, latestFor and asOf.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"backupRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BackupRef selects a backup taken by the Backup CR of the given name",
+							Ref:         ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReference"),
+						},
+					},
+					"latestFor": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LatestFor selects the latest backup of the cluster whose kube-system namespace UID starts with this prefix",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"asOf": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AsOf only selects backups taken at or before this time, in RFC3339 format. On its own, it selects the latest backup taken at or before this time.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"storageLocation": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation"),
+						},
+					},
+					"prune": {
+						SchemaProps: spec.SchemaProps{
+							Description: "prune is true by default when unset",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"deleteTimeoutSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"encryptionConfigSecretName": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"archiveEncryptionSecretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the Secret containing the age identities or KMS plugin that decrypt an encrypted archive",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ignoreErrors": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When set to true, the controller ignores any errors during the restore process",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.BackupReference", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation"},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_RestoreStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"conditions": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"type",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/rancher/wrangler/v3/pkg/genericcondition.GenericCondition"),
+									},
+								},
+							},
+						},
+					},
+					"restoreCompletionTs": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"backupSource": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"backupFilename": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BackupFilename is the filename of the backup being restored, as selected when the restore started",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"backupFilenameGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BackupFilenameGeneration is the generation of the restore BackupFilename was selected for, the backup is selected again once the restore changes",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"summary": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/rancher/wrangler/v3/pkg/genericcondition.GenericCondition"},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_RetentionPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RetentionPolicy keeps the archives of a backup following grandfather-father-son rules, evaluated against the timestamps in the archives' filenames. An archive is kept when any rule keeps it, unless it is older than maxAge.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"keepHourly": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of most recent hours to keep the newest archive of",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"keepDaily": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of most recent days to keep the newest archive of",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"keepWeekly": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of most recent ISO weeks to keep the newest archive of",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"keepMonthly": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of most recent months to keep the newest archive of",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"maxAge": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Archives older than this are deleted, example \"720h\". When no keep rule is set, all younger archives are kept.",
+							Ref:         ref(v1.Duration{}.OpenAPIModelName()),
+						},
+					},
+					"minKeep": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of most recent archives that are always kept, regardless of the other rules",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			v1.Duration{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_resourcescattleio_v1_S3ObjectStore(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"endpoint": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"endpointCA": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"insecureTLSSkipVerify": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"credentialSecretName": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"credentialSecretNamespace": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"bucketName": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"region": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"folder": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"clientConfig": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ClientConfig"),
 						},
 					},
-					"apiVersion": {
+					"objectLock": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Ref: ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ObjectLock"),
+						},
+					},
+					"serverSideEncryption": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ServerSideEncryption"),
+						},
+					},
+					"storageClass": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Storage class of uploaded backup files, example \"STANDARD_IA\". The bucket's default storage class is used when unset.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"metadata": {
+					"tags": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref(v1.ListMeta{}.OpenAPIModelName()),
+							Description: "Tags set on uploaded backup files",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
 						},
 					},
-					"items": {
+					"metadata": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
+							Description: "User metadata set on uploaded backup files",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSet"),
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
 									},
 								},
 							},
 						},
 					},
 				},
-				Required: []string{"metadata", "items"},
+				Required: []string{"endpoint", "bucketName"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ResourceSet", v1.ListMeta{}.OpenAPIModelName()},
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ClientConfig", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ObjectLock", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ServerSideEncryption"},
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_Restore(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_SelectedResource(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "SelectedResource is a resource matched by a resource selector",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
 					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"metadata": {
-						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref(v1.ObjectMeta{}.OpenAPIModelName()),
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
-					"spec": {
+					"resource": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreSpec"),
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
-					"status": {
+					"objectCount": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreStatus"),
+							Default: 0,
+							Type:    []string{"integer"},
+							Format:  "int32",
 						},
 					},
 				},
-				Required: []string{"spec"},
+				Required: []string{"apiVersion", "resource", "objectCount"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreSpec", "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.RestoreStatus", v1.ObjectMeta{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_RestoreList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_SelectorOverlap(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "RestoreList is a list of Restore resources",
+				Description: "SelectorOverlap counts the objects of a resource selected by a selector of a ResourceSet which an earlier selector already selected",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"firstSelector": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Selector which first selected the objects",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SelectorReference"),
+						},
+					},
+					"selector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Selector which selected the objects again",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SelectorReference"),
 						},
 					},
 					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
-							Type:        []string{"string"},
-							Format:      "",
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
-					"metadata": {
+					"resource": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref(v1.ListMeta{}.OpenAPIModelName()),
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
-					"items": {
+					"objectCount": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Restore"),
-									},
-								},
-							},
+							Default: 0,
+							Type:    []string{"integer"},
+							Format:  "int32",
 						},
 					},
 				},
-				Required: []string{"metadata", "items"},
+				Required: []string{"firstSelector", "selector", "apiVersion", "resource", "objectCount"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.Restore", v1.ListMeta{}.OpenAPIModelName()},
+			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.SelectorReference"},
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_RestoreSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_SelectorReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "SelectorReference identifies a selector of the ResourceSet of a Backup, or of a ResourceSet it includes",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"backupFilename": {
-						SchemaProps: spec.SchemaProps{
-							Default: "",
-							Type:    []string{"string"},
-							Format:  "",
-						},
-					},
-					"storageLocation": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation"),
-						},
-					},
-					"prune": {
+					"resourceSet": {
 						SchemaProps: spec.SchemaProps{
-							Description: "prune is true by default when unset",
-							Type:        []string{"boolean"},
+							Description: "Name of the ResourceSet the selector belongs to",
+							Default:     "",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"deleteTimeoutSeconds": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
-						},
-					},
-					"encryptionConfigSecretName": {
+					"position": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Position of the selector, starting at 1, in the resourceSelectors of the ResourceSet, or in the selectors it generates when generated is true",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"ignoreErrors": {
+					"generated": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When set to true, the controller ignores any errors during the restore process",
+							Description: "Generated is true for a selector generated by the generate field of the ResourceSet",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
 				},
-				Required: []string{"backupFilename"},
+				Required: []string{"resourceSet", "position"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.StorageLocation"},
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_RestoreStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_ServerSideEncryption(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "ServerSideEncryption configures the S3 server-side encryption of uploaded backup files",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"conditions": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-map-keys": []interface{}{
-									"type",
-								},
-								"x-kubernetes-list-type": "map",
-							},
-						},
+					"type": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("github.com/rancher/wrangler/v3/pkg/genericcondition.GenericCondition"),
-									},
-								},
-							},
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
-					"restoreCompletionTs": {
+					"kmsKeyID": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "ID of the KMS key used by SSE-KMS, the bucket's default key is used when unset",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"observedGeneration": {
+					"kmsContext": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Encryption context used by SSE-KMS",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
 						},
 					},
-					"backupSource": {
+					"customerKeySecretName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Name of the Secret holding the 32 bytes key used by SSE-C in its sseCustomerKey field",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"summary": {
+					"customerKeySecretNamespace": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Namespace of the Secret holding the key used by SSE-C",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
+				Required: []string{"type"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/rancher/wrangler/v3/pkg/genericcondition.GenericCondition"},
 	}
 }
 
-func schema_pkg_apis_resourcescattleio_v1_S3ObjectStore(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_resourcescattleio_v1_SkippedResource(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "SkippedResource is a resource matched by a resource selector whose objects are not backed up",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"endpoint": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
 							Default: "",
 							Type:    []string{"string"},
 							Format:  "",
 						},
 					},
-					"endpointCA": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"insecureTLSSkipVerify": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
-						},
-					},
-					"credentialSecretName": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"credentialSecretNamespace": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"bucketName": {
+					"resource": {
 						SchemaProps: spec.SchemaProps{
 							Default: "",
 							Type:    []string{"string"},
 							Format:  "",
 						},
 					},
-					"region": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"folder": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"clientConfig": {
+					"reason": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ClientConfig"),
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
 				},
-				Required: []string{"endpoint", "bucketName"},
+				Required: []string{"apiVersion", "resource", "reason"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1.ClientConfig"},
 	}
 }
 
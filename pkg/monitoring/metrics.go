@@ -156,10 +156,16 @@ func updateRestoreMetrics(restores []v1.Restore) {
 			restoreMessage = r.Status.Conditions[0].Message
 		}
 
+		// backups selected by backupRef, latestFor or asOf only have their filename in the status
+		backupFilename := r.Spec.BackupFilename
+		if r.Status.BackupFilename != "" {
+			backupFilename = r.Status.BackupFilename
+		}
+
 		restore.WithLabelValues(
 			r.Name,
 			restoreMessage,
-			r.Spec.BackupFilename,
+			backupFilename,
 			strconv.FormatBool(r.Spec.GetPrune()),
 			r.Status.BackupSource,
 			r.Status.RestoreCompletionTS,
@@ -21,6 +21,7 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 	"github.com/minio/minio-go/v7/pkg/s3utils"
 	log "github.com/sirupsen/logrus"
 	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -38,7 +39,12 @@ type ObjectStore struct {
 	Region                    string `json:"region"`
 	Folder                    string `json:"folder"`
 	// TODO: this may need to be a string too
-	ClientConfig *v1.ClientConfig `json:"clientConfig,omitempty"`
+	ClientConfig         *v1.ClientConfig         `json:"clientConfig,omitempty"`
+	ObjectLock           *v1.ObjectLock           `json:"objectLock,omitempty"`
+	ServerSideEncryption *v1.ServerSideEncryption `json:"serverSideEncryption,omitempty"`
+	StorageClass         string                   `json:"storageClass,omitempty"`
+	Tags                 map[string]string        `json:"tags,omitempty"`
+	Metadata             map[string]string        `json:"metadata,omitempty"`
 }
 
 // Almost everything in this file is from rke-tools with some modifications https://github.com/rancher/rke-tools/blob/master/main.go
@@ -47,6 +53,8 @@ const (
 	s3ServerRetries = 3
 	s3Endpoint      = "s3.amazonaws.com"
 	contentType     = "application/gzip"
+	// sseCustomerKey is the field of the SSE-C key in its Secret
+	sseCustomerKey = "sseCustomerKey"
 )
 
 func SetS3Service(bc *v1.S3ObjectStore, accessKeyID, secretKey string, useSSL bool) (*minio.Client, error) {
@@ -197,11 +205,117 @@ func getBucketLookupType(bc *v1.S3ObjectStore) minio.BucketLookupType {
 	return minio.BucketLookupAuto
 }
 
-func UploadBackupFile(svc *minio.Client, bucketName, fileName, filePath string) error {
+// UploadOptions are the encryption, storage class, tags and object lock settings of an uploaded backup file
+type UploadOptions struct {
+	// ServerSideEncryption encrypts the backup file with the S3 provider's server-side encryption, the bucket's default encryption applies when unset
+	ServerSideEncryption encrypt.ServerSide
+	StorageClass         string
+	Tags                 map[string]string
+	Metadata             map[string]string
+	// RetentionMode and RetainUntil lock the backup file until the given time, the bucket's default retention applies when unset
+	RetentionMode minio.RetentionMode
+	RetainUntil   time.Time
+	LegalHold     bool
+}
+
+func (o UploadOptions) putObjectOptions() minio.PutObjectOptions {
+	opts := minio.PutObjectOptions{
+		ContentType:          contentType,
+		ServerSideEncryption: o.ServerSideEncryption,
+		StorageClass:         o.StorageClass,
+		UserTags:             o.Tags,
+		UserMetadata:         o.Metadata,
+	}
+	if o.RetentionMode != "" {
+		opts.Mode = o.RetentionMode
+		opts.RetainUntilDate = o.RetainUntil
+	}
+	if o.LegalHold {
+		opts.LegalHold = minio.LegalHoldEnabled
+	}
+	if opts.Mode != "" || opts.LegalHold != "" {
+		// S3 requires a checksum on requests setting object lock
+		opts.SendContentMd5 = true
+	}
+	return opts
+}
+
+// NewUploadOptions returns the server-side encryption, storage class, tags and metadata of the backup files uploaded to
+// the object store
+func NewUploadOptions(ctx context.Context, objectStore *v1.S3ObjectStore, dynamicClient dynamic.Interface) (UploadOptions, error) {
+	sse, err := GetServerSideEncryption(ctx, objectStore, dynamicClient)
+	if err != nil {
+		return UploadOptions{}, err
+	}
+	return UploadOptions{
+		ServerSideEncryption: sse,
+		StorageClass:         objectStore.StorageClass,
+		Tags:                 objectStore.Tags,
+		Metadata:             objectStore.Metadata,
+	}, nil
+}
+
+// GetServerSideEncryption returns the server-side encryption of the object store's backup files, nil when it is not configured.
+// The SSE-C key is read from its Secret, since the same key is needed to download the backup files.
+func GetServerSideEncryption(ctx context.Context, objectStore *v1.S3ObjectStore, dynamicClient dynamic.Interface) (encrypt.ServerSide, error) {
+	sse := objectStore.ServerSideEncryption
+	if sse == nil {
+		return nil, nil
+	}
+	switch sse.Type {
+	case v1.SSES3:
+		return encrypt.NewSSE(), nil
+	case v1.SSEKMS:
+		var kmsContext interface{}
+		if len(sse.KMSContext) > 0 {
+			kmsContext = sse.KMSContext
+		}
+		return encrypt.NewSSEKMS(sse.KMSKeyID, kmsContext)
+	case v1.SSEC:
+		if sse.CustomerKeySecretName == "" {
+			return nil, fmt.Errorf("SSE-C server-side encryption requires customerKeySecretName")
+		}
+		secretNs := sse.CustomerKeySecretNamespace
+		if secretNs == "" {
+			secretNs = objectStore.CredentialSecretNamespace
+		}
+		key, err := getSecretValue(ctx, dynamicClient, secretNs, sse.CustomerKeySecretName, sseCustomerKey)
+		if err != nil {
+			return nil, err
+		}
+		return encrypt.NewSSEC(key)
+	default:
+		return nil, fmt.Errorf("unsupported server-side encryption type [%s]", sse.Type)
+	}
+}
+
+// getSecretValue returns the decoded value of a key in the data field of a secret
+func getSecretValue(ctx context.Context, dynamicClient dynamic.Interface, secretNs, secretName, key string) ([]byte, error) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}
+	secret, err := dynamicClient.Resource(gvr).Namespace(secretNs).Get(ctx, secretName, k8sv1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	data, ok := secret.Object["data"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("malformed secret [%s] in namespace [%s], unable to read the data field", secretName, secretNs)
+	}
+	encoded, ok := data[key].(string)
+	if !ok {
+		return nil, fmt.Errorf("malformed secret [%s] in namespace [%s], the following keys were not found in the data field: [%s]", secretName, secretNs, key)
+	}
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("malformed secret [%s] in namespace [%s], %s could not be base64 decoded: %v", secretName, secretNs, key, err)
+	}
+	return value, nil
+}
+
+func UploadBackupFile(svc *minio.Client, bucketName, fileName, filePath string, options UploadOptions) error {
 	// Upload the zip file with FPutObject
 	log.Infof("invoking uploading backup file [%s] to s3", fileName)
 	for retries := 0; retries <= s3ServerRetries; retries++ {
-		uploadInfo, err := svc.FPutObject(context.Background(), bucketName, fileName, filePath, minio.PutObjectOptions{ContentType: contentType})
+		uploadInfo, err := svc.FPutObject(context.Background(), bucketName, fileName, filePath, options.putObjectOptions())
 		if err != nil {
 			log.Infof("failed to upload backup file [%s], error: %v, retried %d times", fileName, err, retries)
 			if retries >= s3ServerRetries {
@@ -216,7 +330,20 @@ func UploadBackupFile(svc *minio.Client, bucketName, fileName, filePath string)
 	return nil
 }
 
-func DownloadFromS3WithPrefix(client *minio.Client, prefix, bucket string) (string, error) {
+// IsObjectLocked returns true when the object is under an unexpired object lock retention or a legal hold.
+// Objects whose lock cannot be read, for instance in buckets without object lock, are considered unlocked.
+func IsObjectLocked(ctx context.Context, svc *minio.Client, bucketName, objectName string) bool {
+	mode, retainUntil, err := svc.GetObjectRetention(ctx, bucketName, objectName, "")
+	if err == nil && mode != nil && retainUntil != nil && retainUntil.After(time.Now()) {
+		return true
+	}
+	legalHold, err := svc.GetObjectLegalHold(ctx, bucketName, objectName, minio.GetObjectLegalHoldOptions{})
+	return err == nil && legalHold != nil && *legalHold == minio.LegalHoldEnabled
+}
+
+// DownloadFromS3WithPrefix downloads the backup file with the prefix as key to a temporary file. sse must be the
+// encryption the file was uploaded with, only SSE-C keys are sent along the download request.
+func DownloadFromS3WithPrefix(client *minio.Client, prefix, bucket string, sse encrypt.ServerSide) (string, error) {
 	var filename string
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -253,7 +380,7 @@ func DownloadFromS3WithPrefix(client *minio.Client, prefix, bucket string) (stri
 	var object *minio.Object
 	var err error
 	for retries := 0; retries <= s3ServerRetries; retries++ {
-		object, err = client.GetObject(context.Background(), bucket, filename, minio.GetObjectOptions{})
+		object, err = client.GetObject(context.Background(), bucket, filename, minio.GetObjectOptions{ServerSideEncryption: sse})
 		if err != nil {
 			log.Infof("Failed to download backup file [%s] from bucket [%s]: %v, retried %d times", filename, bucket, err, retries)
 			if retries >= s3ServerRetries {
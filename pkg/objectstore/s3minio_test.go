@@ -1,11 +1,19 @@
 package objectstore
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 )
 
 func Test_redactAccessKeyID(t *testing.T) {
@@ -135,3 +143,98 @@ func Test_getBucketLookupType(t *testing.T) {
 		})
 	}
 }
+
+func Test_putObjectOptions(t *testing.T) {
+	sse := encrypt.NewSSE()
+	options := UploadOptions{
+		ServerSideEncryption: sse,
+		StorageClass:         "STANDARD_IA",
+		Tags:                 map[string]string{"team": "platform"},
+		Metadata:             map[string]string{"cluster": "local"},
+	}
+
+	opts := options.putObjectOptions()
+
+	assert.Equal(t, contentType, opts.ContentType)
+	assert.Equal(t, sse, opts.ServerSideEncryption)
+	assert.Equal(t, "STANDARD_IA", opts.StorageClass)
+	assert.Equal(t, map[string]string{"team": "platform"}, opts.UserTags)
+	assert.Equal(t, map[string]string{"cluster": "local"}, opts.UserMetadata)
+	assert.False(t, opts.SendContentMd5, "checksums are only required when locking objects")
+}
+
+func TestGetServerSideEncryption(t *testing.T) {
+	customerKey := strings.Repeat("k", 32)
+	secrets := []runtime.Object{
+		&corev1.Secret{
+			ObjectMeta: k8sv1.ObjectMeta{Name: "sse-key", Namespace: "cattle-resources-system"},
+			Data:       map[string][]byte{sseCustomerKey: []byte(customerKey)},
+		},
+		&corev1.Secret{
+			ObjectMeta: k8sv1.ObjectMeta{Name: "short-key", Namespace: "cattle-resources-system"},
+			Data:       map[string][]byte{sseCustomerKey: []byte("short")},
+		},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme, secrets...)
+
+	tests := []struct {
+		name     string
+		sse      *v1.ServerSideEncryption
+		expected encrypt.Type
+		wantErr  bool
+	}{
+		{
+			name: "not configured",
+			sse:  nil,
+		},
+		{
+			name:     "SSE-S3",
+			sse:      &v1.ServerSideEncryption{Type: v1.SSES3},
+			expected: encrypt.S3,
+		},
+		{
+			name:     "SSE-KMS with key and context",
+			sse:      &v1.ServerSideEncryption{Type: v1.SSEKMS, KMSKeyID: "alias/backups", KMSContext: map[string]string{"cluster": "local"}},
+			expected: encrypt.KMS,
+		},
+		{
+			name:     "SSE-C with the key secret in the credential secret namespace",
+			sse:      &v1.ServerSideEncryption{Type: v1.SSEC, CustomerKeySecretName: "sse-key"},
+			expected: encrypt.SSEC,
+		},
+		{
+			name:    "SSE-C without key secret",
+			sse:     &v1.ServerSideEncryption{Type: v1.SSEC},
+			wantErr: true,
+		},
+		{
+			name:    "SSE-C with a key of the wrong size",
+			sse:     &v1.ServerSideEncryption{Type: v1.SSEC, CustomerKeySecretName: "short-key"},
+			wantErr: true,
+		},
+		{
+			name:    "SSE-C with a missing key secret",
+			sse:     &v1.ServerSideEncryption{Type: v1.SSEC, CustomerKeySecretName: "missing"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objectStore := &v1.S3ObjectStore{CredentialSecretNamespace: "cattle-resources-system", ServerSideEncryption: tt.sse}
+			sse, err := GetServerSideEncryption(context.Background(), objectStore, dynamicClient)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.sse == nil {
+				assert.Nil(t, sse)
+				return
+			}
+			require.NotNil(t, sse)
+			assert.Equal(t, tt.expected, sse.Type())
+		})
+	}
+}
@@ -6,14 +6,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	backupv1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
 	"github.com/rancher/backup-restore-operator/pkg/controllers/backup"
+	"github.com/rancher/backup-restore-operator/pkg/controllers/backuparchive"
+	"github.com/rancher/backup-restore-operator/pkg/controllers/backupreencrypt"
+	"github.com/rancher/backup-restore-operator/pkg/controllers/resourceset"
 	"github.com/rancher/backup-restore-operator/pkg/controllers/restore"
 	"github.com/rancher/backup-restore-operator/pkg/generated/controllers/resources.cattle.io"
 	"github.com/rancher/backup-restore-operator/pkg/monitoring"
 	"github.com/rancher/backup-restore-operator/pkg/objectstore"
 	"github.com/rancher/backup-restore-operator/pkg/util"
+	"github.com/rancher/backup-restore-operator/pkg/webhook"
 	lasso "github.com/rancher/lasso/pkg/client"
 	"github.com/rancher/lasso/pkg/mapper"
 	v1core "github.com/rancher/wrangler/v3/pkg/generated/controllers/core"
@@ -32,6 +38,13 @@ var (
 	LocalBackupStorageLocation = "/var/lib/backups" // local within the pod, this is the mountPath for PVC
 )
 
+// defaultCatalogSyncInterval is how often BackupArchives are synced from storage when RunOptions don't set it
+const defaultCatalogSyncInterval = 5 * time.Minute
+
+// defaultResourceSetResolveInterval is how often the selectors of ResourceSets are resolved again when RunOptions
+// don't set it
+const defaultResourceSetResolveInterval = 10 * time.Minute
+
 type RunOptions struct {
 	OperatorPVCEnabled              bool
 	MetricsServerEnabled            bool
@@ -41,6 +54,19 @@ type RunOptions struct {
 	ChartNamespace                  string
 	LocalDriverPath                 string
 	LocalEncryptionProviderLocation string
+	// CatalogSyncIntervalSeconds is how often BackupArchives are synced from storage, every 5 minutes when unset
+	CatalogSyncIntervalSeconds int
+	OrphanArchiveRetention     time.Duration
+	// ResourceSetResolveIntervalSeconds is how often the selectors of ResourceSets are resolved again to report what
+	// they select in their status, every 10 minutes when unset
+	ResourceSetResolveIntervalSeconds int
+	// RequireEncryption makes every Backup fail instead of storing sensitive resources unencrypted, Backups can't opt out
+	RequireEncryption bool
+	// SensitiveResources must be encrypted in addition to secrets when encryption is required
+	SensitiveResources []string
+	// WebhookCertDir holds the certificate of the validating admission webhook, which is served when it is set
+	WebhookCertDir string
+	WebhookPort    int
 }
 
 func (o *RunOptions) Validate() error {
@@ -64,6 +90,18 @@ func (o *RunOptions) Validate() error {
 	if o.MetricsServerEnabled && o.MetricsIntervalSeconds <= 0 {
 		return fmt.Errorf("invalid metrics interval : %d", o.MetricsIntervalSeconds)
 	}
+
+	if o.WebhookCertDir != "" && o.WebhookPort <= 0 {
+		return fmt.Errorf("invalid webhook port : %d", o.WebhookPort)
+	}
+
+	if o.CatalogSyncIntervalSeconds < 0 {
+		return fmt.Errorf("invalid backup archive catalog sync interval : %d", o.CatalogSyncIntervalSeconds)
+	}
+
+	if o.ResourceSetResolveIntervalSeconds < 0 {
+		return fmt.Errorf("invalid resource set resolve interval : %d", o.ResourceSetResolveIntervalSeconds)
+	}
 	return nil
 }
 
@@ -83,6 +121,24 @@ func (o *RunOptions) shouldRunMetricsServer() bool {
 	return o.MetricsServerEnabled
 }
 
+func (o *RunOptions) shouldServeWebhook() bool {
+	return o.WebhookCertDir != ""
+}
+
+func (o *RunOptions) catalogSyncInterval() time.Duration {
+	if o.CatalogSyncIntervalSeconds == 0 {
+		return defaultCatalogSyncInterval
+	}
+	return time.Duration(o.CatalogSyncIntervalSeconds) * time.Second
+}
+
+func (o *RunOptions) resourceSetResolveInterval() time.Duration {
+	if o.ResourceSetResolveIntervalSeconds == 0 {
+		return defaultResourceSetResolveInterval
+	}
+	return time.Duration(o.ResourceSetResolveIntervalSeconds) * time.Second
+}
+
 type ControllerOptions struct {
 	mapper        meta.RESTMapper
 	clientSet     *clientset.Clientset
@@ -146,6 +202,11 @@ func FetchDefaultS3Configuration(options RunOptions, core *v1core.Factory) (*bac
 
 	stringData := make(map[string]interface{})
 	for key, val := range s3Secret.Data {
+		// nested settings such as clientConfig, objectLock and tags are stored as JSON objects
+		if json.Valid(val) && strings.HasPrefix(strings.TrimSpace(string(val)), "{") {
+			stringData[key] = json.RawMessage(val)
+			continue
+		}
 		stringData[key] = string(val)
 	}
 
@@ -168,6 +229,11 @@ func FetchDefaultS3Configuration(options RunOptions, core *v1core.Factory) (*bac
 		Region:                    objStoreWithStrSkipVerify.Region,
 		Folder:                    objStoreWithStrSkipVerify.Folder,
 		ClientConfig:              objStoreWithStrSkipVerify.ClientConfig,
+		ObjectLock:                objStoreWithStrSkipVerify.ObjectLock,
+		ServerSideEncryption:      objStoreWithStrSkipVerify.ServerSideEncryption,
+		StorageClass:              objStoreWithStrSkipVerify.StorageClass,
+		Tags:                      objStoreWithStrSkipVerify.Tags,
+		Metadata:                  objStoreWithStrSkipVerify.Metadata,
 	}
 
 	if objStoreWithStrSkipVerify.InsecureTLSSkipVerify == "true" {
@@ -260,6 +326,11 @@ func Run(
 		defaultS3,
 		metricsServerEnabled,
 		encryptionProviderLocation,
+		backup.EncryptionPolicy{
+			RequireEncryption:  options.RequireEncryption,
+			SensitiveResources: options.SensitiveResources,
+		},
+		options.shouldServeWebhook(),
 	)
 	restore.Register(ctx,
 		c.backupFactory.Resources().V1().Restore(),
@@ -276,6 +347,50 @@ func Run(
 		encryptionProviderLocation,
 	)
 
+	backupreencrypt.Register(ctx,
+		c.backupFactory.Resources().V1().BackupReencrypt(),
+		c.backupFactory.Resources().V1().Backup(),
+		c.core.Core().V1().Secret(),
+		c.core.Core().V1().Namespace(),
+		c.dynamic,
+		defaultMountPath,
+		defaultS3,
+		encryptionProviderLocation,
+	)
+
+	backuparchive.Register(ctx,
+		c.backupFactory.Resources().V1().BackupArchive(),
+		c.backupFactory.Resources().V1().Backup(),
+		c.core.Core().V1().Namespace(),
+		c.dynamic,
+		defaultMountPath,
+		defaultS3,
+		options.catalogSyncInterval(),
+		options.OrphanArchiveRetention,
+	)
+
+	resourceset.Register(ctx,
+		c.backupFactory.Resources().V1().ResourceSet(),
+		c.clientSet.Discovery(),
+		c.dynamic,
+		c.clientSet.ApiextensionsV1().CustomResourceDefinitions(),
+		c.core.Core().V1().Secret(),
+		options.resourceSetResolveInterval(),
+	)
+
+	if options.shouldServeWebhook() {
+		validator := webhook.NewValidator(
+			c.core.Core().V1().Secret(),
+			c.backupFactory.Resources().V1().ResourceSet(),
+			defaultMountPath != "" || defaultS3 != nil,
+		)
+		go func() {
+			if err := webhook.ListenAndServe(ctx, options.WebhookPort, options.WebhookCertDir, validator); err != nil {
+				logrus.Fatalf("Error serving the admission webhooks: %s", err.Error())
+			}
+		}()
+	}
+
 	if err := start.All(ctx, 2, c.backupFactory); err != nil {
 		logrus.Fatalf("Error starting: %s", err.Error())
 	}
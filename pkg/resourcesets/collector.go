@@ -10,7 +10,9 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/google/cel-go/cel"
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/archiveformat"
 	"github.com/sirupsen/logrus"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,17 +38,32 @@ type ResourceHandler struct {
 	DynamicClient       dynamic.Interface
 	TransformerMap      k8sEncryptionconfig.StaticTransformers
 	GVResourceToObjects map[GVResource][]unstructured.Unstructured
-	Ctx                 context.Context
+	// Overlaps lists the objects gathered by several selectors, which are kept once in GVResourceToObjects
+	Overlaps []SelectorOverlap
+	Ctx      context.Context
+	// selectedNamespaces caches the namespaces matching each namespace selector while gathering resources
+	selectedNamespaces map[string]map[string]bool
+	// coverage records the group versions and resources skipped while gathering the resources of a selector, when set
+	coverage *SelectorCoverage
+	// matchPrograms caches the compiled match expressions of the selectors while gathering resources
+	matchPrograms map[string]cel.Program
+	// identitiesOnly keeps only the identity of the objects gathered, when what matters is which objects are selected
+	// and not their content
+	identitiesOnly bool
 }
 
+var namespacesResource = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
 /*
 	  GatherResources iterates over the ResourceSelectors in the given ResourceSet
-	   	Each ResourceSelector can specify only one apigroupversion, example "v1" or "management.cattle.io/v3"
+	   	Each ResourceSelector can specify only one apigroupversion, example "v1" or "management.cattle.io/v3",
+		or an APIGroupRegexp to select the preferred version of every API group whose name matches
 		ResourceSelector can specify resource types/kinds to backup from this apigroupversion through Kinds and KindsRegexp.
 		Resources matching Kinds and KindsRegexp both will be backed up
 		ResourceSelector can also specify names of particular resources of this groupversionkind to backup, using ResourceNames and ResourceNamesRegex
-		It can specify namespaces from which to backup these resources through Namespaces and NamespacesRegex
+		It can specify namespaces from which to backup these resources through Namespaces, NamespacesRegex and NamespaceSelector
 		And it can provide a labelSelector to backup resources of this gvk+name+ns combination containing some label
+		and a CEL match expression evaluated on the content of each of these objects
 		For each value that has two fields, for regex and an array of exact names GatherResources performs OR
 		But it performs AND for separate selector types, example:
 		apiversion: v1
@@ -54,70 +71,155 @@ type ResourceHandler struct {
 		resourceNamesRegex: "^cattle-|^p-|^c-|^user-|^u-"
 		resourceNames: "local"
 		All namespaces that match resourceNamesRegex, also local ns is backed up
+		An object selected by several selectors is gathered once, the overlaps between selectors are listed in Overlaps
+		The objects matching any of the excludeSelectors are then removed from the gathered objects, whichever
+		version of their group they were gathered in
 */
-func (h *ResourceHandler) GatherResources(ctx context.Context, resourceSelectors []v1.ResourceSelector) error {
-	h.GVResourceToObjects = make(map[GVResource][]unstructured.Unstructured)
+func (h *ResourceHandler) GatherResources(ctx context.Context, resourceSelectors, excludeSelectors []v1.ResourceSelector) error {
+	h.selectedNamespaces = nil
+	h.Overlaps = nil
+
+	// the objects of each selector are gathered on their own, so that objects selected again are dropped and reported
+	deduplicator := newObjectDeduplicator()
+	for i, resourceSelector := range resourceSelectors {
+		h.GVResourceToObjects = make(map[GVResource][]unstructured.Unstructured)
+		if err := h.gatherResourcesForSelector(ctx, resourceSelector); err != nil {
+			return err
+		}
+		deduplicator.add(i, h.GVResourceToObjects)
+	}
+	h.GVResourceToObjects = deduplicator.objects
+	h.Overlaps = deduplicator.selectorOverlaps()
+	if len(excludeSelectors) == 0 {
+		return nil
+	}
+	excluded, err := h.gatherExcluded(ctx, excludeSelectors)
+	if err != nil {
+		return err
+	}
+	h.removeExcluded(excluded)
+	return nil
+}
 
-	for _, resourceSelector := range resourceSelectors {
-		resourceList, err := h.gatherResourcesForGroupVersion(resourceSelector)
+// gatherResourcesForSelector gathers the objects matching the ResourceSelector in each group version it selects
+func (h *ResourceHandler) gatherResourcesForSelector(ctx context.Context, resourceSelector v1.ResourceSelector) error {
+	groupVersions, err := h.groupVersionsForSelector(resourceSelector)
+	if err != nil {
+		return err
+	}
+	for _, gv := range groupVersions {
+		if err := h.gatherResourcesForGroupVersionSelector(ctx, gv, resourceSelector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupVersionsForSelector returns the group versions a ResourceSelector selects resources from: its APIVersion, or the
+// preferred version of each API group matching its APIGroupRegexp
+func (h *ResourceHandler) groupVersionsForSelector(filter v1.ResourceSelector) ([]schema.GroupVersion, error) {
+	if filter.APIVersion != "" && filter.APIGroupRegexp != "" {
+		return nil, fmt.Errorf("apiVersion %v and apiGroupRegexp %v can't be combined in a resource selector", filter.APIVersion, filter.APIGroupRegexp)
+	}
+	if filter.APIGroupRegexp == "" {
+		if filter.APIVersion == "" {
+			return nil, fmt.Errorf("resource selector requires one of apiVersion or apiGroupRegexp")
+		}
+		gv, err := schema.ParseGroupVersion(filter.APIVersion)
 		if err != nil {
-			return fmt.Errorf("error gathering resource for %v: %v", resourceSelector.APIVersion, err)
+			return nil, err
+		}
+		return []schema.GroupVersion{gv}, nil
+	}
+
+	groupRegexp, err := regexp.Compile(filter.APIGroupRegexp)
+	if err != nil {
+		return nil, fmt.Errorf("error in apiGroupRegexp pattern %s: %w", filter.APIGroupRegexp, err)
+	}
+	groups, err := h.DiscoveryClient.ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("error listing API groups for apiGroupRegexp %s: %v", filter.APIGroupRegexp, err)
+	}
+	var groupVersions []schema.GroupVersion
+	for _, group := range groups.Groups {
+		// "." matches every group, the core group included
+		if filter.APIGroupRegexp != "." && !groupRegexp.MatchString(group.Name) {
+			continue
 		}
-		gv, err := schema.ParseGroupVersion(resourceSelector.APIVersion)
+		// only the preferred version is gathered, the objects served by the other versions of the group are the same
+		gv, err := schema.ParseGroupVersion(group.PreferredVersion.GroupVersion)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		currGVResource := GVResource{GroupVersion: gv}
-		for _, res := range resourceList {
-			currGVResource.Name = res.Name
-			currGVResource.Namespaced = res.Namespaced
+		logrus.Debugf("Using preferred version %v of API group %q matching apiGroupRegexp %s", gv, group.Name, filter.APIGroupRegexp)
+		groupVersions = append(groupVersions, gv)
+	}
+	return groupVersions, nil
+}
 
-			if strings.Contains(res.Name, "/") {
-				logrus.Debugf("Skipped backing up subresource: %s", res.Name)
-				continue
-			}
+// gatherResourcesForGroupVersionSelector gathers the objects of the group version gv which match the ResourceSelector
+func (h *ResourceHandler) gatherResourcesForGroupVersionSelector(ctx context.Context, gv schema.GroupVersion, resourceSelector v1.ResourceSelector) error {
+	resourceList, err := h.gatherResourcesForGroupVersion(gv, resourceSelector)
+	if err != nil {
+		return fmt.Errorf("error gathering resource for %v: %v", gv, err)
+	}
+	currGVResource := GVResource{GroupVersion: gv}
+	for _, res := range resourceList {
+		currGVResource.Name = res.Name
+		currGVResource.Namespaced = res.Namespaced
 
-			if !canListResource(res.Verbs) {
-				if canGetResource(res.Verbs) {
-					filteredObjects, err := h.gatherObjectsForNonListResource(ctx, res, gv, resourceSelector)
-					if err != nil {
-						return err
-					}
-					h.GVResourceToObjects[currGVResource] = filteredObjects
-				} else {
-					logrus.Infof("Not collecting objects for resource %v since it does not have list or get verbs", res.Name)
-				}
-				continue
-			}
+		if strings.Contains(res.Name, "/") {
+			logrus.Debugf("Skipped backing up subresource: %s", res.Name)
+			continue
+		}
 
-			filteredObjects, err := h.gatherObjectsForResource(ctx, res, gv, resourceSelector)
-			if err != nil {
-				return err
-			}
-			// currGVResource contains GV for resource type, its name and if its namespaced or not,
-			// example: gv=v1, name=secrets, namespaced=true; filteredObjects are all the objects matching the resourceSelector
-			previouslyGatheredForGVR, ok := h.GVResourceToObjects[currGVResource]
-			if ok {
-				h.GVResourceToObjects[currGVResource] = append(previouslyGatheredForGVR, filteredObjects...)
+		if !canListResource(res.Verbs) {
+			if canGetResource(res.Verbs) {
+				filteredObjects, err := h.gatherObjectsForNonListResource(ctx, res, gv, resourceSelector)
+				if err != nil {
+					return err
+				}
+				if filteredObjects, err = h.filterByMatch(resourceSelector.Match, filteredObjects); err != nil {
+					return err
+				}
+				h.GVResourceToObjects[currGVResource] = h.keep(filteredObjects)
 			} else {
-				h.GVResourceToObjects[currGVResource] = filteredObjects
+				logrus.Infof("Not collecting objects for resource %v since it does not have list or get verbs", res.Name)
+				h.recordSkippedResource(currGVResource, "resource can't be listed or get")
 			}
+			continue
+		}
+
+		filteredObjects, err := h.gatherObjectsForResource(ctx, res, gv, resourceSelector)
+		if err != nil {
+			return err
+		}
+		// currGVResource contains GV for resource type, its name and if its namespaced or not,
+		// example: gv=v1, name=secrets, namespaced=true; filteredObjects are all the objects matching the resourceSelector
+		previouslyGatheredForGVR, ok := h.GVResourceToObjects[currGVResource]
+		if ok {
+			h.GVResourceToObjects[currGVResource] = append(previouslyGatheredForGVR, filteredObjects...)
+		} else {
+			h.GVResourceToObjects[currGVResource] = filteredObjects
 		}
 	}
 	return nil
 }
 
-func (h *ResourceHandler) gatherResourcesForGroupVersion(filter v1.ResourceSelector) ([]k8sv1.APIResource, error) {
+func (h *ResourceHandler) gatherResourcesForGroupVersion(gv schema.GroupVersion, filter v1.ResourceSelector) ([]k8sv1.APIResource, error) {
 	var resourceList []k8sv1.APIResource
 
-	groupVersion := filter.APIVersion
+	groupVersion := gv.String()
 	logrus.Debugf("Gathering resources for groupVersion: %v", groupVersion)
 
 	// first list all resources for given groupversion using discovery API
 	resources, err := h.DiscoveryClient.ServerResourcesForGroupVersion(groupVersion)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			logrus.Debugf("No resources found for groupVersion %v, skipping it", groupVersion)
+			logrus.Warnf("No resources found for groupVersion %v, it is not served by the cluster, skipping it", groupVersion)
+			if h.coverage != nil {
+				h.coverage.UnknownGroupVersions = append(h.coverage.UnknownGroupVersions, groupVersion)
+			}
 			return resourceList, nil
 		}
 		return resourceList, err
@@ -131,31 +233,57 @@ func (h *ResourceHandler) gatherObjectsForResource(ctx context.Context, res k8sv
 
 	// only resources that match name+namespace and label/field selector combination will be backed up, so we can filter in any order
 	// however, in practice filtering by label happens at an API level when we paginate the resources (creating our initial list)
-	resourcesFromAPIServer, err := h.fetchResourcesFromAPIServer(ctx, dr, filter)
-	if err != nil {
-		return nil, err
-	}
+	// each page is filtered as it is listed, so that the objects which are not selected are not all held at once
+	var filteredObjects []unstructured.Unstructured
+	err := h.fetchResourcesFromAPIServer(ctx, dr, filter, func(page []unstructured.Unstructured) error {
+		filteredByName, err := h.filterByName(filter, page)
+		if err != nil {
+			return err
+		}
 
-	filteredByName, err := h.filterByName(filter, resourcesFromAPIServer.Items)
+		if res.Namespaced && (len(filter.Namespaces) > 0 || filter.NamespaceRegexp != "" || filter.NamespaceSelector != nil) {
+			if filteredByName, err = h.filterByNamespace(ctx, filter, filteredByName); err != nil {
+				return err
+			}
+		}
+		// match expressions filter on the content of the objects, which label and field selectors can't
+		filteredByMatch, err := h.filterByMatch(filter.Match, filteredByName)
+		if err != nil {
+			return err
+		}
+		filteredObjects = append(filteredObjects, h.keep(filteredByMatch)...)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return filteredObjects, nil
+}
 
-	if res.Namespaced && (len(filter.Namespaces) > 0 || filter.NamespaceRegexp != "") {
-		return h.filterByNamespace(filter, filteredByName)
+// keep returns the objects to keep of the objects gathered, only their identity when the handler keeps identities only
+func (h *ResourceHandler) keep(objects []unstructured.Unstructured) []unstructured.Unstructured {
+	if !h.identitiesOnly {
+		return objects
 	}
-	return filteredByName, nil
+	identities := make([]unstructured.Unstructured, len(objects))
+	for i, obj := range objects {
+		identities[i].SetAPIVersion(obj.GetAPIVersion())
+		identities[i].SetKind(obj.GetKind())
+		identities[i].SetNamespace(obj.GetNamespace())
+		identities[i].SetName(obj.GetName())
+	}
+	return identities
 }
 
-// fetchResourcesFromAPIServer uses a label selector and/or field selector from the ResourceSelector to fetch, unroll, and return the initial list of objects
-func (h *ResourceHandler) fetchResourcesFromAPIServer(ctx context.Context, dr dynamic.ResourceInterface, filter v1.ResourceSelector) (*unstructured.UnstructuredList, error) {
+// fetchResourcesFromAPIServer uses a label selector and/or field selector from the ResourceSelector to list the initial objects page by page, passing each page to visit
+func (h *ResourceHandler) fetchResourcesFromAPIServer(ctx context.Context, dr dynamic.ResourceInterface, filter v1.ResourceSelector, visit func([]unstructured.Unstructured) error) error {
 	var labelSelector string
 	var fieldSelector string
 
 	if filter.LabelSelectors != nil {
 		selector, err := k8sv1.LabelSelectorAsSelector(filter.LabelSelectors)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		labelSelector = selector.String()
 		logrus.Debugf("Listing objects using label selector %v", labelSelector)
@@ -166,7 +294,7 @@ func (h *ResourceHandler) fetchResourcesFromAPIServer(ctx context.Context, dr dy
 		logrus.Debugf("Listing objects using field selector %v", fieldSelector)
 	}
 
-	return unrollPaginatedListResult(ctx, dr, k8sv1.ListOptions{LabelSelector: labelSelector, FieldSelector: fieldSelector})
+	return listPages(ctx, dr, k8sv1.ListOptions{LabelSelector: labelSelector, FieldSelector: fieldSelector}, visit)
 }
 
 func (h *ResourceHandler) filterByKind(filter v1.ResourceSelector, apiResources []k8sv1.APIResource) ([]k8sv1.APIResource, error) {
@@ -266,11 +394,11 @@ func (h *ResourceHandler) filterByName(filter v1.ResourceSelector, resourceObjec
 	return filteredByName, nil
 }
 
-func (h *ResourceHandler) filterByNamespace(filter v1.ResourceSelector, filteredByName []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+func (h *ResourceHandler) filterByNamespace(ctx context.Context, filter v1.ResourceSelector, filteredByName []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
 	var filteredObjects []unstructured.Unstructured
 	var namespaceRegexp *regexp.Regexp
 
-	if len(filter.Namespaces) == 0 && filter.NamespaceRegexp == "" {
+	if len(filter.Namespaces) == 0 && filter.NamespaceRegexp == "" && filter.NamespaceSelector == nil {
 		return filteredByName, nil
 	}
 	if filter.NamespaceRegexp != "" {
@@ -285,6 +413,15 @@ func (h *ResourceHandler) filterByNamespace(filter v1.ResourceSelector, filtered
 	for _, ns := range filter.Namespaces {
 		allowedNamespaces[ns] = true
 	}
+	if filter.NamespaceSelector != nil {
+		selectedNamespaces, err := h.namespacesForSelector(ctx, filter.NamespaceSelector)
+		if err != nil {
+			return nil, err
+		}
+		for ns := range selectedNamespaces {
+			allowedNamespaces[ns] = true
+		}
+	}
 	for _, resObj := range filteredByName {
 		namespace := resObj.GetNamespace()
 		if allowedNamespaces[namespace] ||
@@ -295,6 +432,48 @@ func (h *ResourceHandler) filterByNamespace(filter v1.ResourceSelector, filtered
 	return filteredObjects, nil
 }
 
+// namespacesForSelector returns the names of the namespaces whose labels match the selector
+func (h *ResourceHandler) namespacesForSelector(ctx context.Context, namespaceSelector *k8sv1.LabelSelector) (map[string]bool, error) {
+	selector, err := k8sv1.LabelSelectorAsSelector(namespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("error in namespaceSelector: %w", err)
+	}
+	if namespaces, ok := h.selectedNamespaces[selector.String()]; ok {
+		return namespaces, nil
+	}
+	logrus.Debugf("Listing namespaces using label selector %v", selector.String())
+	namespaceList, err := unrollPaginatedListResult(ctx, h.DynamicClient.Resource(namespacesResource), k8sv1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("error listing namespaces matching namespaceSelector %v: %w", selector.String(), err)
+	}
+	namespaces := make(map[string]bool, len(namespaceList.Items))
+	for _, namespace := range namespaceList.Items {
+		namespaces[namespace.GetName()] = true
+	}
+	if h.selectedNamespaces == nil {
+		h.selectedNamespaces = make(map[string]map[string]bool)
+	}
+	h.selectedNamespaces[selector.String()] = namespaces
+	return namespaces, nil
+}
+
+// listPages lists the objects of dr ListObjectsLimit at a time, passing each page to visit
+func listPages(ctx context.Context, dr dynamic.ResourceInterface, listOptions k8sv1.ListOptions, visit func([]unstructured.Unstructured) error) error {
+	listOptions.Limit = ListObjectsLimit
+	for {
+		page, err := dr.List(ctx, listOptions)
+		if err != nil {
+			return err
+		}
+		if err := visit(page.Items); err != nil {
+			return err
+		}
+		if listOptions.Continue = page.GetContinue(); listOptions.Continue == "" {
+			return nil
+		}
+	}
+}
+
 func unrollPaginatedListResult(ctx context.Context, dr dynamic.ResourceInterface, listOptions k8sv1.ListOptions) (*unstructured.UnstructuredList, error) {
 	var resourceObjectsList *unstructured.UnstructuredList
 	listOptions.Limit = ListObjectsLimit
@@ -325,11 +504,13 @@ func (h *ResourceHandler) gatherObjectsForNonListResource(ctx context.Context, r
 	// these objects
 	if len(filter.ResourceNames) == 0 {
 		logrus.Infof("Cannot get objects for res %v since it doesn't allow list, and no resource names are provided", res.Name)
+		h.recordSkippedResource(GVResource{GroupVersion: gv, Name: res.Name, Namespaced: res.Namespaced}, "resource can't be listed and no resourceNames are set")
 		return gatheredObjects, nil
 	}
 
 	if res.Namespaced && len(filter.Namespaces) == 0 {
 		logrus.Infof("Cannot get objects for res %v since it doesn't allow list, and no namespaces are provided", res.Name)
+		h.recordSkippedResource(GVResource{GroupVersion: gv, Name: res.Name, Namespaced: res.Namespaced}, "resource can't be listed and no namespaces are set")
 		return gatheredObjects, nil
 	}
 
@@ -361,7 +542,11 @@ func (h *ResourceHandler) gatherObjectsForNonListResource(ctx context.Context, r
 	return gatheredObjects, nil
 }
 
-func (h *ResourceHandler) WriteBackupObjects(backupPath string) error {
+// WriteBackupObjects writes the gathered objects to backupPath and returns the number of objects written
+func (h *ResourceHandler) WriteBackupObjects(backupPath string) (int, error) {
+	written := 0
+	names := archiveformat.Names{}
+	layout := archiveformat.NewLayout(names)
 	for gvResource, resObjects := range h.GVResourceToObjects {
 		for _, resObj := range resObjects {
 			metadata := resObj.Object["metadata"].(map[string]interface{})
@@ -383,41 +568,50 @@ func (h *ResourceHandler) WriteBackupObjects(backupPath string) error {
 			}
 
 			objName := metadata["name"].(string)
-			objFilename := objName
 
 			// TODO: confirm-test deletionTimestamp needs to be dropped
 			for _, field := range []string{"uid", "creationTimestamp", "deletionTimestamp", "selfLink", "resourceVersion", "deletionGracePeriodSeconds"} {
 				delete(metadata, field)
 			}
 			gv := gvResource.GroupVersion
-			resourcePath := backupPath + "/" + gvResource.Name + "." + gv.Group + "#" + gv.Version
-			if err := createResourceDir(resourcePath); err != nil {
-				return err
-			}
-
-			gr := schema.ParseGroupResource(gvResource.Name + "." + gv.Group)
-			encryptionTransformer := h.TransformerMap.TransformerForResource(gr)
-			additionalAuthenticatedData := objName
+			resource := archiveformat.Resource{GVR: gv.WithResource(gvResource.Name), Name: objName}
 			if gvResource.Namespaced {
-				additionalAuthenticatedData = fmt.Sprintf("%s#%s", metadata["namespace"].(string), additionalAuthenticatedData)
 				/*Max length in k8s is 253 characters for names of resources, for instance for serviceaccount.
 				And max length of filename on UNIX is 255, so we risk going over max filename length by storing namespace in the filename,
-				hence create a separate subdir for namespaced resources*/
-				objNs := metadata["namespace"].(string)
-				resourcePath = filepath.Join(resourcePath, objNs)
-				if err := createResourceDir(resourcePath); err != nil {
-					return err
-				}
+				hence the layout has a separate subdir for namespaced resources*/
+				resource.Namespace = metadata["namespace"].(string)
 			}
+			// names which can't be used as filenames are encoded and recorded in names
+			resourceFile := filepath.Join(backupPath, filepath.FromSlash(layout.Path(resource)))
+			resourcePath := filepath.Dir(resourceFile)
+			if err := createResourceDir(resourcePath); err != nil {
+				return written, err
+			}
+
+			encryptionTransformer := h.TransformerMap.TransformerForResource(resource.GVR.GroupResource())
 
 			// TODO: POST-preview-2: collect all objects first and then write??
-			err := writeToBackup(h.Ctx, resObj.Object, resourcePath, objFilename, encryptionTransformer, additionalAuthenticatedData)
+			err := writeToBackup(h.Ctx, resObj.Object, resourcePath, strings.TrimSuffix(filepath.Base(resourceFile), ".json"), encryptionTransformer, resource.AdditionalAuthenticatedData())
 			if err != nil {
-				return err
+				return written, err
 			}
+			written++
 		}
 	}
-	return nil
+	return written, writeNames(backupPath, names)
+}
+
+// writeNames writes the index of the names whose filename is encoded to the names file of the backup
+func writeNames(backupPath string, names archiveformat.Names) error {
+	namesFile := filepath.Join(backupPath, filepath.FromSlash(archiveformat.NamesPath))
+	if err := createResourceDir(filepath.Dir(namesFile)); err != nil {
+		return err
+	}
+	data, err := json.Marshal(names)
+	if err != nil {
+		return fmt.Errorf("error converting names to JSON: %v", err)
+	}
+	return os.WriteFile(namesFile, data, os.ModePerm)
 }
 
 func createResourceDir(path string) error {
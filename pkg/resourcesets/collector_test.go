@@ -1,16 +1,25 @@
 package resourcesets
 
 import (
+	"context"
 	"embed"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
 )
 
 //go:embed testdata/*.yaml
@@ -330,7 +339,7 @@ func TestResourceHandler_filterByName_then_filterByNamespace(t *testing.T) {
 	assert.Equal(t, 6, len(result)) // Only expected items...
 
 	handler := &ResourceHandler{}
-	namespaceResult, _ := handler.filterByNamespace(mockFilter, result)
+	namespaceResult, _ := handler.filterByNamespace(context.Background(), mockFilter, result)
 	assert.NotNil(t, namespaceResult)
 	assert.Equal(t, 4, len(namespaceResult)) // Should be 5 after bug fix
 }
@@ -344,7 +353,7 @@ func TestFilterByNamespace_BadNamespaceRegexp(t *testing.T) {
 		NamespaceRegexp: ")",
 	}
 	handler := &ResourceHandler{}
-	_, err = handler.filterByNamespace(filter, resourceObjectsList)
+	_, err = handler.filterByNamespace(context.Background(), filter, resourceObjectsList)
 	assert.Error(t, err)
 }
 
@@ -356,3 +365,203 @@ func memberOf(haystack []string, needle string) bool {
 	}
 	return false
 }
+
+func newNamespace(name string, labels map[string]string) *unstructured.Unstructured {
+	namespace := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "Namespace"}}
+	namespace.SetName(name)
+	namespace.SetLabels(labels)
+	return namespace
+}
+
+func newConfigMap(namespace, name string) unstructured.Unstructured {
+	configMap := unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}}
+	configMap.SetNamespace(namespace)
+	configMap.SetName(name)
+	return configMap
+}
+
+func TestFilterByNamespace_NamespaceSelector(t *testing.T) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{namespacesResource: "NamespaceList"},
+		newNamespace("team-a", map[string]string{"backup": "true"}),
+		newNamespace("team-b", map[string]string{"backup": "false"}),
+		newNamespace("team-c", nil),
+	)
+	objects := []unstructured.Unstructured{
+		newConfigMap("team-a", "settings"),
+		newConfigMap("team-b", "settings"),
+		newConfigMap("team-c", "settings"),
+	}
+	testCases := []struct {
+		name     string
+		filter   v1.ResourceSelector
+		expected []string
+	}{
+		{
+			name:     "Labeled namespaces",
+			filter:   v1.ResourceSelector{NamespaceSelector: &k8sv1.LabelSelector{MatchLabels: map[string]string{"backup": "true"}}},
+			expected: []string{"team-a"},
+		},
+		{
+			name: "Combined with namespaces via OR",
+			filter: v1.ResourceSelector{
+				Namespaces:        []string{"team-c"},
+				NamespaceSelector: &k8sv1.LabelSelector{MatchLabels: map[string]string{"backup": "true"}},
+			},
+			expected: []string{"team-a", "team-c"},
+		},
+		{
+			name: "Expressions",
+			filter: v1.ResourceSelector{NamespaceSelector: &k8sv1.LabelSelector{MatchExpressions: []k8sv1.LabelSelectorRequirement{
+				{Key: "backup", Operator: k8sv1.LabelSelectorOpExists},
+			}}},
+			expected: []string{"team-a", "team-b"},
+		},
+		{
+			name:   "No matching namespace",
+			filter: v1.ResourceSelector{NamespaceSelector: &k8sv1.LabelSelector{MatchLabels: map[string]string{"backup": "maybe"}}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			handler := &ResourceHandler{DynamicClient: dynamicClient}
+			result, err := handler.filterByNamespace(context.Background(), testCase.filter, objects)
+			assert.NoError(t, err)
+			var namespaces []string
+			for _, obj := range result {
+				namespaces = append(namespaces, obj.GetNamespace())
+			}
+			assert.Equal(t, testCase.expected, namespaces)
+		})
+	}
+}
+
+func TestFilterByNamespace_BadNamespaceSelector(t *testing.T) {
+	filter := v1.ResourceSelector{NamespaceSelector: &k8sv1.LabelSelector{MatchExpressions: []k8sv1.LabelSelectorRequirement{
+		{Key: "backup", Operator: "Maybe"},
+	}}}
+	handler := &ResourceHandler{}
+	_, err := handler.filterByNamespace(context.Background(), filter, []unstructured.Unstructured{newConfigMap("team-a", "settings")})
+	assert.Error(t, err)
+}
+
+func TestGroupVersionsForSelector(t *testing.T) {
+	discoveryClient := &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*k8sv1.APIResourceList{
+		{GroupVersion: "v1"},
+		{GroupVersion: "management.cattle.io/v3"},
+		{GroupVersion: "example.io/v2"},
+		{GroupVersion: "example.io/v1"},
+	}}}
+	testCases := []struct {
+		name     string
+		filter   v1.ResourceSelector
+		expected []schema.GroupVersion
+		err      bool
+	}{
+		{
+			name:     "API version",
+			filter:   v1.ResourceSelector{APIVersion: "example.io/v1"},
+			expected: []schema.GroupVersion{{Group: "example.io", Version: "v1"}},
+		},
+		{
+			name:     "Preferred version of the matching group",
+			filter:   v1.ResourceSelector{APIGroupRegexp: `^example\.io$`},
+			expected: []schema.GroupVersion{{Group: "example.io", Version: "v2"}},
+		},
+		{
+			name:     "Core group",
+			filter:   v1.ResourceSelector{APIGroupRegexp: "^$"},
+			expected: []schema.GroupVersion{{Version: "v1"}},
+		},
+		{
+			name:   "Every group",
+			filter: v1.ResourceSelector{APIGroupRegexp: "."},
+			expected: []schema.GroupVersion{
+				{Version: "v1"},
+				{Group: "management.cattle.io", Version: "v3"},
+				{Group: "example.io", Version: "v2"},
+			},
+		},
+		{
+			name:   "No matching group",
+			filter: v1.ResourceSelector{APIGroupRegexp: `^apps$`},
+		},
+		{
+			name:   "API version and group regexp",
+			filter: v1.ResourceSelector{APIVersion: "v1", APIGroupRegexp: "."},
+			err:    true,
+		},
+		{
+			name:   "Neither API version nor group regexp",
+			filter: v1.ResourceSelector{},
+			err:    true,
+		},
+		{
+			name:   "Bad group regexp",
+			filter: v1.ResourceSelector{APIGroupRegexp: "("},
+			err:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			handler := &ResourceHandler{DiscoveryClient: discoveryClient}
+			groupVersions, err := handler.groupVersionsForSelector(testCase.filter)
+			if testCase.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, testCase.expected, groupVersions)
+		})
+	}
+}
+
+// pagedResource serves a list of pages, keyed by the continue token listing them
+type pagedResource struct {
+	dynamic.ResourceInterface
+	pages  map[string]*unstructured.UnstructuredList
+	listed []k8sv1.ListOptions
+}
+
+func (r *pagedResource) List(_ context.Context, opts k8sv1.ListOptions) (*unstructured.UnstructuredList, error) {
+	r.listed = append(r.listed, opts)
+	if page, ok := r.pages[opts.Continue]; ok {
+		return page, nil
+	}
+	return nil, fmt.Errorf("unknown continue token %q", opts.Continue)
+}
+
+func TestListPages(t *testing.T) {
+	first := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{newConfigMap("team-a", "a"), newConfigMap("team-a", "b")}}
+	first.SetContinue("page2")
+	dr := &pagedResource{pages: map[string]*unstructured.UnstructuredList{
+		"":      first,
+		"page2": {Items: []unstructured.Unstructured{newConfigMap("team-a", "c")}},
+	}}
+
+	var names []string
+	err := listPages(context.Background(), dr, k8sv1.ListOptions{LabelSelector: "app=web"}, func(page []unstructured.Unstructured) error {
+		for _, obj := range page {
+			names = append(names, obj.GetName())
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+	require.Len(t, dr.listed, 2)
+	for _, opts := range dr.listed {
+		assert.Equal(t, int64(ListObjectsLimit), opts.Limit)
+		assert.Equal(t, "app=web", opts.LabelSelector)
+	}
+	assert.Equal(t, "page2", dr.listed[1].Continue)
+
+	visited := 0
+	err = listPages(context.Background(), dr, k8sv1.ListOptions{}, func([]unstructured.Unstructured) error {
+		visited++
+		return fmt.Errorf("visit failed")
+	})
+	assert.EqualError(t, err, "visit failed")
+	assert.Equal(t, 1, visited, "no page is listed once visiting one fails")
+}
@@ -0,0 +1,127 @@
+package resourcesets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/match"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SelectorCoverage is what a ResourceSelector selects in the cluster
+type SelectorCoverage struct {
+	// ObjectCounts is the number of objects selected of each resource matched
+	ObjectCounts map[GVResource]int
+	// UnknownGroupVersions lists the group versions selected which the cluster doesn't serve
+	UnknownGroupVersions []string
+	// SkippedResources lists the resources matched whose objects can't be gathered, with the reason
+	SkippedResources map[GVResource]string
+	// Err is set when the selector is invalid, or its objects could not be gathered
+	Err error
+}
+
+// Coverage gathers the objects of each ResourceSelector on its own and returns what each selects in the cluster, once
+// the objects matching the exclude selectors are removed. Only the identities of the objects are kept while counting
+// them, each page of objects listed is dropped once filtered. An invalid selector is reported in its coverage and
+// doesn't stop the others. An error is returned when the exclude selectors are invalid or their objects could not be
+// gathered.
+func (h *ResourceHandler) Coverage(ctx context.Context, resourceSelectors, excludeSelectors []v1.ResourceSelector) ([]SelectorCoverage, error) {
+	h.selectedNamespaces = nil
+	h.identitiesOnly = true
+	defer func() {
+		h.coverage = nil
+		h.GVResourceToObjects = nil
+		h.identitiesOnly = false
+	}()
+
+	var errs []error
+	for i, excludeSelector := range excludeSelectors {
+		if err := ValidateResourceSelector(excludeSelector); err != nil {
+			errs = append(errs, fmt.Errorf("exclude selector %d: %w", i+1, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	excluded, err := h.gatherExcluded(ctx, excludeSelectors)
+	if err != nil {
+		return nil, err
+	}
+
+	coverages := make([]SelectorCoverage, len(resourceSelectors))
+	for i, resourceSelector := range resourceSelectors {
+		coverage := &coverages[i]
+		if coverage.Err = ValidateResourceSelector(resourceSelector); coverage.Err != nil {
+			continue
+		}
+		h.GVResourceToObjects = make(map[GVResource][]unstructured.Unstructured)
+		h.coverage = coverage
+		coverage.Err = h.gatherResourcesForSelector(ctx, resourceSelector)
+		h.removeExcluded(excluded)
+		coverage.ObjectCounts = make(map[GVResource]int, len(h.GVResourceToObjects))
+		for gvResource, objects := range h.GVResourceToObjects {
+			if _, skipped := coverage.SkippedResources[gvResource]; !skipped {
+				coverage.ObjectCounts[gvResource] = len(objects)
+			}
+		}
+	}
+	return coverages, nil
+}
+
+// recordSkippedResource records in the coverage being computed that the objects of a resource can't be gathered
+func (h *ResourceHandler) recordSkippedResource(gvResource GVResource, reason string) {
+	if h.coverage == nil {
+		return
+	}
+	if h.coverage.SkippedResources == nil {
+		h.coverage.SkippedResources = make(map[GVResource]string)
+	}
+	h.coverage.SkippedResources[gvResource] = reason
+}
+
+// ValidateResourceSelector returns an error when the API version or group of the selector is missing or invalid, or
+// one of its regexps or label selectors can't be parsed
+func ValidateResourceSelector(selector v1.ResourceSelector) error {
+	var errs []error
+	switch {
+	case selector.APIVersion != "" && selector.APIGroupRegexp != "":
+		errs = append(errs, fmt.Errorf("apiVersion and apiGroupRegexp can't be combined"))
+	case selector.APIVersion == "" && selector.APIGroupRegexp == "":
+		errs = append(errs, fmt.Errorf("one of apiVersion or apiGroupRegexp is required"))
+	case selector.APIVersion != "":
+		if _, err := schema.ParseGroupVersion(selector.APIVersion); err != nil {
+			errs = append(errs, fmt.Errorf("apiVersion: %w", err))
+		}
+	}
+	for _, pattern := range []struct {
+		field  string
+		regexp string
+	}{
+		{field: "apiGroupRegexp", regexp: selector.APIGroupRegexp},
+		{field: "kindsRegexp", regexp: selector.KindsRegexp},
+		{field: "resourceNameRegexp", regexp: selector.ResourceNameRegexp},
+		{field: "excludeResourceNameRegexp", regexp: selector.ExcludeResourceNameRegexp},
+		{field: "namespaceRegexp", regexp: selector.NamespaceRegexp},
+	} {
+		if _, err := regexp.Compile(pattern.regexp); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", pattern.field, err))
+		}
+	}
+	if _, err := k8sv1.LabelSelectorAsSelector(selector.LabelSelectors); err != nil {
+		errs = append(errs, fmt.Errorf("labelSelectors: %w", err))
+	}
+	if _, err := k8sv1.LabelSelectorAsSelector(selector.NamespaceSelector); err != nil {
+		errs = append(errs, fmt.Errorf("namespaceSelector: %w", err))
+	}
+	if selector.Match != "" {
+		if _, err := match.Compile(selector.Match); err != nil {
+			errs = append(errs, fmt.Errorf("match: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,204 @@
+package resourcesets
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestCoverage(t *testing.T) {
+	coreV1 := schema.GroupVersion{Version: "v1"}
+	configMaps := GVResource{GroupVersion: coreV1, Name: "configmaps", Namespaced: true}
+	tokenReviews := GVResource{GroupVersion: coreV1, Name: "tokenreviews"}
+	bindings := GVResource{GroupVersion: coreV1, Name: "bindings", Namespaced: true}
+	discoveryClient := &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*k8sv1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []k8sv1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: k8sv1.Verbs{"get", "list"}},
+			{Name: "tokenreviews", Kind: "TokenReview", Verbs: k8sv1.Verbs{"create"}},
+			{Name: "bindings", Kind: "Binding", Namespaced: true, Verbs: k8sv1.Verbs{"get"}},
+		}},
+	}}}
+	teamA, teamB := newConfigMap("team-a", "settings"), newConfigMap("team-b", "settings")
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{coreV1.WithResource("configmaps"): "ConfigMapList"},
+		&teamA, &teamB,
+	)
+	handler := &ResourceHandler{DiscoveryClient: discoveryClient, DynamicClient: dynamicClient}
+
+	coverages, err := handler.Coverage(context.Background(), []v1.ResourceSelector{
+		{APIVersion: "v1", KindsRegexp: "."},
+		{APIVersion: "v1", Kinds: []string{"configmaps"}, Namespaces: []string{"team-a"}},
+		{APIVersion: "example.io/v1", KindsRegexp: "."},
+		{APIVersion: "v1", KindsRegexp: "("},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, coverages, 4)
+
+	assert.NoError(t, coverages[0].Err)
+	assert.Equal(t, map[GVResource]int{configMaps: 2}, coverages[0].ObjectCounts)
+	assert.Contains(t, coverages[0].SkippedResources, tokenReviews)
+	assert.Contains(t, coverages[0].SkippedResources, bindings)
+
+	assert.NoError(t, coverages[1].Err)
+	assert.Equal(t, map[GVResource]int{configMaps: 1}, coverages[1].ObjectCounts)
+	assert.Empty(t, coverages[1].SkippedResources)
+
+	assert.NoError(t, coverages[2].Err)
+	assert.Empty(t, coverages[2].ObjectCounts)
+	assert.Equal(t, []string{"example.io/v1"}, coverages[2].UnknownGroupVersions)
+
+	assert.Error(t, coverages[3].Err)
+	assert.Empty(t, coverages[3].ObjectCounts)
+
+	assert.Nil(t, handler.GVResourceToObjects, "gathered objects are not kept")
+
+	coverages, err = handler.Coverage(context.Background(), []v1.ResourceSelector{
+		{APIVersion: "v1", KindsRegexp: "."},
+	}, []v1.ResourceSelector{
+		{APIVersion: "v1", Kinds: []string{"configmaps"}, Namespaces: []string{"team-b"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, coverages, 1)
+	assert.Equal(t, map[GVResource]int{configMaps: 1}, coverages[0].ObjectCounts, "excluded objects are not counted")
+
+	_, err = handler.Coverage(context.Background(), []v1.ResourceSelector{
+		{APIVersion: "v1", KindsRegexp: "."},
+	}, []v1.ResourceSelector{
+		{APIVersion: "v1", KindsRegexp: "("},
+	})
+	assert.Error(t, err)
+}
+
+func TestCoverageListsPages(t *testing.T) {
+	coreV1 := schema.GroupVersion{Version: "v1"}
+	configMaps := GVResource{GroupVersion: coreV1, Name: "configmaps", Namespaced: true}
+	discoveryClient := &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*k8sv1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []k8sv1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: k8sv1.Verbs{"get", "list"}},
+		}},
+	}}}
+	newSetting := func(name, enabled string) unstructured.Unstructured {
+		configMap := newConfigMap("team-a", name)
+		configMap.Object["data"] = map[string]interface{}{"enabled": enabled}
+		return configMap
+	}
+	// the configmaps are listed in two pages, the fake client doesn't pass the continue token so they are served in turn
+	first := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{newSetting("a", "true"), newSetting("b", "false")}}
+	first.SetContinue("page2")
+	pages := []*unstructured.UnstructuredList{first, {Items: []unstructured.Unstructured{newSetting("c", "true")}}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{coreV1.WithResource("configmaps"): "ConfigMapList"})
+	listed := 0
+	dynamicClient.PrependReactor("list", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+		page := pages[listed%len(pages)]
+		listed++
+		return true, page, nil
+	})
+	handler := &ResourceHandler{DiscoveryClient: discoveryClient, DynamicClient: dynamicClient}
+	selectors := []v1.ResourceSelector{
+		{APIVersion: "v1", Kinds: []string{"configmaps"}, Match: `object.data.enabled == "true"`},
+	}
+
+	coverages, err := handler.Coverage(context.Background(), selectors, nil)
+	require.NoError(t, err)
+	require.Len(t, coverages, 1)
+	assert.NoError(t, coverages[0].Err)
+	assert.Equal(t, map[GVResource]int{configMaps: 2}, coverages[0].ObjectCounts)
+	assert.Equal(t, 2, listed)
+
+	// backups keep the content of the objects gathered from every page
+	require.NoError(t, handler.GatherResources(context.Background(), selectors, nil))
+	require.Len(t, handler.GVResourceToObjects[configMaps], 2)
+	for _, configMap := range handler.GVResourceToObjects[configMaps] {
+		assert.Equal(t, map[string]interface{}{"enabled": "true"}, configMap.Object["data"])
+	}
+}
+
+func TestKeepIdentitiesOnly(t *testing.T) {
+	configMap := newConfigMap("team-a", "settings")
+	configMap.Object["data"] = map[string]interface{}{"enabled": "true"}
+	objects := []unstructured.Unstructured{configMap}
+
+	handler := &ResourceHandler{}
+	assert.Equal(t, objects, handler.keep(objects))
+
+	handler.identitiesOnly = true
+	kept := handler.keep(objects)
+	require.Len(t, kept, 1)
+	assert.Equal(t, newConfigMap("team-a", "settings"), kept[0])
+	assert.Contains(t, configMap.Object, "data", "the objects gathered are not modified")
+}
+
+func TestValidateResourceSelector(t *testing.T) {
+	testCases := []struct {
+		name     string
+		selector v1.ResourceSelector
+		err      bool
+	}{
+		{
+			name:     "Valid",
+			selector: v1.ResourceSelector{APIVersion: "management.cattle.io/v3", KindsRegexp: "^users$", NamespaceRegexp: "^cattle-"},
+		},
+		{
+			name:     "API group regexp",
+			selector: v1.ResourceSelector{APIGroupRegexp: `\.cattle\.io$`},
+		},
+		{
+			name:     "No API version",
+			selector: v1.ResourceSelector{KindsRegexp: "."},
+			err:      true,
+		},
+		{
+			name:     "Invalid API version",
+			selector: v1.ResourceSelector{APIVersion: "a/b/c"},
+			err:      true,
+		},
+		{
+			name:     "Invalid exclude regexp",
+			selector: v1.ResourceSelector{APIVersion: "v1", ExcludeResourceNameRegexp: "[a-"},
+			err:      true,
+		},
+		{
+			name:     "Invalid namespace regexp",
+			selector: v1.ResourceSelector{APIVersion: "v1", NamespaceRegexp: "("},
+			err:      true,
+		},
+		{
+			name:     "Match expression",
+			selector: v1.ResourceSelector{APIVersion: "v1", Kinds: []string{"Secret"}, Match: `object.type != "Opaque"`},
+		},
+		{
+			name:     "Invalid match expression",
+			selector: v1.ResourceSelector{APIVersion: "v1", Match: `object.type !=`},
+			err:      true,
+		},
+		{
+			name: "Invalid label selector",
+			selector: v1.ResourceSelector{APIVersion: "v1", LabelSelectors: &k8sv1.LabelSelector{MatchExpressions: []k8sv1.LabelSelectorRequirement{
+				{Key: "app", Operator: "Maybe"},
+			}}},
+			err: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := ValidateResourceSelector(testCase.selector)
+			if testCase.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
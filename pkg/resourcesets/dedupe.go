@@ -0,0 +1,96 @@
+package resourcesets
+
+import (
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SelectorOverlap counts the objects of a resource gathered by a selector which an earlier selector of the same
+// ResourceSet already gathered, they are backed up once
+type SelectorOverlap struct {
+	// FirstSelector is the index of the selector the objects were gathered by
+	FirstSelector int
+	// Selector is the index of the selector which selected them again
+	Selector    int
+	GVResource  GVResource
+	ObjectCount int
+}
+
+// gatheredKey identifies an object by its UID, or by its group, resource, namespace and name when it has none
+type gatheredKey struct {
+	uid    types.UID
+	object ObjectKey
+}
+
+func newGatheredKey(gvResource GVResource, obj unstructured.Unstructured) gatheredKey {
+	if uid := obj.GetUID(); uid != "" {
+		return gatheredKey{uid: uid}
+	}
+	return gatheredKey{object: NewObjectKey(gvResource, obj)}
+}
+
+// objectDeduplicator merges the objects gathered by each selector, dropping the objects an earlier selector gathered
+type objectDeduplicator struct {
+	objects    map[GVResource][]unstructured.Unstructured
+	gatheredBy map[gatheredKey]int
+	overlaps   map[SelectorOverlap]int
+}
+
+func newObjectDeduplicator() *objectDeduplicator {
+	return &objectDeduplicator{
+		objects:    make(map[GVResource][]unstructured.Unstructured),
+		gatheredBy: make(map[gatheredKey]int),
+		overlaps:   make(map[SelectorOverlap]int),
+	}
+}
+
+// add merges the objects gathered by the selector at index selector
+func (d *objectDeduplicator) add(selector int, gathered map[GVResource][]unstructured.Unstructured) {
+	for gvResource, objects := range gathered {
+		if _, ok := d.objects[gvResource]; !ok {
+			// resources without objects are kept, the coverage and skipped resources refer to them
+			d.objects[gvResource] = nil
+		}
+		for _, obj := range objects {
+			key := newGatheredKey(gvResource, obj)
+			if first, ok := d.gatheredBy[key]; ok {
+				if first != selector {
+					d.overlaps[SelectorOverlap{FirstSelector: first, Selector: selector, GVResource: gvResource}]++
+				}
+				continue
+			}
+			d.gatheredBy[key] = selector
+			d.objects[gvResource] = append(d.objects[gvResource], obj)
+		}
+	}
+}
+
+// selectorOverlaps returns the overlaps between selectors ordered by selector, then resource
+func (d *objectDeduplicator) selectorOverlaps() []SelectorOverlap {
+	var overlaps []SelectorOverlap
+	for overlap, count := range d.overlaps {
+		overlap.ObjectCount = count
+		overlaps = append(overlaps, overlap)
+	}
+	sort.Slice(overlaps, func(i, j int) bool {
+		a, b := overlaps[i], overlaps[j]
+		if a.Selector != b.Selector {
+			return a.Selector < b.Selector
+		}
+		if a.FirstSelector != b.FirstSelector {
+			return a.FirstSelector < b.FirstSelector
+		}
+		if a.GVResource.GroupVersion != b.GVResource.GroupVersion {
+			return a.GVResource.GroupVersion.String() < b.GVResource.GroupVersion.String()
+		}
+		return a.GVResource.Name < b.GVResource.Name
+	})
+	for _, overlap := range overlaps {
+		logrus.Infof("Selector %d selects %d %v of %v already selected by selector %d, they are backed up once",
+			overlap.Selector+1, overlap.ObjectCount, overlap.GVResource.Name, overlap.GVResource.GroupVersion, overlap.FirstSelector+1)
+	}
+	return overlaps
+}
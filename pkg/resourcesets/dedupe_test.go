@@ -0,0 +1,79 @@
+package resourcesets
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestGatherResourcesDeduplicates(t *testing.T) {
+	coreV1 := schema.GroupVersion{Version: "v1"}
+	exampleV1 := schema.GroupVersion{Group: "example.io", Version: "v1"}
+	exampleV2 := schema.GroupVersion{Group: "example.io", Version: "v2"}
+	widgetResource := k8sv1.APIResource{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: k8sv1.Verbs{"get", "list"}}
+	discoveryClient := &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*k8sv1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []k8sv1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: k8sv1.Verbs{"get", "list"}},
+		}},
+		{GroupVersion: "example.io/v2", APIResources: []k8sv1.APIResource{widgetResource}},
+		{GroupVersion: "example.io/v1", APIResources: []k8sv1.APIResource{widgetResource}},
+	}}}
+	teamA, teamB := newConfigMap("team-a", "settings"), newConfigMap("team-b", "settings")
+	teamA.SetUID("configmap-a")
+	teamB.SetUID("configmap-b")
+	// the same object served by both versions of its group
+	gearV1, gearV2 := newWidget("example.io/v1", "team-a", "gear"), newWidget("example.io/v2", "team-a", "gear")
+	gearV1.SetUID("gear")
+	gearV2.SetUID("gear")
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			coreV1.WithResource("configmaps"): "ConfigMapList",
+			exampleV1.WithResource("widgets"): "WidgetList",
+			exampleV2.WithResource("widgets"): "WidgetList",
+		},
+		&teamA, &teamB, gearV1, gearV2,
+	)
+	configMaps := GVResource{GroupVersion: coreV1, Name: "configmaps", Namespaced: true}
+
+	handler := &ResourceHandler{DiscoveryClient: discoveryClient, DynamicClient: dynamicClient}
+	require.NoError(t, handler.GatherResources(context.Background(), []v1.ResourceSelector{
+		{APIVersion: "v1", Kinds: []string{"configmaps"}},
+		{APIVersion: "v1", Kinds: []string{"configmaps"}, Namespaces: []string{"team-a"}},
+		{APIGroupRegexp: `^example\.io$`, KindsRegexp: "."},
+		{APIVersion: "example.io/v1", Kinds: []string{"widgets"}},
+		{APIVersion: "v1", Kinds: []string{"configmaps"}, Namespaces: []string{"team-b"}},
+	}, nil))
+
+	var objectCount int
+	for _, objects := range handler.GVResourceToObjects {
+		objectCount += len(objects)
+	}
+	assert.Equal(t, 3, objectCount, "each object is gathered once")
+	assert.Len(t, handler.GVResourceToObjects[configMaps], 2)
+	assert.Len(t, handler.GVResourceToObjects[GVResource{GroupVersion: exampleV2, Name: "widgets", Namespaced: true}], 1)
+
+	assert.Equal(t, []SelectorOverlap{
+		{FirstSelector: 0, Selector: 1, GVResource: configMaps, ObjectCount: 1},
+		{FirstSelector: 2, Selector: 3, GVResource: GVResource{GroupVersion: exampleV1, Name: "widgets", Namespaced: true}, ObjectCount: 1},
+		{FirstSelector: 0, Selector: 4, GVResource: configMaps, ObjectCount: 1},
+	}, handler.Overlaps)
+}
+
+func TestNewGatheredKey(t *testing.T) {
+	configMaps := GVResource{GroupVersion: schema.GroupVersion{Version: "v1"}, Name: "configmaps", Namespaced: true}
+	withUID, withoutUID := newConfigMap("team-a", "settings"), newConfigMap("team-a", "settings")
+	withUID.SetUID(types.UID("uid"))
+	assert.Equal(t, gatheredKey{uid: "uid"}, newGatheredKey(configMaps, withUID))
+	assert.Equal(t, gatheredKey{object: ObjectKey{GroupResource: schema.GroupResource{Resource: "configmaps"}, Namespace: "team-a", Name: "settings"}},
+		newGatheredKey(configMaps, withoutUID))
+}
@@ -0,0 +1,73 @@
+package resourcesets
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ObjectKey identifies an object regardless of the version of its group it was gathered in
+type ObjectKey struct {
+	GroupResource schema.GroupResource
+	Namespace     string
+	Name          string
+}
+
+// NewObjectKey returns the key of an object gathered for gvResource, its namespace is left out for cluster-scoped resources
+func NewObjectKey(gvResource GVResource, obj unstructured.Unstructured) ObjectKey {
+	key := ObjectKey{
+		GroupResource: schema.GroupResource{Group: gvResource.GroupVersion.Group, Resource: gvResource.Name},
+		Name:          obj.GetName(),
+	}
+	if gvResource.Namespaced {
+		key.Namespace = obj.GetNamespace()
+	}
+	return key
+}
+
+// gatherExcluded returns the objects selected by any of the exclude selectors, only their identities are kept while
+// gathering them. The objects gathered for the resource selectors and the coverage being computed are left untouched.
+func (h *ResourceHandler) gatherExcluded(ctx context.Context, excludeSelectors []v1.ResourceSelector) (map[ObjectKey]bool, error) {
+	gathered, coverage, identitiesOnly := h.GVResourceToObjects, h.coverage, h.identitiesOnly
+	defer func() {
+		h.GVResourceToObjects, h.coverage, h.identitiesOnly = gathered, coverage, identitiesOnly
+	}()
+	h.GVResourceToObjects = make(map[GVResource][]unstructured.Unstructured)
+	h.coverage = nil
+	h.identitiesOnly = true
+
+	for i, excludeSelector := range excludeSelectors {
+		if err := h.gatherResourcesForSelector(ctx, excludeSelector); err != nil {
+			return nil, fmt.Errorf("error gathering resources for exclude selector %d: %w", i+1, err)
+		}
+	}
+	excluded := make(map[ObjectKey]bool)
+	for gvResource, objects := range h.GVResourceToObjects {
+		for _, obj := range objects {
+			excluded[NewObjectKey(gvResource, obj)] = true
+		}
+	}
+	return excluded, nil
+}
+
+// removeExcluded removes the excluded objects from the gathered objects
+func (h *ResourceHandler) removeExcluded(excluded map[ObjectKey]bool) {
+	if len(excluded) == 0 {
+		return
+	}
+	for gvResource, objects := range h.GVResourceToObjects {
+		kept := objects[:0]
+		for _, obj := range objects {
+			if excluded[NewObjectKey(gvResource, obj)] {
+				logrus.Debugf("Excluding %v %v/%v matched by an exclude selector", gvResource.Name, obj.GetNamespace(), obj.GetName())
+				continue
+			}
+			kept = append(kept, obj)
+		}
+		h.GVResourceToObjects[gvResource] = kept
+	}
+}
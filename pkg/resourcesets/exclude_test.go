@@ -0,0 +1,125 @@
+package resourcesets
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func newWidget(apiVersion, namespace, name string) *unstructured.Unstructured {
+	widget := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": apiVersion, "kind": "Widget"}}
+	widget.SetNamespace(namespace)
+	widget.SetName(name)
+	return widget
+}
+
+func TestGatherResourcesWithExcludeSelectors(t *testing.T) {
+	coreV1 := schema.GroupVersion{Version: "v1"}
+	exampleV1 := schema.GroupVersion{Group: "example.io", Version: "v1"}
+	exampleV2 := schema.GroupVersion{Group: "example.io", Version: "v2"}
+	widgetResource := k8sv1.APIResource{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: k8sv1.Verbs{"get", "list"}}
+	discoveryClient := &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*k8sv1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []k8sv1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: k8sv1.Verbs{"get", "list"}},
+		}},
+		{GroupVersion: "example.io/v1", APIResources: []k8sv1.APIResource{widgetResource}},
+		{GroupVersion: "example.io/v2", APIResources: []k8sv1.APIResource{widgetResource}},
+	}}}
+	teamA, teamB, sandbox := newConfigMap("team-a", "settings"), newConfigMap("team-b", "settings"), newConfigMap("sandbox", "settings")
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			coreV1.WithResource("configmaps"): "ConfigMapList",
+			exampleV1.WithResource("widgets"): "WidgetList",
+			exampleV2.WithResource("widgets"): "WidgetList",
+		},
+		&teamA, &teamB, &sandbox,
+		newWidget("example.io/v1", "team-a", "gear"),
+		newWidget("example.io/v1", "sandbox", "gear"),
+		newWidget("example.io/v2", "sandbox", "gear"),
+	)
+	configMaps := GVResource{GroupVersion: coreV1, Name: "configmaps", Namespaced: true}
+	widgets := GVResource{GroupVersion: exampleV1, Name: "widgets", Namespaced: true}
+
+	testCases := []struct {
+		name             string
+		excludeSelectors []v1.ResourceSelector
+		expected         map[GVResource][]string
+	}{
+		{
+			name: "No exclude selectors",
+			expected: map[GVResource][]string{
+				configMaps: {"sandbox/settings", "team-a/settings", "team-b/settings"},
+				widgets:    {"sandbox/gear", "team-a/gear"},
+			},
+		},
+		{
+			name: "Exclude a namespace across API groups",
+			excludeSelectors: []v1.ResourceSelector{
+				{APIGroupRegexp: ".", KindsRegexp: ".", Namespaces: []string{"sandbox"}},
+			},
+			expected: map[GVResource][]string{
+				configMaps: {"team-a/settings", "team-b/settings"},
+				widgets:    {"team-a/gear"},
+			},
+		},
+		{
+			name: "Exclude in another version of the group",
+			excludeSelectors: []v1.ResourceSelector{
+				{APIVersion: "example.io/v2", Kinds: []string{"widgets"}},
+			},
+			expected: map[GVResource][]string{
+				configMaps: {"sandbox/settings", "team-a/settings", "team-b/settings"},
+				widgets:    {"team-a/gear"},
+			},
+		},
+		{
+			name: "Several exclude selectors",
+			excludeSelectors: []v1.ResourceSelector{
+				{APIVersion: "v1", Kinds: []string{"configmaps"}, Namespaces: []string{"team-b"}},
+				{APIVersion: "example.io/v1", Kinds: []string{"widgets"}, NamespaceRegexp: "^team-"},
+			},
+			expected: map[GVResource][]string{
+				configMaps: {"sandbox/settings", "team-a/settings"},
+				widgets:    {"sandbox/gear"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			handler := &ResourceHandler{DiscoveryClient: discoveryClient, DynamicClient: dynamicClient}
+			require.NoError(t, handler.GatherResources(context.Background(), []v1.ResourceSelector{
+				{APIVersion: "v1", Kinds: []string{"configmaps"}},
+				{APIVersion: "example.io/v1", Kinds: []string{"widgets"}},
+			}, testCase.excludeSelectors))
+
+			gathered := map[GVResource][]string{}
+			for gvResource, objects := range handler.GVResourceToObjects {
+				for _, obj := range objects {
+					gathered[gvResource] = append(gathered[gvResource], obj.GetNamespace()+"/"+obj.GetName())
+				}
+			}
+			for gvResource := range testCase.expected {
+				assert.ElementsMatch(t, testCase.expected[gvResource], gathered[gvResource], gvResource.Name)
+			}
+			assert.Len(t, gathered, len(testCase.expected))
+		})
+	}
+}
+
+func TestGatherResourcesWithInvalidExcludeSelector(t *testing.T) {
+	discoveryClient := &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	handler := &ResourceHandler{DiscoveryClient: discoveryClient}
+	err := handler.GatherResources(context.Background(), nil, []v1.ResourceSelector{{KindsRegexp: "."}})
+	assert.ErrorContains(t, err, "exclude selector 1")
+}
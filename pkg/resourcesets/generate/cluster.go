@@ -0,0 +1,72 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// crdLister lists CustomResourceDefinitions, it is implemented by the apiextensions client
+type crdLister interface {
+	List(ctx context.Context, opts k8sv1.ListOptions) (*apiextv1.CustomResourceDefinitionList, error)
+}
+
+// secretLister lists the secrets Helm stores releases in, it is implemented by the secret controller
+type secretLister interface {
+	List(namespace string, opts k8sv1.ListOptions) (*corev1.SecretList, error)
+}
+
+type clusterSource struct {
+	ctx     context.Context
+	crds    crdLister
+	secrets secretLister
+	// listed caches the CustomResourceDefinitions, so that they are listed once for the ResourceSets of a backup
+	listed []apiextv1.CustomResourceDefinition
+}
+
+// NewClusterSource returns a Source reading CustomResourceDefinitions and Helm releases from the cluster
+func NewClusterSource(ctx context.Context, crds crdLister, secrets secretLister) Source {
+	return &clusterSource{ctx: ctx, crds: crds, secrets: secrets}
+}
+
+func (s *clusterSource) CRDs() ([]apiextv1.CustomResourceDefinition, error) {
+	if s.listed != nil {
+		return s.listed, nil
+	}
+	crds, err := s.crds.List(s.ctx, k8sv1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	s.listed = crds.Items
+	return s.listed, nil
+}
+
+// HelmReleaseManifest reads the manifest of the latest deployed revision of a release from the secrets Helm labels
+// with its name and status
+func (s *clusterSource) HelmReleaseManifest(namespace, name string) (string, error) {
+	selector := labels.SelectorFromSet(labels.Set{"owner": "helm", "name": name, "status": "deployed"})
+	secrets, err := s.secrets.List(namespace, k8sv1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return "", err
+	}
+	var latest *corev1.Secret
+	latestRevision := -1
+	for i := range secrets.Items {
+		revision, err := strconv.Atoi(secrets.Items[i].Labels["version"])
+		if err != nil {
+			continue
+		}
+		if revision > latestRevision {
+			latest, latestRevision = &secrets.Items[i], revision
+		}
+	}
+	if latest == nil {
+		return "", fmt.Errorf("no deployed revision found")
+	}
+	return HelmReleaseManifest(latest.Data["release"])
+}
@@ -0,0 +1,201 @@
+package generate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// crdsAPIVersion is the apiVersion selectors back CustomResourceDefinitions up in
+const crdsAPIVersion = "apiextensions.k8s.io/v1"
+
+// FromCRDs returns selectors for the CustomResourceDefinitions whose group matches groupRegexp, and for their custom
+// resources in the version each CRD stores them in
+func FromCRDs(crds []apiextv1.CustomResourceDefinition, groupRegexp string) ([]v1.ResourceSelector, error) {
+	re, err := regexp.Compile(groupRegexp)
+	if err != nil {
+		return nil, fmt.Errorf("error in CRD group pattern %s: %w", groupRegexp, err)
+	}
+
+	var crdNames []string
+	resourcesByVersion := make(map[string][]string)
+	for _, crd := range crds {
+		if !re.MatchString(crd.Spec.Group) {
+			continue
+		}
+		crdNames = append(crdNames, crd.Name)
+		gv := schema.GroupVersion{Group: crd.Spec.Group, Version: storageVersion(crd)}
+		resourcesByVersion[gv.String()] = append(resourcesByVersion[gv.String()], crd.Spec.Names.Plural)
+	}
+	if len(crdNames) == 0 {
+		return nil, nil
+	}
+
+	sort.Strings(crdNames)
+	selectors := []v1.ResourceSelector{{
+		APIVersion:    crdsAPIVersion,
+		KindsRegexp:   "^customresourcedefinitions$",
+		ResourceNames: crdNames,
+	}}
+	for _, apiVersion := range sortedKeys(resourcesByVersion) {
+		selectors = append(selectors, v1.ResourceSelector{
+			APIVersion:  apiVersion,
+			KindsRegexp: exactRegexp(resourcesByVersion[apiVersion]),
+		})
+	}
+	return selectors, nil
+}
+
+// storageVersion returns the version a CRD stores its objects in, which is served by every cluster it is installed in
+func storageVersion(crd apiextv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	if len(crd.Spec.Versions) > 0 {
+		return crd.Spec.Versions[0].Name
+	}
+	return ""
+}
+
+// FromManifest returns selectors for the objects of a manifest of YAML or JSON documents, such as the manifest of a
+// Helm release. Objects without a namespace are selected in namespace, the namespace is ignored for cluster scoped
+// resources.
+func FromManifest(manifest []byte, namespace string) ([]v1.ResourceSelector, error) {
+	type selectorKey struct {
+		apiVersion string
+		kind       string
+		namespace  string
+	}
+	names := make(map[selectorKey][]string)
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+	for {
+		var obj unstructured.Unstructured
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error decoding manifest: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("manifest object %v/%v is missing its apiVersion, kind or name", obj.GetKind(), obj.GetName())
+		}
+		key := selectorKey{apiVersion: obj.GetAPIVersion(), kind: obj.GetKind(), namespace: obj.GetNamespace()}
+		if key.namespace == "" {
+			key.namespace = namespace
+		}
+		names[key] = append(names[key], obj.GetName())
+	}
+
+	keys := make([]selectorKey, 0, len(names))
+	for key := range names {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.apiVersion != b.apiVersion {
+			return a.apiVersion < b.apiVersion
+		}
+		if a.kind != b.kind {
+			return a.kind < b.kind
+		}
+		return a.namespace < b.namespace
+	})
+	selectors := make([]v1.ResourceSelector, 0, len(keys))
+	for _, key := range keys {
+		selector := v1.ResourceSelector{
+			APIVersion:    key.apiVersion,
+			KindsRegexp:   exactRegexp([]string{key.kind}),
+			ResourceNames: unique(names[key]),
+		}
+		if key.namespace != "" {
+			selector.Namespaces = []string{key.namespace}
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
+// FromLabelSelector returns a selector for the objects of every resource whose labels match
+func FromLabelSelector(labelSelector *k8sv1.LabelSelector) []v1.ResourceSelector {
+	return []v1.ResourceSelector{{
+		APIGroupRegexp: ".",
+		KindsRegexp:    ".",
+		LabelSelectors: labelSelector.DeepCopy(),
+	}}
+}
+
+// HelmReleaseManifest returns the manifest of a Helm release from the release field of the Secret Helm stores it in,
+// which holds the release encoded in base64 and usually gzipped
+func HelmReleaseManifest(release []byte) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(string(release))
+	if err != nil {
+		return "", fmt.Errorf("error decoding Helm release: %w", err)
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("error decompressing Helm release: %w", err)
+		}
+		defer reader.Close()
+		if data, err = io.ReadAll(reader); err != nil {
+			return "", fmt.Errorf("error decompressing Helm release: %w", err)
+		}
+	}
+	var decoded struct {
+		Manifest string `json:"manifest"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return "", fmt.Errorf("error unmarshaling Helm release: %w", err)
+	}
+	return decoded.Manifest, nil
+}
+
+// exactRegexp returns a regexp matching exactly one of the names, in the "^a$|^b$" form used by the chart's ResourceSets
+func exactRegexp(names []string) string {
+	names = unique(names)
+	patterns := make([]string, 0, len(names))
+	for _, name := range names {
+		patterns = append(patterns, "^"+regexp.QuoteMeta(name)+"$")
+	}
+	return strings.Join(patterns, "|")
+}
+
+func unique(values []string) []string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	var result []string
+	for i, value := range sorted {
+		if i == 0 || value != sorted[i-1] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,190 @@
+package generate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newCRD(group, plural string, versions ...apiextv1.CustomResourceDefinitionVersion) apiextv1.CustomResourceDefinition {
+	return apiextv1.CustomResourceDefinition{
+		ObjectMeta: k8sv1.ObjectMeta{Name: plural + "." + group},
+		Spec: apiextv1.CustomResourceDefinitionSpec{
+			Group:    group,
+			Names:    apiextv1.CustomResourceDefinitionNames{Plural: plural},
+			Versions: versions,
+		},
+	}
+}
+
+func TestFromCRDs(t *testing.T) {
+	crds := []apiextv1.CustomResourceDefinition{
+		newCRD("management.cattle.io", "users", apiextv1.CustomResourceDefinitionVersion{Name: "v3", Storage: true}),
+		newCRD("management.cattle.io", "clusters", apiextv1.CustomResourceDefinitionVersion{Name: "v3", Storage: true}),
+		newCRD("fleet.cattle.io", "bundles", apiextv1.CustomResourceDefinitionVersion{Name: "v1alpha1"}, apiextv1.CustomResourceDefinitionVersion{Name: "v1", Storage: true}),
+		newCRD("example.io", "widgets", apiextv1.CustomResourceDefinitionVersion{Name: "v1", Storage: true}),
+	}
+
+	selectors, err := FromCRDs(crds, `cattle\.io$`)
+	require.NoError(t, err)
+	assert.Equal(t, []v1.ResourceSelector{
+		{APIVersion: "apiextensions.k8s.io/v1", KindsRegexp: "^customresourcedefinitions$", ResourceNames: []string{"bundles.fleet.cattle.io", "clusters.management.cattle.io", "users.management.cattle.io"}},
+		{APIVersion: "fleet.cattle.io/v1", KindsRegexp: "^bundles$"},
+		{APIVersion: "management.cattle.io/v3", KindsRegexp: "^clusters$|^users$"},
+	}, selectors)
+
+	selectors, err = FromCRDs(crds, `^monitoring\.coreos\.com$`)
+	require.NoError(t, err)
+	assert.Empty(t, selectors)
+
+	_, err = FromCRDs(crds, "(")
+	assert.Error(t, err)
+}
+
+func TestFromManifest(t *testing.T) {
+	manifest := `
+---
+# Source: chart/templates/serviceaccount.yaml
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: operator
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: other
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: worker
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: operator
+`
+	selectors, err := FromManifest([]byte(manifest), "cattle-system")
+	require.NoError(t, err)
+	assert.Equal(t, []v1.ResourceSelector{
+		{APIVersion: "rbac.authorization.k8s.io/v1", KindsRegexp: "^ClusterRole$", ResourceNames: []string{"operator"}, Namespaces: []string{"cattle-system"}},
+		{APIVersion: "v1", KindsRegexp: "^ConfigMap$", ResourceNames: []string{"settings"}, Namespaces: []string{"other"}},
+		{APIVersion: "v1", KindsRegexp: "^ServiceAccount$", ResourceNames: []string{"operator", "worker"}, Namespaces: []string{"cattle-system"}},
+	}, selectors)
+
+	_, err = FromManifest([]byte("apiVersion: v1\nkind: ConfigMap\n"), "cattle-system")
+	assert.ErrorContains(t, err, "missing")
+}
+
+func TestHelmReleaseManifest(t *testing.T) {
+	release := []byte(`{"name":"rancher","manifest":"apiVersion: v1\nkind: ServiceAccount\n"}`)
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	_, err := writer.Write(release)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Gzipped release", data: gzipped.Bytes()},
+		{name: "Plain release", data: release},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			manifest, err := HelmReleaseManifest([]byte(base64.StdEncoding.EncodeToString(testCase.data)))
+			require.NoError(t, err)
+			assert.Equal(t, "apiVersion: v1\nkind: ServiceAccount\n", manifest)
+		})
+	}
+
+	_, err = HelmReleaseManifest([]byte("not base64"))
+	assert.Error(t, err)
+}
+
+type fakeSource struct {
+	crds      []apiextv1.CustomResourceDefinition
+	manifests map[string]string
+}
+
+func (f fakeSource) CRDs() ([]apiextv1.CustomResourceDefinition, error) {
+	return f.crds, nil
+}
+
+func (f fakeSource) HelmReleaseManifest(namespace, name string) (string, error) {
+	manifest, ok := f.manifests[namespace+"/"+name]
+	if !ok {
+		return "", fmt.Errorf("no deployed revision found")
+	}
+	return manifest, nil
+}
+
+func TestResolve(t *testing.T) {
+	source := fakeSource{
+		crds:      []apiextv1.CustomResourceDefinition{newCRD("example.io", "widgets", apiextv1.CustomResourceDefinitionVersion{Name: "v1", Storage: true})},
+		manifests: map[string]string{"example-system/example": "apiVersion: v1\nkind: Secret\nmetadata:\n  name: example-token\n"},
+	}
+	resourceSet := &v1.ResourceSet{
+		ObjectMeta:        k8sv1.ObjectMeta{Name: "example"},
+		ResourceSelectors: []v1.ResourceSelector{{APIVersion: "v1", KindsRegexp: "^namespaces$", ResourceNames: []string{"example-system"}}},
+		Generate: &v1.ResourceSetGenerator{
+			CRDGroupRegexp: `^example\.io$`,
+			HelmReleases:   []v1.HelmReleaseReference{{Name: "example", Namespace: "example-system"}},
+			LabelSelector:  &k8sv1.LabelSelector{MatchLabels: map[string]string{"app": "example"}},
+		},
+	}
+
+	resolved, err := Resolve(resourceSet, source)
+	require.NoError(t, err)
+	assert.Equal(t, []v1.ResourceSelector{
+		{APIVersion: "v1", KindsRegexp: "^namespaces$", ResourceNames: []string{"example-system"}},
+		{APIVersion: "apiextensions.k8s.io/v1", KindsRegexp: "^customresourcedefinitions$", ResourceNames: []string{"widgets.example.io"}},
+		{APIVersion: "example.io/v1", KindsRegexp: "^widgets$"},
+		{APIVersion: "v1", KindsRegexp: "^Secret$", ResourceNames: []string{"example-token"}, Namespaces: []string{"example-system"}},
+		{APIGroupRegexp: ".", KindsRegexp: ".", LabelSelectors: &k8sv1.LabelSelector{MatchLabels: map[string]string{"app": "example"}}},
+	}, resolved.ResourceSelectors)
+	assert.Len(t, resourceSet.ResourceSelectors, 1, "the ResourceSet is not modified")
+
+	resourceSet.Generate.HelmReleases = append(resourceSet.Generate.HelmReleases, v1.HelmReleaseReference{Name: "missing", Namespace: "example-system"})
+	_, err = Resolve(resourceSet, source)
+	assert.ErrorContains(t, err, "example-system/missing")
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		generator v1.ResourceSetGenerator
+		err       string
+	}{
+		{name: "Valid generator", generator: v1.ResourceSetGenerator{CRDGroupRegexp: `cattle\.io$`}},
+		{name: "Empty generator", generator: v1.ResourceSetGenerator{}, err: "required"},
+		{name: "Invalid group regexp", generator: v1.ResourceSetGenerator{CRDGroupRegexp: "("}, err: "crdGroupRegexp"},
+		{name: "Release without a namespace", generator: v1.ResourceSetGenerator{HelmReleases: []v1.HelmReleaseReference{{Name: "rancher"}}}, err: "helmReleases[0]"},
+		{
+			name:      "Invalid label selector",
+			generator: v1.ResourceSetGenerator{LabelSelector: &k8sv1.LabelSelector{MatchExpressions: []k8sv1.LabelSelectorRequirement{{Key: "app", Operator: "Near"}}}},
+			err:       "labelSelector",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := Validate(&testCase.generator)
+			if testCase.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, testCase.err)
+			}
+		})
+	}
+}
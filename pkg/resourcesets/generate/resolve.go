@@ -0,0 +1,88 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Source reads what resource selectors are generated from
+type Source interface {
+	// CRDs returns the CustomResourceDefinitions installed
+	CRDs() ([]apiextv1.CustomResourceDefinition, error)
+	// HelmReleaseManifest returns the manifest of the deployed revision of a Helm release
+	HelmReleaseManifest(namespace, name string) (string, error)
+}
+
+// Resolve returns a copy of resourceSet whose ResourceSelectors are followed by the selectors generated by its
+// Generate from source. The ResourceSet is returned as is when it doesn't generate selectors.
+func Resolve(resourceSet *v1.ResourceSet, source Source) (*v1.ResourceSet, error) {
+	if resourceSet.Generate == nil {
+		return resourceSet, nil
+	}
+	selectors, err := Selectors(resourceSet.Generate, source)
+	if err != nil {
+		return nil, fmt.Errorf("error generating the selectors of ResourceSet %v: %w", resourceSet.Name, err)
+	}
+	resolved := resourceSet.DeepCopy()
+	resolved.ResourceSelectors = append(resolved.ResourceSelectors, selectors...)
+	return resolved, nil
+}
+
+// Selectors returns the selectors generated by generator from source
+func Selectors(generator *v1.ResourceSetGenerator, source Source) ([]v1.ResourceSelector, error) {
+	if err := Validate(generator); err != nil {
+		return nil, err
+	}
+	var selectors []v1.ResourceSelector
+	if generator.CRDGroupRegexp != "" {
+		crds, err := source.CRDs()
+		if err != nil {
+			return nil, fmt.Errorf("error listing CustomResourceDefinitions: %w", err)
+		}
+		crdSelectors, err := FromCRDs(crds, generator.CRDGroupRegexp)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, crdSelectors...)
+	}
+	for _, release := range generator.HelmReleases {
+		manifest, err := source.HelmReleaseManifest(release.Namespace, release.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error getting Helm release %v/%v: %w", release.Namespace, release.Name, err)
+		}
+		releaseSelectors, err := FromManifest([]byte(manifest), release.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("Helm release %v/%v: %w", release.Namespace, release.Name, err)
+		}
+		selectors = append(selectors, releaseSelectors...)
+	}
+	if generator.LabelSelector != nil {
+		selectors = append(selectors, FromLabelSelector(generator.LabelSelector)...)
+	}
+	return selectors, nil
+}
+
+// Validate returns an error when the generator can't generate selectors
+func Validate(generator *v1.ResourceSetGenerator) error {
+	var errs []error
+	if generator.CRDGroupRegexp == "" && len(generator.HelmReleases) == 0 && generator.LabelSelector == nil {
+		errs = append(errs, fmt.Errorf("one of crdGroupRegexp, helmReleases or labelSelector is required"))
+	}
+	if _, err := regexp.Compile(generator.CRDGroupRegexp); err != nil {
+		errs = append(errs, fmt.Errorf("crdGroupRegexp: %w", err))
+	}
+	for i, release := range generator.HelmReleases {
+		if release.Name == "" || release.Namespace == "" {
+			errs = append(errs, fmt.Errorf("helmReleases[%d]: name and namespace are required", i))
+		}
+	}
+	if _, err := k8sv1.LabelSelectorAsSelector(generator.LabelSelector); err != nil {
+		errs = append(errs, fmt.Errorf("labelSelector: %w", err))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,70 @@
+package include
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+)
+
+// Getter returns the ResourceSet with the given name
+type Getter func(name string) (*v1.ResourceSet, error)
+
+// Resolve returns a copy of resourceSet whose ResourceSelectors, ExcludeSelectors and ControllerReferences are followed by those of
+// the ResourceSets it includes, and of the ones they include in turn. A ResourceSet included several times is merged
+// once, and a ResourceSet including itself, directly or not, is an error.
+func Resolve(resourceSet *v1.ResourceSet, get Getter) (*v1.ResourceSet, error) {
+	resolved := resourceSet.DeepCopy()
+	merged := map[string]bool{resourceSet.Name: true}
+	if err := mergeIncludes(resolved, resourceSet, get, merged, []string{resourceSet.Name}); err != nil {
+		return nil, err
+	}
+	resolved.ControllerReferences = uniqueControllerReferences(resolved.ControllerReferences)
+	return resolved, nil
+}
+
+// mergeIncludes appends the selectors of the ResourceSets included by resourceSet to resolved, path is the chain of
+// includes leading to resourceSet
+func mergeIncludes(resolved, resourceSet *v1.ResourceSet, get Getter, merged map[string]bool, path []string) error {
+	for _, name := range resourceSet.Include {
+		for _, including := range path {
+			if including == name {
+				return fmt.Errorf("ResourceSet %v includes itself: %v", name, strings.Join(append(path, name), " -> "))
+			}
+		}
+		if merged[name] {
+			continue
+		}
+		merged[name] = true
+		included, err := get(name)
+		if err != nil {
+			return fmt.Errorf("error getting ResourceSet %v included by %v: %w", name, resourceSet.Name, err)
+		}
+		included = included.DeepCopy()
+		resolved.ResourceSelectors = append(resolved.ResourceSelectors, included.ResourceSelectors...)
+		resolved.ExcludeSelectors = append(resolved.ExcludeSelectors, included.ExcludeSelectors...)
+		resolved.ControllerReferences = append(resolved.ControllerReferences, included.ControllerReferences...)
+		includedPath := append(append([]string{}, path...), name)
+		if err := mergeIncludes(resolved, included, get, merged, includedPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uniqueControllerReferences drops the controllers referenced more than once, a controller scaled down twice would be
+// scaled back up to 0 replicas
+func uniqueControllerReferences(references []v1.ControllerReference) []v1.ControllerReference {
+	seen := make(map[v1.ControllerReference]bool, len(references))
+	var unique []v1.ControllerReference
+	for _, reference := range references {
+		key := reference
+		key.Replicas = 0
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, reference)
+	}
+	return unique
+}
@@ -0,0 +1,125 @@
+package include
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newResourceSet(name string, apiVersions []string, include ...string) *v1.ResourceSet {
+	resourceSet := &v1.ResourceSet{ObjectMeta: k8sv1.ObjectMeta{Name: name}, Include: include}
+	for _, apiVersion := range apiVersions {
+		resourceSet.ResourceSelectors = append(resourceSet.ResourceSelectors, v1.ResourceSelector{APIVersion: apiVersion})
+	}
+	return resourceSet
+}
+
+func getterOf(resourceSets ...*v1.ResourceSet) Getter {
+	return func(name string) (*v1.ResourceSet, error) {
+		for _, resourceSet := range resourceSets {
+			if resourceSet.Name == name {
+				return resourceSet, nil
+			}
+		}
+		return nil, fmt.Errorf("resourcesets %v not found", name)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	testCases := []struct {
+		name        string
+		resourceSet *v1.ResourceSet
+		available   []*v1.ResourceSet
+		expected    []string
+		err         bool
+	}{
+		{
+			name:        "No includes",
+			resourceSet: newResourceSet("team", []string{"team.example.com/v1"}),
+			expected:    []string{"team.example.com/v1"},
+		},
+		{
+			name:        "Nested includes",
+			resourceSet: newResourceSet("team", []string{"team.example.com/v1"}, "rancher"),
+			available: []*v1.ResourceSet{
+				newResourceSet("rancher", []string{"management.cattle.io/v3"}, "core"),
+				newResourceSet("core", []string{"v1"}),
+			},
+			expected: []string{"team.example.com/v1", "management.cattle.io/v3", "v1"},
+		},
+		{
+			name:        "ResourceSet included twice is merged once",
+			resourceSet: newResourceSet("team", nil, "rancher", "fleet"),
+			available: []*v1.ResourceSet{
+				newResourceSet("rancher", []string{"management.cattle.io/v3"}, "core"),
+				newResourceSet("fleet", []string{"fleet.cattle.io/v1alpha1"}, "core"),
+				newResourceSet("core", []string{"v1"}),
+			},
+			expected: []string{"management.cattle.io/v3", "v1", "fleet.cattle.io/v1alpha1"},
+		},
+		{
+			name:        "Includes itself",
+			resourceSet: newResourceSet("team", nil, "team"),
+			err:         true,
+		},
+		{
+			name:        "Includes itself through another ResourceSet",
+			resourceSet: newResourceSet("team", nil, "rancher"),
+			available:   []*v1.ResourceSet{newResourceSet("rancher", nil, "team")},
+			err:         true,
+		},
+		{
+			name:        "Missing ResourceSet",
+			resourceSet: newResourceSet("team", nil, "rancher"),
+			err:         true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			available := append([]*v1.ResourceSet{testCase.resourceSet}, testCase.available...)
+			selectorCount := len(testCase.resourceSet.ResourceSelectors)
+			resolved, err := Resolve(testCase.resourceSet, getterOf(available...))
+			if testCase.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var apiVersions []string
+			for _, selector := range resolved.ResourceSelectors {
+				apiVersions = append(apiVersions, selector.APIVersion)
+			}
+			assert.Equal(t, testCase.expected, apiVersions)
+			assert.Equal(t, testCase.resourceSet.Include, resolved.Include)
+			assert.Len(t, testCase.resourceSet.ResourceSelectors, selectorCount, "the ResourceSet is not modified")
+		})
+	}
+}
+
+func TestResolveControllerReferences(t *testing.T) {
+	deployment := v1.ControllerReference{APIVersion: "apps/v1", Resource: "deployments", Namespace: "cattle-system", Name: "rancher"}
+	resourceSet := newResourceSet("team", nil, "rancher")
+	resourceSet.ControllerReferences = []v1.ControllerReference{deployment}
+	rancher := newResourceSet("rancher", nil)
+	rancher.ControllerReferences = []v1.ControllerReference{deployment, {APIVersion: "apps/v1", Resource: "deployments", Namespace: "cattle-fleet-system", Name: "fleet-controller"}}
+
+	resolved, err := Resolve(resourceSet, getterOf(rancher))
+	require.NoError(t, err)
+	assert.Equal(t, []v1.ControllerReference{deployment, rancher.ControllerReferences[1]}, resolved.ControllerReferences)
+}
+
+func TestResolveExcludeSelectors(t *testing.T) {
+	resourceSet := newResourceSet("team", nil, "rancher")
+	resourceSet.ExcludeSelectors = []v1.ResourceSelector{{APIVersion: "v1", Namespaces: []string{"sandbox"}}}
+	rancher := newResourceSet("rancher", []string{"v1"})
+	rancher.ExcludeSelectors = []v1.ResourceSelector{{APIVersion: "v1", Kinds: []string{"secrets"}}}
+
+	resolved, err := Resolve(resourceSet, getterOf(rancher))
+	require.NoError(t, err)
+	assert.Equal(t, append(resourceSet.ExcludeSelectors, rancher.ExcludeSelectors...), resolved.ExcludeSelectors)
+	assert.Len(t, resourceSet.ExcludeSelectors, 1, "the ResourceSet is not modified")
+}
@@ -0,0 +1,42 @@
+package resourcesets
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/match"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// filterByMatch returns the objects for which the match expression returns true
+func (h *ResourceHandler) filterByMatch(expression string, objects []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	if expression == "" {
+		return objects, nil
+	}
+	program, ok := h.matchPrograms[expression]
+	if !ok {
+		var err error
+		if program, err = match.Compile(expression); err != nil {
+			return nil, fmt.Errorf("error in match expression %s: %w", expression, err)
+		}
+		if h.matchPrograms == nil {
+			h.matchPrograms = map[string]cel.Program{}
+		}
+		h.matchPrograms[expression] = program
+	}
+
+	var selected []unstructured.Unstructured
+	for _, obj := range objects {
+		matched, err := match.Eval(program, obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("error evaluating match expression %s on %v %v/%v: %w", expression, obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+		}
+		if !matched {
+			logrus.Debugf("Skipping %v %v/%v since it doesn't match expression %s", obj.GetKind(), obj.GetNamespace(), obj.GetName(), expression)
+			continue
+		}
+		selected = append(selected, obj)
+	}
+	return selected, nil
+}
@@ -0,0 +1,40 @@
+package match
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// costLimit bounds the cost of evaluating a match expression on one object
+const costLimit = 1000000
+
+// Compile compiles the CEL match expression of a resource selector, which must return a bool for the variable
+// "object" holding an object selected by the other fields of the selector
+func Compile(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(cel.Variable("object", cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression returns %v instead of bool", ast.OutputType())
+	}
+	return env.Program(ast, cel.CostLimit(costLimit))
+}
+
+// Eval returns whether a compiled match expression selects the object
+func Eval(program cel.Program, object map[string]interface{}) (bool, error) {
+	out, _, err := program.Eval(map[string]interface{}{"object": object})
+	if err != nil {
+		return false, err
+	}
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("returned %v instead of a bool", out.Type())
+	}
+	return result, nil
+}
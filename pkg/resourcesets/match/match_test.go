@@ -0,0 +1,32 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	_, err := Compile(`object.type == "Opaque"`)
+	assert.NoError(t, err)
+	_, err = Compile(`1 + 1`)
+	assert.Error(t, err, "the expression must return a bool")
+	_, err = Compile(`secret.type == "Opaque"`)
+	assert.Error(t, err, "only the object variable is declared")
+}
+
+func TestEval(t *testing.T) {
+	object := map[string]interface{}{"type": "Opaque"}
+
+	program, err := Compile(`object.type == "Opaque"`)
+	require.NoError(t, err)
+	matched, err := Eval(program, object)
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	program, err = Compile(`object.type`)
+	require.NoError(t, err)
+	_, err = Eval(program, object)
+	assert.ErrorContains(t, err, "instead of a bool")
+}
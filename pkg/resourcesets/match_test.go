@@ -0,0 +1,83 @@
+package resourcesets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newSecret(name, secretType string, annotations map[string]interface{}) unstructured.Unstructured {
+	metadata := map[string]interface{}{"name": name, "namespace": "cattle-system"}
+	if annotations != nil {
+		metadata["annotations"] = annotations
+	}
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   metadata,
+		"type":       secretType,
+	}}
+}
+
+func TestFilterByMatch(t *testing.T) {
+	objects := []unstructured.Unstructured{
+		newSecret("opaque", "Opaque", nil),
+		newSecret("registration", "fleet.cattle.io/cluster-registration-values", nil),
+		newSecret("skipped", "Opaque", map[string]interface{}{"backup.cattle.io/skip": "true"}),
+	}
+	testCases := []struct {
+		name       string
+		expression string
+		expected   []string
+		err        bool
+	}{
+		{
+			name:     "No expression",
+			expected: []string{"opaque", "registration", "skipped"},
+		},
+		{
+			name:       "Exclude a secret type",
+			expression: `object.type != "fleet.cattle.io/cluster-registration-values"`,
+			expected:   []string{"opaque", "skipped"},
+		},
+		{
+			name:       "Exclude an annotation",
+			expression: `!has(object.metadata.annotations) || !("backup.cattle.io/skip" in object.metadata.annotations)`,
+			expected:   []string{"opaque", "registration"},
+		},
+		{
+			name:       "Missing field",
+			expression: `object.metadata.annotations["backup.cattle.io/skip"] != "true"`,
+			err:        true,
+		},
+		{
+			name:       "Not a bool",
+			expression: `object.metadata.name`,
+			err:        true,
+		},
+		{
+			name:       "Invalid expression",
+			expression: `object.type ==`,
+			err:        true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			h := &ResourceHandler{}
+			matched, err := h.filterByMatch(testCase.expression, objects)
+			if testCase.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, obj := range matched {
+				names = append(names, obj.GetName())
+			}
+			assert.Equal(t, testCase.expected, names)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/s3utils"
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// Archive is a backup archive found in a storage location
+type Archive struct {
+	ArchiveName
+	// Filename is the base name of the archive
+	Filename string
+	// Key is the path of the archive in a mount path, or its object key in an S3 bucket
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// ListMountPath returns the backup archives in the mount path, newest first
+func ListMountPath(mountPath string) ([]Archive, error) {
+	entries, err := os.ReadDir(mountPath)
+	if err != nil {
+		return nil, err
+	}
+	var archives []Archive
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name, ok := ParseArchiveName(entry.Name())
+		if !ok {
+			continue
+		}
+		fileInfo, err := entry.Info()
+		if err != nil {
+			logrus.Errorf("Error getting file information for %v: %v", entry.Name(), err)
+			continue
+		}
+		archives = append(archives, Archive{
+			ArchiveName:  name,
+			Filename:     entry.Name(),
+			Key:          filepath.Join(mountPath, entry.Name()),
+			Size:         fileInfo.Size(),
+			LastModified: fileInfo.ModTime(),
+		})
+	}
+	sortNewestFirst(archives)
+	return archives, nil
+}
+
+// ListS3 returns the backup archives in the bucket and folder of the object store, newest first
+func ListS3(ctx context.Context, svc *minio.Client, objectStore *v1.S3ObjectStore) ([]Archive, error) {
+	folder := strings.Trim(objectStore.Folder, "/")
+	opts := minio.ListObjectsOptions{}
+	if folder != "" {
+		opts.Prefix = folder + "/"
+	}
+	if s3utils.IsGoogleEndpoint(*svc.EndpointURL()) {
+		logrus.Info("Endpoint is Google GCS")
+		opts.UseV1 = true
+	}
+
+	var archives []Archive
+	for object := range svc.ListObjects(ctx, objectStore.BucketName, opts) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list objects in bucket [%s]: %w", objectStore.BucketName, object.Err)
+		}
+		name, ok := ParseArchiveName(path.Base(object.Key))
+		if !ok {
+			continue
+		}
+		archives = append(archives, Archive{
+			ArchiveName:  name,
+			Filename:     path.Base(object.Key),
+			Key:          object.Key,
+			Size:         object.Size,
+			LastModified: object.LastModified,
+		})
+	}
+	sortNewestFirst(archives)
+	return archives, nil
+}
+
+func sortNewestFirst(archives []Archive) {
+	sort.SliceStable(archives, func(i, j int) bool {
+		return archives[i].Timestamp.After(archives[j].Timestamp)
+	})
+}
+
+// ObjectKey returns the key of an archive in the bucket and folder of the object store
+func ObjectKey(objectStore *v1.S3ObjectStore, filename string) string {
+	folder := strings.Trim(objectStore.Folder, "/")
+	if folder == "" {
+		return filename
+	}
+	return folder + "/" + filename
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// ArchiveExtension is the extension of backup archives
+	ArchiveExtension = ".tar.gz"
+	// ZstdArchiveExtension is the extension of backup archives compressed with zstd
+	ZstdArchiveExtension = ".tar.zst"
+	// EncryptedExtension is appended to the extension of backup archives whose resources are encrypted
+	EncryptedExtension = ".enc"
+	// EncryptedArchiveExtension is the extension of backup archives whose resources are encrypted
+	EncryptedArchiveExtension = ArchiveExtension + EncryptedExtension
+	// SealedExtension is appended to the extension of backup archives that are encrypted as a whole
+	SealedExtension = ".age"
+)
+
+// archiveNameRegexp matches the filenames generated by the backup controller: <backup name>-<kube-system UID>-<timestamp>,
+// where the timestamp is in RFC3339 format with colons replaced by dashes, example:
+// default-backup-24e1b8ce-1f00-4bbe-94bb-248ad7606dc8-2023-05-08T13-40-33-04-00.tar.gz, archives compressed with zstd
+// end with .tar.zst
+var archiveNameRegexp = regexp.MustCompile(`^(.+)-([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})-` +
+	`(\d{4}-\d{2}-\d{2})T(\d{2})-(\d{2})-(\d{2})(Z|[+-]\d{2}-\d{2})(\.tar\.(?:gz|zst)(\.enc)?)(\.age)?$`)
+
+// ArchiveName holds the parts of a backup archive filename
+type ArchiveName struct {
+	BackupName string
+	ClusterUID string
+	Timestamp  time.Time
+	Encrypted  bool
+	// Sealed archives are encrypted as a whole, independently of the encryption of their resources
+	Sealed bool
+}
+
+// ParseArchiveName parses the base name of a backup archive generated by the backup controller, it returns false for any
+// other file
+func ParseArchiveName(filename string) (ArchiveName, bool) {
+	matches := archiveNameRegexp.FindStringSubmatch(filename)
+	if matches == nil {
+		return ArchiveName{}, false
+	}
+	zone := matches[7]
+	if zone != "Z" {
+		// +hh-mm, only the separator of hours and minutes was replaced
+		zone = zone[:3] + ":" + zone[4:]
+	}
+	timestamp, err := time.Parse(time.RFC3339, fmt.Sprintf("%sT%s:%s:%s%s", matches[3], matches[4], matches[5], matches[6], zone))
+	if err != nil {
+		return ArchiveName{}, false
+	}
+	return ArchiveName{
+		BackupName: matches[1],
+		ClusterUID: matches[2],
+		Timestamp:  timestamp,
+		Encrypted:  matches[9] == EncryptedExtension,
+		Sealed:     matches[10] == SealedExtension,
+	}, true
+}
+
+// TrimArchiveExtension returns the filename of an archive without its extension
+func TrimArchiveExtension(filename string) string {
+	name := strings.TrimSuffix(filename, SealedExtension)
+	name = strings.TrimSuffix(name, EncryptedExtension)
+	return strings.TrimSuffix(strings.TrimSuffix(name, ArchiveExtension), ZstdArchiveExtension)
+}
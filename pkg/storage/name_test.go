@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testClusterUID = "24e1b8ce-1f00-4bbe-94bb-248ad7606dc8"
+
+func TestParseArchiveName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		filename string
+		expected ArchiveName
+		ok       bool
+	}{
+		{
+			name:     "Negative UTC offset",
+			filename: "default-backup-" + testClusterUID + "-2023-05-08T13-40-33-04-00.tar.gz",
+			expected: ArchiveName{
+				BackupName: "default-backup",
+				ClusterUID: testClusterUID,
+				Timestamp:  time.Date(2023, 5, 8, 17, 40, 33, 0, time.UTC),
+			},
+			ok: true,
+		},
+		{
+			name:     "UTC and encrypted",
+			filename: "nightly-" + testClusterUID + "-2024-01-02T03-04-05Z.tar.gz.enc",
+			expected: ArchiveName{
+				BackupName: "nightly",
+				ClusterUID: testClusterUID,
+				Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Encrypted:  true,
+			},
+			ok: true,
+		},
+		{
+			name:     "Positive UTC offset",
+			filename: "b-" + testClusterUID + "-2024-01-02T03-04-05+05-30.tar.gz",
+			expected: ArchiveName{
+				BackupName: "b",
+				ClusterUID: testClusterUID,
+				Timestamp:  time.Date(2024, 1, 1, 21, 34, 5, 0, time.UTC),
+			},
+			ok: true,
+		},
+		{
+			name:     "Sealed and encrypted",
+			filename: "nightly-" + testClusterUID + "-2024-01-02T03-04-05Z.tar.gz.enc.age",
+			expected: ArchiveName{
+				BackupName: "nightly",
+				ClusterUID: testClusterUID,
+				Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Encrypted:  true,
+				Sealed:     true,
+			},
+			ok: true,
+		},
+		{
+			name:     "Zstd and encrypted",
+			filename: "nightly-" + testClusterUID + "-2024-01-02T03-04-05Z.tar.zst.enc",
+			expected: ArchiveName{
+				BackupName: "nightly",
+				ClusterUID: testClusterUID,
+				Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Encrypted:  true,
+			},
+			ok: true,
+		},
+		{
+			name:     "Sealed zstd",
+			filename: "nightly-" + testClusterUID + "-2024-01-02T03-04-05Z.tar.zst.age",
+			expected: ArchiveName{
+				BackupName: "nightly",
+				ClusterUID: testClusterUID,
+				Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Sealed:     true,
+			},
+			ok: true,
+		},
+		{
+			name:     "Unknown compression",
+			filename: "nightly-" + testClusterUID + "-2024-01-02T03-04-05Z.tar.bz2",
+		},
+		{
+			name:     "Not an archive",
+			filename: "filters.json",
+		},
+		{
+			name:     "Missing cluster UID",
+			filename: "default-backup-2023-05-08T13-40-33-04-00.tar.gz",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			name, ok := ParseArchiveName(testCase.filename)
+			require.Equal(t, testCase.ok, ok)
+			assert.Equal(t, testCase.expected.BackupName, name.BackupName)
+			assert.Equal(t, testCase.expected.ClusterUID, name.ClusterUID)
+			assert.True(t, testCase.expected.Timestamp.Equal(name.Timestamp), "expected %v, got %v", testCase.expected.Timestamp, name.Timestamp)
+			assert.Equal(t, testCase.expected.Encrypted, name.Encrypted)
+			assert.Equal(t, testCase.expected.Sealed, name.Sealed)
+		})
+	}
+}
+
+func TestTrimArchiveExtension(t *testing.T) {
+	for _, extension := range []string{".tar.gz", ".tar.gz.enc", ".tar.gz.enc.age", ".tar.zst", ".tar.zst.enc", ".tar.zst.age"} {
+		assert.Equal(t, "b-"+testClusterUID+"-2024-01-02T03-04-05Z", TrimArchiveExtension("b-"+testClusterUID+"-2024-01-02T03-04-05Z"+extension), extension)
+	}
+}
+
+func TestListMountPath(t *testing.T) {
+	mountPath := t.TempDir()
+	older := "b-" + testClusterUID + "-2024-01-01T00-00-00Z.tar.gz"
+	newer := "b-" + testClusterUID + "-2024-01-02T00-00-00Z.tar.gz.enc"
+	for _, filename := range []string{newer, older, "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(mountPath, filename), []byte("archive"), 0600))
+	}
+
+	archives, err := ListMountPath(mountPath)
+	require.NoError(t, err)
+	require.Len(t, archives, 2)
+	assert.Equal(t, newer, archives[0].Filename)
+	assert.Equal(t, older, archives[1].Filename)
+	assert.Equal(t, filepath.Join(mountPath, older), archives[1].Key)
+	assert.Equal(t, int64(len("archive")), archives[1].Size)
+}
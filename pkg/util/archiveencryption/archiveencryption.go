@@ -0,0 +1,131 @@
+package archiveencryption
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"filippo.io/age"
+	"github.com/rancher/backup-restore-operator/pkg/util"
+	v1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	v1core "k8s.io/api/core/v1"
+	v2 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/storage/value/encrypt/envelope/kmsv2"
+	kmsservice "k8s.io/kms/pkg/service"
+)
+
+const (
+	// RecipientsKey holds the age recipients, one per line, that archives are encrypted to
+	RecipientsKey = "recipients"
+	// IdentitiesKey holds the age identities, one per line, that archives are decrypted with. Identities of rotated
+	// recipients are kept to restore older archives.
+	IdentitiesKey = "identities"
+	// KMSEndpointKey holds the endpoint of a KMS v2 plugin, example unix:///var/run/kmsplugin/socket.sock, that also
+	// wraps the archives' file keys
+	KMSEndpointKey = "kms-endpoint"
+	// KMSNameKey holds the name of the KMS plugin, stored in archives to find the plugin that can unwrap their file key
+	KMSNameKey = "kms-name"
+
+	defaultKMSName    = "kms"
+	kmsCallTimeout    = 30 * time.Second
+	encryptedFileMark = "age-encryption.org/v1\n"
+)
+
+// Keys are the recipients and identities of a Secret, that archives are encrypted to and decrypted with
+type Keys struct {
+	Recipients []age.Recipient
+	Identities []age.Identity
+}
+
+func GetArchiveEncryptionSecret(secrets v1.SecretController, archiveEncryptionSecretName string) (*v1core.Secret, error) {
+	// like the EncryptionConfig secret, the archive encryption secret is in the chart's ns
+	// kubectl create secret generic archive-encryption --from-file=recipients --from-file=identities
+	logrus.Infof("Get archive encryption keys from namespace %v", util.GetChartNamespace())
+	return secrets.Get(util.GetChartNamespace(), archiveEncryptionSecretName, v2.GetOptions{})
+}
+
+// GetKeysFromSecret parses the age recipients and identities of the secret, and connects to its KMS plugin if any
+func GetKeysFromSecret(ctx context.Context, secret *v1core.Secret) (*Keys, error) {
+	keys := &Keys{}
+	if recipients := secret.Data[RecipientsKey]; len(recipients) > 0 {
+		parsed, err := age.ParseRecipients(bytes.NewReader(recipients))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %v of archive encryption secret %v: %w", RecipientsKey, secret.Name, err)
+		}
+		keys.Recipients = append(keys.Recipients, parsed...)
+	}
+	if identities := secret.Data[IdentitiesKey]; len(identities) > 0 {
+		parsed, err := age.ParseIdentities(bytes.NewReader(identities))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %v of archive encryption secret %v: %w", IdentitiesKey, secret.Name, err)
+		}
+		keys.Identities = append(keys.Identities, parsed...)
+	}
+	if endpoint := strings.TrimSpace(string(secret.Data[KMSEndpointKey])); endpoint != "" {
+		name := strings.TrimSpace(string(secret.Data[KMSNameKey]))
+		if name == "" {
+			name = defaultKMSName
+		}
+		service, err := getKMSService(ctx, endpoint, name)
+		if err != nil {
+			return nil, err
+		}
+		kms := NewKMSKey(ctx, name, service)
+		keys.Recipients = append(keys.Recipients, kms)
+		keys.Identities = append(keys.Identities, kms)
+	}
+	if len(keys.Recipients) == 0 && len(keys.Identities) == 0 {
+		return nil, fmt.Errorf("archive encryption secret %v has no %v, %v or %v", secret.Name, RecipientsKey, IdentitiesKey, KMSEndpointKey)
+	}
+	return keys, nil
+}
+
+var (
+	kmsServicesMu sync.Mutex
+	// kmsServices holds a client per KMS plugin, since clients are only closed when the operator stops
+	kmsServices = make(map[string]kmsservice.Service)
+)
+
+func getKMSService(ctx context.Context, endpoint, name string) (kmsservice.Service, error) {
+	kmsServicesMu.Lock()
+	defer kmsServicesMu.Unlock()
+	key := name + "@" + endpoint
+	if service, ok := kmsServices[key]; ok {
+		return service, nil
+	}
+	service, err := kmsv2.NewGRPCService(ctx, endpoint, name, kmsCallTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to KMS plugin %v at %v: %w", name, endpoint, err)
+	}
+	kmsServices[key] = service
+	return service, nil
+}
+
+// Encrypt returns a writer encrypting to dst with a new file key, wrapped for each recipient. The writer must be
+// closed to flush the last chunk of the archive.
+func (k *Keys) Encrypt(dst io.Writer) (io.WriteCloser, error) {
+	if len(k.Recipients) == 0 {
+		return nil, fmt.Errorf("no recipients to encrypt the archive to")
+	}
+	return age.Encrypt(dst, k.Recipients...)
+}
+
+// Decrypt returns a reader of the archive decrypted with the first identity that can unwrap its file key
+func (k *Keys) Decrypt(src io.Reader) (io.Reader, error) {
+	if len(k.Identities) == 0 {
+		return nil, fmt.Errorf("no identities to decrypt the archive with")
+	}
+	return age.Decrypt(src, k.Identities...)
+}
+
+// IsEncrypted returns true when the archive read by r starts with the header of an encrypted archive, without consuming it
+func IsEncrypted(r *bufio.Reader) bool {
+	header, err := r.Peek(len(encryptedFileMark))
+	return err == nil && string(header) == encryptedFileMark
+}
@@ -0,0 +1,126 @@
+package archiveencryption
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1core "k8s.io/api/core/v1"
+	v2 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newIdentity(t *testing.T) *age.X25519Identity {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	return identity
+}
+
+func newSecret(recipients, identities string) *v1core.Secret {
+	return &v1core.Secret{
+		ObjectMeta: v2.ObjectMeta{Name: "archive-encryption"},
+		Data: map[string][]byte{
+			RecipientsKey: []byte(recipients),
+			IdentitiesKey: []byte(identities),
+		},
+	}
+}
+
+func TestGetKeysFromSecret(t *testing.T) {
+	first, second := newIdentity(t), newIdentity(t)
+
+	testCases := []struct {
+		name           string
+		secret         *v1core.Secret
+		wantRecipients int
+		wantIdentities int
+		wantErr        bool
+	}{
+		{
+			name:           "Multiple recipients with comments",
+			secret:         newSecret("# current key\n"+first.Recipient().String()+"\n"+second.Recipient().String()+"\n", ""),
+			wantRecipients: 2,
+		},
+		{
+			name:           "Identities of rotated keys",
+			secret:         newSecret(second.Recipient().String(), first.String()+"\n"+second.String()),
+			wantRecipients: 1,
+			wantIdentities: 2,
+		},
+		{
+			name:    "Invalid recipient",
+			secret:  newSecret("not-a-recipient", ""),
+			wantErr: true,
+		},
+		{
+			name:    "Invalid identity",
+			secret:  newSecret("", "AGE-SECRET-KEY-INVALID"),
+			wantErr: true,
+		},
+		{
+			name:    "No keys",
+			secret:  newSecret("", ""),
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			keys, err := GetKeysFromSecret(context.Background(), testCase.secret)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, keys.Recipients, testCase.wantRecipients)
+			assert.Len(t, keys.Identities, testCase.wantIdentities)
+		})
+	}
+}
+
+func TestRecipientRotation(t *testing.T) {
+	oldIdentity, newIdentity := newIdentity(t), newIdentity(t)
+	before, err := GetKeysFromSecret(context.Background(), newSecret(oldIdentity.Recipient().String(), ""))
+	require.NoError(t, err)
+	sealedBeforeRotation := seal(t, before, "archive taken before rotation")
+
+	// after rotation, archives are encrypted to the new recipient, the old identity is kept to restore older archives
+	rotated, err := GetKeysFromSecret(context.Background(), newSecret(newIdentity.Recipient().String(), oldIdentity.String()+"\n"+newIdentity.String()))
+	require.NoError(t, err)
+	sealed := seal(t, rotated, "archive taken after rotation")
+
+	assert.Equal(t, "archive taken before rotation", unseal(t, rotated, sealedBeforeRotation))
+	assert.Equal(t, "archive taken after rotation", unseal(t, rotated, sealed))
+	_, err = (&Keys{Identities: []age.Identity{oldIdentity}}).Decrypt(bytes.NewReader(sealed))
+	assert.Error(t, err, "the old identity cannot decrypt archives taken after rotation")
+}
+
+func TestMultipleRecipients(t *testing.T) {
+	first, second := newIdentity(t), newIdentity(t)
+	sealed := seal(t, &Keys{Recipients: []age.Recipient{first.Recipient(), second.Recipient()}}, "archive")
+
+	for _, identity := range []age.Identity{first, second} {
+		assert.Equal(t, "archive", unseal(t, &Keys{Identities: []age.Identity{identity}}, sealed))
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	identity := newIdentity(t)
+	sealed := seal(t, &Keys{Recipients: []age.Recipient{identity.Recipient()}}, "archive")
+
+	r := bufio.NewReader(bytes.NewReader(sealed))
+	assert.True(t, IsEncrypted(r))
+	assert.Equal(t, "archive", unseal(t, &Keys{Identities: []age.Identity{identity}}, mustReadAll(t, r)), "the header is not consumed")
+	assert.False(t, IsEncrypted(bufio.NewReader(strings.NewReader("\x1f\x8b\x08 gzip archive"))))
+}
+
+func mustReadAll(t *testing.T, r *bufio.Reader) []byte {
+	var b bytes.Buffer
+	_, err := b.ReadFrom(r)
+	require.NoError(t, err)
+	return b.Bytes()
+}
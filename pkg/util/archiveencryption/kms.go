@@ -0,0 +1,90 @@
+package archiveencryption
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"filippo.io/age"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	kmsservice "k8s.io/kms/pkg/service"
+)
+
+// kmsStanzaType is the type of the archive header stanzas holding a file key wrapped by a KMS plugin
+const kmsStanzaType = "bro-kms"
+
+// KMSKey wraps and unwraps archive file keys with a KMS v2 plugin. The plugin's key ID is stored with the wrapped file
+// key, so archives remain decryptable after the plugin rotated its key, as long as it can still decrypt with the old one.
+type KMSKey struct {
+	ctx     context.Context
+	name    string
+	service kmsservice.Service
+}
+
+var (
+	_ age.Recipient = (*KMSKey)(nil)
+	_ age.Identity  = (*KMSKey)(nil)
+)
+
+// NewKMSKey returns a recipient and identity using the KMS service, name distinguishes the file keys of several plugins
+func NewKMSKey(ctx context.Context, name string, service kmsservice.Service) *KMSKey {
+	return &KMSKey{ctx: ctx, name: name, service: service}
+}
+
+// Wrap encrypts the file key with the KMS plugin. The stanza arguments are the plugin name, the key ID and the
+// annotations returned by the plugin, all base64 encoded.
+func (k *KMSKey) Wrap(fileKey []byte) ([]*age.Stanza, error) {
+	resp, err := k.service.Encrypt(k.ctx, string(uuid.NewUUID()), fileKey)
+	if err != nil {
+		return nil, fmt.Errorf("error encrypting file key with KMS plugin %v: %w", k.name, err)
+	}
+	if resp.KeyID == "" {
+		return nil, fmt.Errorf("KMS plugin %v returned an empty key ID", k.name)
+	}
+	args := []string{encodeArg(k.name), encodeArg(resp.KeyID)}
+	for key, value := range resp.Annotations {
+		// stanza arguments cannot be empty
+		if key == "" || len(value) == 0 {
+			continue
+		}
+		args = append(args, encodeArg(key), encodeArg(string(value)))
+	}
+	return []*age.Stanza{{Type: kmsStanzaType, Args: args, Body: resp.Ciphertext}}, nil
+}
+
+// Unwrap decrypts the file key wrapped by a plugin of the same name
+func (k *KMSKey) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	for _, stanza := range stanzas {
+		if stanza.Type != kmsStanzaType || len(stanza.Args) < 2 || len(stanza.Args)%2 != 0 {
+			continue
+		}
+		args := make([]string, len(stanza.Args))
+		for i, arg := range stanza.Args {
+			decoded, err := base64.RawStdEncoding.DecodeString(arg)
+			if err != nil {
+				return nil, fmt.Errorf("malformed %v stanza: %w", kmsStanzaType, err)
+			}
+			args[i] = string(decoded)
+		}
+		if args[0] != k.name {
+			continue
+		}
+		req := &kmsservice.DecryptRequest{Ciphertext: stanza.Body, KeyID: args[1]}
+		if len(args) > 2 {
+			req.Annotations = make(map[string][]byte)
+			for i := 2; i < len(args); i += 2 {
+				req.Annotations[args[i]] = []byte(args[i+1])
+			}
+		}
+		fileKey, err := k.service.Decrypt(k.ctx, string(uuid.NewUUID()), req)
+		if err != nil {
+			return nil, fmt.Errorf("error decrypting file key with KMS plugin %v: %w", k.name, err)
+		}
+		return fileKey, nil
+	}
+	return nil, age.ErrIncorrectIdentity
+}
+
+func encodeArg(arg string) string {
+	return base64.RawStdEncoding.EncodeToString([]byte(arg))
+}
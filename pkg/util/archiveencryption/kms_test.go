@@ -0,0 +1,146 @@
+package archiveencryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1core "k8s.io/api/core/v1"
+	v2 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kmsservice "k8s.io/kms/pkg/service"
+)
+
+// localKMS is a KMS stub encrypting with in-memory AES keys, rotate switches encryption to a new key while the old
+// ones remain usable for decryption
+type localKMS struct {
+	mu           sync.Mutex
+	keys         map[string]cipher.AEAD
+	currentKeyID string
+}
+
+func newLocalKMS(t *testing.T) *localKMS {
+	kms := &localKMS{keys: make(map[string]cipher.AEAD)}
+	kms.rotate(t)
+	return kms
+}
+
+func (k *localKMS) rotate(t *testing.T) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	k.currentKeyID = fmt.Sprintf("key-%d", len(k.keys)+1)
+	k.keys[k.currentKeyID] = aead
+}
+
+func (k *localKMS) Encrypt(_ context.Context, _ string, data []byte) (*kmsservice.EncryptResponse, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	aead := k.keys[k.currentKeyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &kmsservice.EncryptResponse{
+		Ciphertext:  aead.Seal(nonce, nonce, data, nil),
+		KeyID:       k.currentKeyID,
+		Annotations: map[string][]byte{"local.kms.io/version": []byte("1")},
+	}, nil
+}
+
+func (k *localKMS) Decrypt(_ context.Context, _ string, req *kmsservice.DecryptRequest) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	aead, ok := k.keys[req.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %v", req.KeyID)
+	}
+	if string(req.Annotations["local.kms.io/version"]) != "1" {
+		return nil, fmt.Errorf("missing annotation")
+	}
+	nonce, ciphertext := req.Ciphertext[:aead.NonceSize()], req.Ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func (k *localKMS) Status(_ context.Context) (*kmsservice.StatusResponse, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return &kmsservice.StatusResponse{Version: "v2", Healthz: "ok", KeyID: k.currentKeyID}, nil
+}
+
+func TestKMSKeyRotation(t *testing.T) {
+	kms := newLocalKMS(t)
+	keys := &Keys{}
+	kmsKey := NewKMSKey(context.Background(), "local", kms)
+	keys.Recipients = append(keys.Recipients, kmsKey)
+	keys.Identities = append(keys.Identities, kmsKey)
+
+	sealed := seal(t, keys, "archive taken before rotation")
+	kms.rotate(t)
+	sealedAfterRotation := seal(t, keys, "archive taken after rotation")
+
+	assert.Equal(t, "archive taken before rotation", unseal(t, keys, sealed))
+	assert.Equal(t, "archive taken after rotation", unseal(t, keys, sealedAfterRotation))
+
+	otherPlugin := NewKMSKey(context.Background(), "other", kms)
+	_, err := (&Keys{Identities: []age.Identity{otherPlugin}}).Decrypt(bytes.NewReader(sealed))
+	assert.Error(t, err, "file keys are only unwrapped by the plugin that wrapped them")
+}
+
+func TestGetKeysFromSecretWithKMSPlugin(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "kms.sock")
+	server := kmsservice.NewGRPCService(socket, 10*time.Second, newLocalKMS(t))
+	go func() {
+		_ = server.ListenAndServe()
+	}()
+	t.Cleanup(server.Shutdown)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	keys, err := GetKeysFromSecret(ctx, &v1core.Secret{
+		ObjectMeta: v2.ObjectMeta{Name: "archive-encryption"},
+		Data: map[string][]byte{
+			KMSEndpointKey: []byte("unix://" + socket),
+			KMSNameKey:     []byte("local"),
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, keys.Recipients, 1)
+	require.Len(t, keys.Identities, 1)
+
+	assert.Equal(t, "archive", unseal(t, keys, seal(t, keys, "archive")))
+}
+
+func seal(t *testing.T, keys *Keys, content string) []byte {
+	var sealed bytes.Buffer
+	w, err := keys.Encrypt(&sealed)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return sealed.Bytes()
+}
+
+func unseal(t *testing.T, keys *Keys, sealed []byte) string {
+	r, err := keys.Decrypt(bytes.NewReader(sealed))
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(content)
+}
@@ -0,0 +1,112 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/storage"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Algorithm returns the compression algorithm of a backup, gzip when it is unset
+func Algorithm(compression *v1.Compression) v1.CompressionAlgorithm {
+	if compression == nil || compression.Algorithm == "" {
+		return v1.GzipCompression
+	}
+	return compression.Algorithm
+}
+
+// Extension returns the extension of archives compressed with the algorithm
+func Extension(algorithm v1.CompressionAlgorithm) string {
+	if algorithm == v1.ZstdCompression {
+		return storage.ZstdArchiveExtension
+	}
+	return storage.ArchiveExtension
+}
+
+// Validate checks the algorithm and the level of the compression, a zero level is the default level of the algorithm
+func Validate(compression *v1.Compression) error {
+	if compression == nil {
+		return nil
+	}
+	maxLevel := 0
+	switch Algorithm(compression) {
+	case v1.GzipCompression:
+		maxLevel = gzip.BestCompression
+	case v1.ZstdCompression:
+		maxLevel = 22
+	default:
+		return fmt.Errorf("unsupported compression algorithm %v, must be one of %v, %v", compression.Algorithm, v1.GzipCompression, v1.ZstdCompression)
+	}
+	if compression.Level < 0 || compression.Level > maxLevel {
+		return fmt.Errorf("invalid %v compression level %v, must be 0 for the default, or 1..%v", Algorithm(compression), compression.Level, maxLevel)
+	}
+	return nil
+}
+
+// NewWriter returns a writer compressing to w, closing it flushes the compressed data but doesn't close w
+func NewWriter(w io.Writer, compression *v1.Compression) (io.WriteCloser, error) {
+	if err := Validate(compression); err != nil {
+		return nil, err
+	}
+	level := 0
+	if compression != nil {
+		level = compression.Level
+	}
+	if Algorithm(compression) == v1.ZstdCompression {
+		if level == 0 {
+			return zstd.NewWriter(w)
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+// NewReader returns a reader decompressing r, the algorithm is detected from the magic bytes of the archive rather than
+// its filename
+func NewReader(r io.Reader) (io.ReadCloser, v1.CompressionAlgorithm, error) {
+	br := bufio.NewReader(r)
+	algorithm, err := Detect(br)
+	if err != nil {
+		return nil, "", err
+	}
+	if algorithm == v1.ZstdCompression {
+		decoder, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, "", err
+		}
+		return decoder.IOReadCloser(), algorithm, nil
+	}
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, "", err
+	}
+	return gz, algorithm, nil
+}
+
+// Detect returns the compression algorithm of the archive read by br from its magic bytes, without consuming them
+func Detect(br *bufio.Reader) (v1.CompressionAlgorithm, error) {
+	header, err := br.Peek(len(zstdMagic))
+	if bytes.HasPrefix(header, gzipMagic) {
+		return v1.GzipCompression, nil
+	}
+	if bytes.HasPrefix(header, zstdMagic) {
+		return v1.ZstdCompression, nil
+	}
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("error reading archive header: %v", err)
+	}
+	return "", fmt.Errorf("unknown archive format, archives must be compressed with %v or %v", v1.GzipCompression, v1.ZstdCompression)
+}
@@ -0,0 +1,136 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compress(t *testing.T, compression *v1.Compression, data []byte) []byte {
+	var compressed bytes.Buffer
+	w, err := NewWriter(&compressed, compression)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return compressed.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat(`{"apiVersion":"v1","kind":"Secret"}`, 100))
+	testCases := []struct {
+		name        string
+		compression *v1.Compression
+		expected    v1.CompressionAlgorithm
+	}{
+		{
+			name:     "Default",
+			expected: v1.GzipCompression,
+		},
+		{
+			name:        "Gzip level",
+			compression: &v1.Compression{Algorithm: v1.GzipCompression, Level: 9},
+			expected:    v1.GzipCompression,
+		},
+		{
+			name:        "Level without algorithm",
+			compression: &v1.Compression{Level: 1},
+			expected:    v1.GzipCompression,
+		},
+		{
+			name:        "Zstd",
+			compression: &v1.Compression{Algorithm: v1.ZstdCompression},
+			expected:    v1.ZstdCompression,
+		},
+		{
+			name:        "Zstd level",
+			compression: &v1.Compression{Algorithm: v1.ZstdCompression, Level: 19},
+			expected:    v1.ZstdCompression,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r, algorithm, err := NewReader(bytes.NewReader(compress(t, testCase.compression, data)))
+			require.NoError(t, err)
+			defer r.Close()
+			assert.Equal(t, testCase.expected, algorithm)
+			decompressed, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, data, decompressed)
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	br := bufio.NewReader(bytes.NewReader(compress(t, &v1.Compression{Algorithm: v1.ZstdCompression}, []byte("data"))))
+	algorithm, err := Detect(br)
+	require.NoError(t, err)
+	assert.Equal(t, v1.ZstdCompression, algorithm)
+	// the magic bytes are not consumed
+	algorithm, err = Detect(br)
+	require.NoError(t, err)
+	assert.Equal(t, v1.ZstdCompression, algorithm)
+
+	for _, data := range []string{"", "\x1f", "age-encryption.org/v1\n"} {
+		_, err := Detect(bufio.NewReader(strings.NewReader(data)))
+		assert.Error(t, err, "%q", data)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		compression *v1.Compression
+		valid       bool
+	}{
+		{
+			name:  "Unset",
+			valid: true,
+		},
+		{
+			name:        "Gzip best compression",
+			compression: &v1.Compression{Algorithm: v1.GzipCompression, Level: 9},
+			valid:       true,
+		},
+		{
+			name:        "Gzip level too high",
+			compression: &v1.Compression{Algorithm: v1.GzipCompression, Level: 10},
+		},
+		{
+			name:        "Zstd max level",
+			compression: &v1.Compression{Algorithm: v1.ZstdCompression, Level: 22},
+			valid:       true,
+		},
+		{
+			name:        "Negative level",
+			compression: &v1.Compression{Algorithm: v1.ZstdCompression, Level: -1},
+		},
+		{
+			name:        "Unknown algorithm",
+			compression: &v1.Compression{Algorithm: "bzip2"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := Validate(testCase.compression)
+			if testCase.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestExtension(t *testing.T) {
+	assert.Equal(t, ".tar.gz", Extension(Algorithm(nil)))
+	assert.Equal(t, ".tar.zst", Extension(Algorithm(&v1.Compression{Algorithm: v1.ZstdCompression})))
+}
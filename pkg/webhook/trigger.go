@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+// TriggerRecorder records on Backups the user who sets their trigger-snapshot annotation, in the
+// trigger-snapshot-user annotation
+type TriggerRecorder struct{}
+
+// jsonPatchOperation is an operation of a JSON patch, RFC 6902
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// triggerUserPatch returns the JSON patch setting the trigger-snapshot-user annotation of a Backup to the user of the
+// request when it sets or changes its trigger-snapshot annotation. Otherwise the trigger-snapshot-user annotation is
+// restored to its previous value if the request changes it, so that it can't be set by users. nil is returned when the
+// Backup doesn't need to be patched.
+func triggerUserPatch(request *admissionv1.AdmissionRequest) ([]byte, error) {
+	if request.Kind.Kind != "Backup" || (request.Operation != admissionv1.Create && request.Operation != admissionv1.Update) {
+		return nil, nil
+	}
+	var obj, oldObj v1.Backup
+	if err := decode(request, &obj, &oldObj); err != nil {
+		return nil, err
+	}
+	token := obj.Annotations[v1.TriggerSnapshotAnnotation]
+	user, hasUser := obj.Annotations[v1.TriggerSnapshotUserAnnotation]
+	oldUser, hadUser := oldObj.Annotations[v1.TriggerSnapshotUserAnnotation]
+
+	var patch []jsonPatchOperation
+	switch {
+	case token != "" && token != oldObj.Annotations[v1.TriggerSnapshotAnnotation]:
+		if user == request.UserInfo.Username && hasUser {
+			return nil, nil
+		}
+		patch = []jsonPatchOperation{{Op: "add", Path: annotationPath(v1.TriggerSnapshotUserAnnotation), Value: request.UserInfo.Username}}
+	case user == oldUser && hasUser == hadUser:
+		return nil, nil
+	case hadUser:
+		patch = []jsonPatchOperation{{Op: "add", Path: annotationPath(v1.TriggerSnapshotUserAnnotation), Value: oldUser}}
+	default:
+		patch = []jsonPatchOperation{{Op: "remove", Path: annotationPath(v1.TriggerSnapshotUserAnnotation)}}
+	}
+	// the previous annotation is restored in a new map when the request removes every annotation of the Backup
+	if obj.Annotations == nil {
+		patch = []jsonPatchOperation{{Op: "add", Path: "/metadata/annotations", Value: map[string]string{v1.TriggerSnapshotUserAnnotation: oldUser}}}
+	}
+	return json.Marshal(patch)
+}
+
+// annotationPath returns the JSON pointer of an annotation, RFC 6901
+func annotationPath(annotation string) string {
+	return "/metadata/annotations/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(annotation)
+}
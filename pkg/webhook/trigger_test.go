@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+func newAnnotatedBackup(annotations map[string]string) *v1.Backup {
+	backup := newBackup(v1.BackupSpec{ResourceSetName: "rancher-resource-set"})
+	backup.Annotations = annotations
+	return backup
+}
+
+func TestTriggerUserPatch(t *testing.T) {
+	const userAnnotation = "/metadata/annotations/resources.cattle.io~1trigger-snapshot-user"
+	testCases := []struct {
+		name      string
+		operation admissionv1.Operation
+		obj       *v1.Backup
+		oldObj    *v1.Backup
+		expected  []jsonPatchOperation
+	}{
+		{
+			name:      "Created with a trigger",
+			operation: admissionv1.Create,
+			obj:       newAnnotatedBackup(map[string]string{v1.TriggerSnapshotAnnotation: "1"}),
+			expected:  []jsonPatchOperation{{Op: "add", Path: userAnnotation, Value: "alice"}},
+		},
+		{
+			name:      "Created without a trigger",
+			operation: admissionv1.Create,
+			obj:       newAnnotatedBackup(nil),
+		},
+		{
+			name:      "Trigger changed",
+			operation: admissionv1.Update,
+			obj:       newAnnotatedBackup(map[string]string{v1.TriggerSnapshotAnnotation: "2", v1.TriggerSnapshotUserAnnotation: "bob"}),
+			oldObj:    newAnnotatedBackup(map[string]string{v1.TriggerSnapshotAnnotation: "1", v1.TriggerSnapshotUserAnnotation: "bob"}),
+			expected:  []jsonPatchOperation{{Op: "add", Path: userAnnotation, Value: "alice"}},
+		},
+		{
+			name:      "Trigger changed with the user already recorded",
+			operation: admissionv1.Update,
+			obj:       newAnnotatedBackup(map[string]string{v1.TriggerSnapshotAnnotation: "2", v1.TriggerSnapshotUserAnnotation: "alice"}),
+			oldObj:    newAnnotatedBackup(map[string]string{v1.TriggerSnapshotAnnotation: "1", v1.TriggerSnapshotUserAnnotation: "bob"}),
+		},
+		{
+			name:      "Trigger unchanged",
+			operation: admissionv1.Update,
+			obj:       newAnnotatedBackup(map[string]string{v1.TriggerSnapshotAnnotation: "1", v1.TriggerSnapshotUserAnnotation: "bob"}),
+			oldObj:    newAnnotatedBackup(map[string]string{v1.TriggerSnapshotAnnotation: "1", v1.TriggerSnapshotUserAnnotation: "bob"}),
+		},
+		{
+			name:      "User changed without the trigger",
+			operation: admissionv1.Update,
+			obj:       newAnnotatedBackup(map[string]string{v1.TriggerSnapshotAnnotation: "1", v1.TriggerSnapshotUserAnnotation: "mallory"}),
+			oldObj:    newAnnotatedBackup(map[string]string{v1.TriggerSnapshotAnnotation: "1", v1.TriggerSnapshotUserAnnotation: "bob"}),
+			expected:  []jsonPatchOperation{{Op: "add", Path: userAnnotation, Value: "bob"}},
+		},
+		{
+			name:      "User set without a trigger",
+			operation: admissionv1.Create,
+			obj:       newAnnotatedBackup(map[string]string{v1.TriggerSnapshotUserAnnotation: "mallory"}),
+			expected:  []jsonPatchOperation{{Op: "remove", Path: userAnnotation}},
+		},
+		{
+			name:      "Annotations removed",
+			operation: admissionv1.Update,
+			obj:       newAnnotatedBackup(nil),
+			oldObj:    newAnnotatedBackup(map[string]string{v1.TriggerSnapshotAnnotation: "1", v1.TriggerSnapshotUserAnnotation: "bob"}),
+			expected: []jsonPatchOperation{{Op: "add", Path: "/metadata/annotations",
+				Value: map[string]interface{}{v1.TriggerSnapshotUserAnnotation: "bob"}}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := newRequest(t, testCase.operation, testCase.obj, nil)
+			if testCase.oldObj != nil {
+				request = newRequest(t, testCase.operation, testCase.obj, testCase.oldObj)
+			}
+			request.UserInfo = authenticationv1.UserInfo{Username: "alice"}
+			patch, err := triggerUserPatch(request)
+			require.NoError(t, err)
+			if testCase.expected == nil {
+				assert.Nil(t, patch)
+				return
+			}
+			var actual []jsonPatchOperation
+			require.NoError(t, json.Unmarshal(patch, &actual))
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestTriggerUserPatchIgnoresOtherKinds(t *testing.T) {
+	request := newRequest(t, admissionv1.Create, newRestore(v1.RestoreSpec{BackupFilename: "backup.tar.gz"}), nil)
+	patch, err := triggerUserPatch(request)
+	require.NoError(t, err)
+	assert.Nil(t, patch)
+}
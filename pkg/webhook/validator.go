@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/controllers/backup"
+	"github.com/rancher/backup-restore-operator/pkg/controllers/restore"
+	backupControllers "github.com/rancher/backup-restore-operator/pkg/generated/controllers/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/generate"
+	"github.com/rancher/backup-restore-operator/pkg/resourcesets/include"
+	"github.com/rancher/backup-restore-operator/pkg/util"
+	v1core "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// secretGetter gets the secrets referenced by Backups and Restores, it is implemented by the secret controller
+type secretGetter interface {
+	Get(namespace, name string, options k8sv1.GetOptions) (*corev1.Secret, error)
+}
+
+// resourceSetGetter gets the ResourceSets included by a ResourceSet, it is implemented by the ResourceSet controller
+type resourceSetGetter interface {
+	Get(name string, options k8sv1.GetOptions) (*v1.ResourceSet, error)
+}
+
+// Validator rejects Backups, Restores and ResourceSets whose spec the controllers would fail to reconcile
+type Validator struct {
+	secrets      secretGetter
+	resourceSets resourceSetGetter
+	// hasDefaultStorageLocation is true when the operator stores backups without a storage location in a PVC or S3
+	hasDefaultStorageLocation bool
+}
+
+func NewValidator(secrets v1core.SecretController, resourceSets backupControllers.ResourceSetController, hasDefaultStorageLocation bool) *Validator {
+	return &Validator{
+		secrets:                   secrets,
+		resourceSets:              resourceSets,
+		hasDefaultStorageLocation: hasDefaultStorageLocation,
+	}
+}
+
+// Validate returns an error when the object created or updated by the request is invalid. Updates which don't change
+// the spec are allowed, so that the finalizers of an object whose secret was deleted since it was created can be removed.
+func (v *Validator) Validate(request *admissionv1.AdmissionRequest) error {
+	if request.Operation != admissionv1.Create && request.Operation != admissionv1.Update {
+		return nil
+	}
+	switch request.Kind.Kind {
+	case "Backup":
+		var obj, oldObj v1.Backup
+		if err := decode(request, &obj, &oldObj); err != nil {
+			return err
+		}
+		if obj.DeletionTimestamp != nil || (request.Operation == admissionv1.Update && equality.Semantic.DeepEqual(obj.Spec, oldObj.Spec)) {
+			return nil
+		}
+		return v.validateBackup(&obj)
+	case "Restore":
+		var obj, oldObj v1.Restore
+		if err := decode(request, &obj, &oldObj); err != nil {
+			return err
+		}
+		if obj.DeletionTimestamp != nil || (request.Operation == admissionv1.Update && equality.Semantic.DeepEqual(obj.Spec, oldObj.Spec)) {
+			return nil
+		}
+		return v.validateRestore(&obj)
+	case "ResourceSet":
+		var obj, oldObj v1.ResourceSet
+		if err := decode(request, &obj, &oldObj); err != nil {
+			return err
+		}
+		if obj.DeletionTimestamp != nil || (request.Operation == admissionv1.Update &&
+			equality.Semantic.DeepEqual(obj.ResourceSelectors, oldObj.ResourceSelectors) &&
+			equality.Semantic.DeepEqual(obj.ExcludeSelectors, oldObj.ExcludeSelectors) && equality.Semantic.DeepEqual(obj.Include, oldObj.Include) &&
+			equality.Semantic.DeepEqual(obj.Generate, oldObj.Generate)) {
+			return nil
+		}
+		return v.validateResourceSet(&obj)
+	default:
+		return nil
+	}
+}
+
+// decode unmarshals the object of the request, and the previous object of updates
+func decode(request *admissionv1.AdmissionRequest, obj, oldObj interface{}) error {
+	if err := json.Unmarshal(request.Object.Raw, obj); err != nil {
+		return fmt.Errorf("error decoding %v: %v", request.Kind.Kind, err)
+	}
+	if request.Operation == admissionv1.Update {
+		if err := json.Unmarshal(request.OldObject.Raw, oldObj); err != nil {
+			return fmt.Errorf("error decoding previous %v: %v", request.Kind.Kind, err)
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateBackup(obj *v1.Backup) error {
+	if err := backup.ValidateBackupSpec(obj.Spec); err != nil {
+		return err
+	}
+	if err := v.validateStorageLocation(obj.Spec.StorageLocation); err != nil {
+		return err
+	}
+	if err := v.validateSecret(util.GetChartNamespace(), obj.Spec.EncryptionConfigSecretName, "encryptionConfigSecretName"); err != nil {
+		return err
+	}
+	return v.validateSecret(util.GetChartNamespace(), obj.Spec.ArchiveEncryptionSecretName, "archiveEncryptionSecretName")
+}
+
+func (v *Validator) validateRestore(obj *v1.Restore) error {
+	if err := restore.ValidateBackupSelection(obj.Spec); err != nil {
+		return err
+	}
+	// a restore referencing a Backup uses the Backup's storage location when it has none
+	if obj.Spec.BackupRef == nil || obj.Spec.StorageLocation != nil {
+		if err := v.validateStorageLocation(obj.Spec.StorageLocation); err != nil {
+			return err
+		}
+	}
+	if err := v.validateSecret(util.GetChartNamespace(), obj.Spec.EncryptionConfigSecretName, "encryptionConfigSecretName"); err != nil {
+		return err
+	}
+	return v.validateSecret(util.GetChartNamespace(), obj.Spec.ArchiveEncryptionSecretName, "archiveEncryptionSecretName")
+}
+
+func (v *Validator) validateResourceSet(obj *v1.ResourceSet) error {
+	for i, selector := range obj.ResourceSelectors {
+		if err := resourcesets.ValidateResourceSelector(selector); err != nil {
+			return fmt.Errorf("resourceSelectors[%d]: %v", i, err)
+		}
+	}
+	for i, selector := range obj.ExcludeSelectors {
+		if err := resourcesets.ValidateResourceSelector(selector); err != nil {
+			return fmt.Errorf("excludeSelectors[%d]: %v", i, err)
+		}
+	}
+	if obj.Generate != nil {
+		if err := generate.Validate(obj.Generate); err != nil {
+			return fmt.Errorf("generate: %v", err)
+		}
+	}
+	// included ResourceSets which don't exist yet are reported in the status, only includes cycles are rejected
+	_, err := include.Resolve(obj, func(name string) (*v1.ResourceSet, error) {
+		included, err := v.resourceSets.Get(name, k8sv1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return &v1.ResourceSet{}, nil
+		}
+		return included, err
+	})
+	return err
+}
+
+// validateStorageLocation checks that a backup can be stored in or read from the storage location: S3 details are set,
+// or the operator has a default storage location. The S3 credentials secret must exist.
+func (v *Validator) validateStorageLocation(location *v1.StorageLocation) error {
+	if location == nil {
+		if !v.hasDefaultStorageLocation {
+			return fmt.Errorf("storageLocation must be set, no default storage location is configured at the operator level")
+		}
+		return nil
+	}
+	if location.S3 == nil {
+		return fmt.Errorf("storageLocation must set s3 details")
+	}
+	return v.validateSecret(location.S3.CredentialSecretNamespace, location.S3.CredentialSecretName, "storageLocation.s3.credentialSecretName")
+}
+
+// validateSecret checks that the secret referenced by field exists, when it is set
+func (v *Validator) validateSecret(namespace, name, field string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := v.secrets.Get(namespace, name, k8sv1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%v: secret %v not found in namespace %v", field, name, namespace)
+		}
+		return fmt.Errorf("%v: error getting secret %v in namespace %v: %v", field, name, namespace, err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/rancher/backup-restore-operator/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const chartNamespace = "cattle-resources-system"
+
+type fakeSecrets map[string]bool
+
+func (f fakeSecrets) Get(namespace, name string, _ k8sv1.GetOptions) (*corev1.Secret, error) {
+	if !f[namespace+"/"+name] {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+	}
+	return &corev1.Secret{ObjectMeta: k8sv1.ObjectMeta{Namespace: namespace, Name: name}}, nil
+}
+
+type fakeResourceSets map[string]*v1.ResourceSet
+
+func (f fakeResourceSets) Get(name string, _ k8sv1.GetOptions) (*v1.ResourceSet, error) {
+	resourceSet, ok := f[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "resourcesets"}, name)
+	}
+	return resourceSet, nil
+}
+
+func newRequest(t *testing.T, operation admissionv1.Operation, obj, oldObj runtime.Object) *admissionv1.AdmissionRequest {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	request := &admissionv1.AdmissionRequest{Operation: operation, Kind: k8sv1.GroupVersionKind{Group: "resources.cattle.io", Version: "v1", Kind: kind}}
+	raw, err := json.Marshal(obj)
+	require.NoError(t, err)
+	request.Object.Raw = raw
+	if oldObj != nil {
+		raw, err = json.Marshal(oldObj)
+		require.NoError(t, err)
+		request.OldObject.Raw = raw
+	}
+	return request
+}
+
+func newBackup(spec v1.BackupSpec) *v1.Backup {
+	return &v1.Backup{TypeMeta: k8sv1.TypeMeta{Kind: "Backup"}, ObjectMeta: k8sv1.ObjectMeta{Name: "backup"}, Spec: spec}
+}
+
+func newRestore(spec v1.RestoreSpec) *v1.Restore {
+	return &v1.Restore{TypeMeta: k8sv1.TypeMeta{Kind: "Restore"}, ObjectMeta: k8sv1.ObjectMeta{Name: "restore"}, Spec: spec}
+}
+
+func newResourceSet(name string, selectors []v1.ResourceSelector, include ...string) *v1.ResourceSet {
+	return &v1.ResourceSet{TypeMeta: k8sv1.TypeMeta{Kind: "ResourceSet"}, ObjectMeta: k8sv1.ObjectMeta{Name: name}, ResourceSelectors: selectors, Include: include}
+}
+
+func TestValidate(t *testing.T) {
+	util.SetChartNamespace(chartNamespace)
+	s3 := &v1.StorageLocation{S3: &v1.S3ObjectStore{Endpoint: "s3.example.com", BucketName: "backups", CredentialSecretName: "s3-creds", CredentialSecretNamespace: "default"}}
+	validator := &Validator{
+		secrets: fakeSecrets{chartNamespace + "/encryptionconfig": true, "default/s3-creds": true},
+		resourceSets: fakeResourceSets{
+			"rancher": newResourceSet("rancher", nil),
+			"team":    newResourceSet("team", nil, "cycle"),
+		},
+		hasDefaultStorageLocation: true,
+	}
+
+	testCases := []struct {
+		name    string
+		request func(t *testing.T) *admissionv1.AdmissionRequest
+		err     string
+	}{
+		{
+			name: "Valid backup",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				return newRequest(t, admissionv1.Create, newBackup(v1.BackupSpec{ResourceSetName: "rancher", Schedule: "@daily", EncryptionConfigSecretName: "encryptionconfig", StorageLocation: s3}), nil)
+			},
+		},
+		{
+			name: "Backup with an invalid schedule",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				return newRequest(t, admissionv1.Create, newBackup(v1.BackupSpec{ResourceSetName: "rancher", Schedule: "every day"}), nil)
+			},
+			err: "cron",
+		},
+		{
+			name: "Backup with a missing encryption config secret",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				return newRequest(t, admissionv1.Create, newBackup(v1.BackupSpec{ResourceSetName: "rancher", EncryptionConfigSecretName: "missing"}), nil)
+			},
+			err: "encryptionConfigSecretName",
+		},
+		{
+			name: "Backup with a missing S3 credentials secret",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				location := s3.DeepCopy()
+				location.S3.CredentialSecretName = "missing"
+				return newRequest(t, admissionv1.Create, newBackup(v1.BackupSpec{ResourceSetName: "rancher", StorageLocation: location}), nil)
+			},
+			err: "credentialSecretName",
+		},
+		{
+			name: "Backup with an empty storage location",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				return newRequest(t, admissionv1.Create, newBackup(v1.BackupSpec{ResourceSetName: "rancher", StorageLocation: &v1.StorageLocation{}}), nil)
+			},
+			err: "s3",
+		},
+		{
+			name: "Update of a backup that doesn't change its spec",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				backup := newBackup(v1.BackupSpec{ResourceSetName: "rancher", EncryptionConfigSecretName: "deleted"})
+				updated := backup.DeepCopy()
+				updated.Finalizers = []string{"resources.cattle.io/backup-finalizer"}
+				return newRequest(t, admissionv1.Update, updated, backup)
+			},
+		},
+		{
+			name: "Update of a backup spec",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				backup := newBackup(v1.BackupSpec{ResourceSetName: "rancher"})
+				updated := backup.DeepCopy()
+				updated.Spec.Schedule = "every day"
+				return newRequest(t, admissionv1.Update, updated, backup)
+			},
+			err: "cron",
+		},
+		{
+			name: "Valid restore",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				return newRequest(t, admissionv1.Create, newRestore(v1.RestoreSpec{BackupRef: &v1.BackupReference{Name: "backup"}}), nil)
+			},
+		},
+		{
+			name: "Restore selecting a backup twice",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				return newRequest(t, admissionv1.Create, newRestore(v1.RestoreSpec{BackupFilename: "backup.tar.gz", LatestFor: "24e1b8ce"}), nil)
+			},
+			err: "backupFilename",
+		},
+		{
+			name: "Restore with a missing archive encryption secret",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				return newRequest(t, admissionv1.Create, newRestore(v1.RestoreSpec{BackupFilename: "backup.tar.gz.age", ArchiveEncryptionSecretName: "missing"}), nil)
+			},
+			err: "archiveEncryptionSecretName",
+		},
+		{
+			name: "Valid resource set",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				return newRequest(t, admissionv1.Create, newResourceSet("my-set", []v1.ResourceSelector{{APIVersion: "v1", KindsRegexp: "^secrets$"}}, "rancher", "not-yet-created"), nil)
+			},
+		},
+		{
+			name: "Resource set with an invalid regexp",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				return newRequest(t, admissionv1.Create, newResourceSet("my-set", []v1.ResourceSelector{{APIVersion: "v1"}, {APIVersion: "v1", ResourceNameRegexp: "("}}), nil)
+			},
+			err: "resourceSelectors[1]",
+		},
+		{
+			name: "Resource set with an invalid exclude selector",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				resourceSet := newResourceSet("my-set", []v1.ResourceSelector{{APIVersion: "v1"}})
+				resourceSet.ExcludeSelectors = []v1.ResourceSelector{{KindsRegexp: "."}}
+				return newRequest(t, admissionv1.Create, resourceSet, nil)
+			},
+			err: "excludeSelectors[0]",
+		},
+		{
+			name: "Resource set with an invalid generator",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				resourceSet := newResourceSet("my-set", nil)
+				resourceSet.Generate = &v1.ResourceSetGenerator{CRDGroupRegexp: "cattle.io", HelmReleases: []v1.HelmReleaseReference{{Name: "rancher"}}}
+				return newRequest(t, admissionv1.Create, resourceSet, nil)
+			},
+			err: "helmReleases[0]",
+		},
+		{
+			name: "Resource set including itself",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				return newRequest(t, admissionv1.Create, newResourceSet("cycle", nil, "team"), nil)
+			},
+			err: "includes itself",
+		},
+		{
+			name: "Deletion",
+			request: func(t *testing.T) *admissionv1.AdmissionRequest {
+				return newRequest(t, admissionv1.Delete, newBackup(v1.BackupSpec{Schedule: "every day"}), nil)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := validator.Validate(testCase.request(t))
+			if testCase.err == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), testCase.err)
+			}
+		})
+	}
+}
+
+func TestValidateWithoutDefaultStorageLocation(t *testing.T) {
+	util.SetChartNamespace(chartNamespace)
+	validator := &Validator{secrets: fakeSecrets{}, resourceSets: fakeResourceSets{}}
+
+	err := validator.Validate(newRequest(t, admissionv1.Create, newBackup(v1.BackupSpec{ResourceSetName: "rancher"}), nil))
+	assert.ErrorContains(t, err, "storageLocation")
+
+	// a restore of a Backup uses its storage location
+	err = validator.Validate(newRequest(t, admissionv1.Create, newRestore(v1.RestoreSpec{BackupRef: &v1.BackupReference{Name: "backup"}}), nil))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	k8sv1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Path is the path the admission reviews of Backups, Restores and ResourceSets are served on
+const Path = "/validate"
+
+// MutatePath is the path the admission reviews of Backups are mutated on, to record who triggers their snapshots
+const MutatePath = "/mutate"
+
+// ListenAndServe serves the validator and the TriggerRecorder over TLS on port until ctx is done, with the tls.crt and
+// tls.key certificate files of certDir
+func ListenAndServe(ctx context.Context, port int, certDir string, validator *Validator) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, validator)
+	mux.Handle(MutatePath, TriggerRecorder{})
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	logrus.Infof("Serving the admission webhooks on port %d", port)
+	err := server.ListenAndServeTLS(filepath.Join(certDir, "tls.crt"), filepath.Join(certDir, "tls.key"))
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (v *Validator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveReview(w, r, func(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		response := &admissionv1.AdmissionResponse{UID: request.UID, Allowed: true}
+		if err := v.Validate(request); err != nil {
+			logrus.Infof("Rejecting %v of %v %v: %v", request.Operation, request.Kind.Kind, request.Name, err)
+			response.Allowed = false
+			response.Result = &k8sv1.Status{
+				Status:  k8sv1.StatusFailure,
+				Code:    http.StatusUnprocessableEntity,
+				Reason:  k8sv1.StatusReasonInvalid,
+				Message: err.Error(),
+			}
+		}
+		return response
+	})
+}
+
+func (TriggerRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveReview(w, r, func(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		response := &admissionv1.AdmissionResponse{UID: request.UID, Allowed: true}
+		patch, err := triggerUserPatch(request)
+		if err != nil {
+			logrus.Infof("Rejecting %v of %v %v: %v", request.Operation, request.Kind.Kind, request.Name, err)
+			response.Allowed = false
+			response.Result = &k8sv1.Status{
+				Status:  k8sv1.StatusFailure,
+				Code:    http.StatusBadRequest,
+				Reason:  k8sv1.StatusReasonBadRequest,
+				Message: err.Error(),
+			}
+		} else if patch != nil {
+			patchType := admissionv1.PatchTypeJSONPatch
+			response.Patch = patch
+			response.PatchType = &patchType
+		}
+		return response
+	})
+}
+
+// serveReview decodes the admission review of r, and writes it back with the response of respond to its request
+func serveReview(w http.ResponseWriter, r *http.Request, respond func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) {
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+		http.Error(w, "invalid admission review", http.StatusBadRequest)
+		return
+	}
+
+	response := respond(review.Request)
+	review.Request = nil
+	review.Response = response
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		logrus.Errorf("Error writing admission review response: %v", err)
+	}
+}
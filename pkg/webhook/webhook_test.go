@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/rancher/backup-restore-operator/pkg/apis/resources.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func review(t *testing.T, validator *Validator, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	body, err := json.Marshal(admissionv1.AdmissionReview{Request: request})
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	validator.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, Path, bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response admissionv1.AdmissionReview
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.NotNil(t, response.Response)
+	assert.Nil(t, response.Request)
+	assert.Equal(t, request.UID, response.Response.UID)
+	return response.Response
+}
+
+func TestServeHTTP(t *testing.T) {
+	validator := &Validator{resourceSets: fakeResourceSets{}}
+
+	request := newRequest(t, admissionv1.Create, newResourceSet("my-set", []v1.ResourceSelector{{APIVersion: "v1", KindsRegexp: "."}}), nil)
+	request.UID = types.UID("allowed")
+	assert.True(t, review(t, validator, request).Allowed)
+
+	request = newRequest(t, admissionv1.Create, newResourceSet("my-set", []v1.ResourceSelector{{KindsRegexp: "."}}), nil)
+	request.UID = types.UID("denied")
+	response := review(t, validator, request)
+	assert.False(t, response.Allowed)
+	require.NotNil(t, response.Result)
+	assert.Contains(t, response.Result.Message, "apiVersion")
+}
+
+func TestServeHTTPInvalidReview(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	(&Validator{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, Path, bytes.NewReader([]byte("{}"))))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestServeHTTPTriggerRecorder(t *testing.T) {
+	request := newRequest(t, admissionv1.Create, newAnnotatedBackup(map[string]string{v1.TriggerSnapshotAnnotation: "1"}), nil)
+	request.UID = types.UID("triggered")
+	request.UserInfo = authenticationv1.UserInfo{Username: "alice"}
+	body, err := json.Marshal(admissionv1.AdmissionReview{Request: request})
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	TriggerRecorder{}.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, MutatePath, bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response admissionv1.AdmissionReview
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.NotNil(t, response.Response)
+	assert.True(t, response.Response.Allowed)
+	require.NotNil(t, response.Response.PatchType)
+	assert.Equal(t, admissionv1.PatchTypeJSONPatch, *response.Response.PatchType)
+	assert.JSONEq(t, `[{"op":"add","path":"/metadata/annotations/resources.cattle.io~1trigger-snapshot-user","value":"alice"}]`, string(response.Response.Patch))
+}